@@ -15,6 +15,7 @@ func Execute() {
 }
 
 func init() {
+	commands.AddDryRunFlag(rootCmd)
 	rootCmd.AddCommand(commands.NewInitCmd())
 	
 	// Create make command as parent
@@ -32,7 +33,20 @@ func init() {
 	makeCmd.AddCommand(commands.NewMakeMapperCmd())
 	makeCmd.AddCommand(commands.NewMakeValidatorCmd())
 	makeCmd.AddCommand(commands.NewMakeDiCmd())
+	makeCmd.AddCommand(commands.NewMakeMigrationCmd())
 	makeCmd.AddCommand(commands.NewMakeAllCmd())
 	
 	rootCmd.AddCommand(makeCmd)
+	rootCmd.AddCommand(commands.NewGenerateCmd())
+	rootCmd.AddCommand(commands.NewOpenAPICmd())
+
+	// Create import command as parent
+	importCmd := &cobra.Command{
+		Use:   "import",
+		Short: "Generate components from an existing system",
+		Long:  "Generate entities, repositories and mappers from existing databases",
+	}
+	importCmd.AddCommand(commands.NewImportSQLCmd())
+
+	rootCmd.AddCommand(importCmd)
 }
@@ -0,0 +1,67 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// pflagFuncs maps Go types to the pflag.FlagSet method binding a variable
+// of the type to a flag, and the zero value the flag defaults to
+var pflagFuncs = map[string][2]string{
+	"string":            {"StringVar", `""`},
+	"bool":              {"BoolVar", "false"},
+	"int":               {"IntVar", "0"},
+	"int8":              {"Int8Var", "0"},
+	"int16":             {"Int16Var", "0"},
+	"int32":             {"Int32Var", "0"},
+	"int64":             {"Int64Var", "0"},
+	"uint":              {"UintVar", "0"},
+	"uint8":             {"Uint8Var", "0"},
+	"uint16":            {"Uint16Var", "0"},
+	"uint32":            {"Uint32Var", "0"},
+	"uint64":            {"Uint64Var", "0"},
+	"float32":           {"Float32Var", "0"},
+	"float64":           {"Float64Var", "0"},
+	"time.Duration":     {"DurationVar", "0"},
+	"[]string":          {"StringSliceVar", "nil"},
+	"[]bool":            {"BoolSliceVar", "nil"},
+	"[]int":             {"IntSliceVar", "nil"},
+	"[]int32":           {"Int32SliceVar", "nil"},
+	"[]int64":           {"Int64SliceVar", "nil"},
+	"[]uint":            {"UintSliceVar", "nil"},
+	"[]float32":         {"Float32SliceVar", "nil"},
+	"[]float64":         {"Float64SliceVar", "nil"},
+	"[]time.Duration":   {"DurationSliceVar", "nil"},
+	"[]byte":            {"BytesBase64Var", "nil"},
+	"map[string]string": {"StringToStringVar", "nil"},
+	"map[string]int":    {"StringToIntVar", "nil"},
+	"map[string]int64":  {"StringToInt64Var", "nil"},
+}
+
+// FlagName returns the name of the command line flag of the field, in
+// kebab-case (UserID -> user-id)
+func (f Field) FlagName() string {
+	return strings.ToLower(strings.Join(splitWords(f.Name), "-"))
+}
+
+// Flag returns the call of a pflag.FlagSet method binding the field of the
+// struct variable v to its flag, or "" when flags cannot hold its type.
+// time.Time fields take RFC 3339 values through the timeValue type of the
+// generated CLI handlers.
+func (f Field) Flag(v string) string {
+	usage := strings.ToLower(strings.Join(splitWords(f.Name), " "))
+	if f.Type == "time.Time" {
+		return fmt.Sprintf("Var((*timeValue)(&%s.%s), %q, %q)", v, f.Name, f.FlagName(), usage+" (RFC 3339)")
+	}
+	fn, ok := pflagFuncs[f.Type]
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%s(&%s.%s, %q, %s, %q)", fn[0], v, f.Name, f.FlagName(), fn[1], usage)
+}
+
+// CommandName returns the name of the command running useCase, in
+// kebab-case (CreateUser -> create-user)
+func CommandName(useCase string) string {
+	return strings.ToLower(strings.Join(splitWords(useCase), "-"))
+}
@@ -0,0 +1,39 @@
+package internal
+
+import "testing"
+
+func TestField_Flag(t *testing.T) {
+	tests := []struct {
+		field Field
+		flag  string
+	}{
+		{Field{Name: "Name", Type: "string"}, `StringVar(&input.Name, "name", "", "name")`},
+		{Field{Name: "UserID", Type: "int64"}, `Int64Var(&input.UserID, "user-id", 0, "user id")`},
+		{Field{Name: "DryRun", Type: "bool"}, `BoolVar(&input.DryRun, "dry-run", false, "dry run")`},
+		{Field{Name: "Tags", Type: "[]string"}, `StringSliceVar(&input.Tags, "tags", nil, "tags")`},
+		{Field{Name: "Timeout", Type: "time.Duration"}, `DurationVar(&input.Timeout, "timeout", 0, "timeout")`},
+		{Field{Name: "CreatedAt", Type: "time.Time"}, `Var((*timeValue)(&input.CreatedAt), "created-at", "created at (RFC 3339)")`},
+		{Field{Name: "Nickname", Type: "*string"}, ""},
+		{Field{Name: "Price", Type: "decimal.Decimal"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field.Name, func(t *testing.T) {
+			if got := tt.field.Flag("input"); got != tt.flag {
+				t.Errorf("Flag() = %q, expected %q", got, tt.flag)
+			}
+		})
+	}
+}
+
+func TestCommandName(t *testing.T) {
+	for useCase, expected := range map[string]string{
+		"CreateUser":      "create-user",
+		"ImportCSVOrders": "import-csv-orders",
+		"list_users":      "list-users",
+	} {
+		if got := CommandName(useCase); got != expected {
+			t.Errorf("CommandName(%q) = %q, expected %q", useCase, got, expected)
+		}
+	}
+}
@@ -1,10 +1,16 @@
 package commands
 
 import (
+	"errors"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/fsjorgeluis/sazerac/internal"
+	"github.com/fsjorgeluis/sazerac/internal/templates"
 	"github.com/spf13/cobra"
 )
 
@@ -111,6 +117,62 @@ func TestNewMakeUseCaseCmd(t *testing.T) {
 	}
 }
 
+func TestMakeUseCaseOperations(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	os.WriteFile("go.mod", []byte("module test\n\ngo 1.21\n"), 0644)
+
+	tests := []struct {
+		useCase string
+		wants   []string
+	}{
+		{"CreateUser", []string{"Name: input.Name,", "uc.Repo.Save(entity)", "Name string `json:\"name\"`"}},
+		{"GetUser", []string{"uc.Repo.FindByID(input.ID)", "ID string `json:\"id\"`"}},
+		{"UpdateUser", []string{"uc.Repo.FindByID(input.ID)", "uc.Repo.Save(entity)", "Name string `json:\"name\"`"}},
+		{"DeleteUser", []string{"// TODO: the repository has no Delete", `errors.New("DeleteUser is not implemented")`}},
+		{"TransferFunds", []string{"// TODO: add definition", "uc.Repo.Save(entity)"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.useCase, func(t *testing.T) {
+			cmd := NewMakeUseCaseCmd()
+			if err := cmd.RunE(cmd, []string{tt.useCase, "User", "name:string"}); err != nil {
+				t.Fatalf("Command execution failed: %v", err)
+			}
+			content, _ := os.ReadFile(useCasePath(tt.useCase))
+			for _, want := range tt.wants {
+				if !strings.Contains(string(content), want) {
+					t.Errorf("%s use case is missing %q:\n%s", tt.useCase, want, content)
+				}
+			}
+		})
+	}
+
+	// With a CRUD repository the use cases call its methods
+	cmd := NewMakeRepoCmd()
+	cmd.Flags().Set("crud", "true")
+	if err := cmd.RunE(cmd, []string{"Order", "total:int"}); err != nil {
+		t.Fatalf("make repo failed: %v", err)
+	}
+	crud := map[string]string{
+		"CreateOrder": "uc.Repo.Create(entity)",
+		"UpdateOrder": "uc.Repo.Update(entity)",
+		"DeleteOrder": "uc.Repo.Delete(input.ID)",
+	}
+	for useCase, want := range crud {
+		cmd := NewMakeUseCaseCmd()
+		if err := cmd.RunE(cmd, []string{useCase, "Order", "total:int"}); err != nil {
+			t.Fatalf("Command execution failed: %v", err)
+		}
+		if content, _ := os.ReadFile(useCasePath(useCase)); !strings.Contains(string(content), want) {
+			t.Errorf("%s use case is missing %q:\n%s", useCase, want, content)
+		}
+	}
+}
+
 func TestNewMakeHandlerCmd(t *testing.T) {
 	cmd := NewMakeHandlerCmd()
 	if cmd == nil {
@@ -228,6 +290,7 @@ func TestNewInitCmd(t *testing.T) {
 		filepath.Join(projectName, "cmd", projectName, "main.go"),
 		filepath.Join(projectName, "go.mod"),
 		filepath.Join(projectName, "README.md"),
+		filepath.Join(projectName, ".sazerac.yaml"),
 	}
 
 	expectedDirs := []string{
@@ -412,3 +475,1757 @@ func TestCommandArgsValidation(t *testing.T) {
 	}
 }
 
+
+func TestMakeEntityOverwriteProtection(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	os.WriteFile("go.mod", []byte("module test\n\ngo 1.21\n"), 0644)
+
+	path := filepath.Join("internal", "domain", "entities", "user.go")
+	handWritten := []byte("package entities\n\ntype User struct{ Email string }\n")
+	os.MkdirAll(filepath.Dir(path), 0755)
+	os.WriteFile(path, handWritten, 0644)
+
+	// Default refuses to touch the file
+	if err := NewMakeEntityCmd().RunE(NewMakeEntityCmd(), []string{"User"}); !errors.Is(err, internal.ErrFileExists) {
+		t.Fatalf("Expected ErrFileExists, got %v", err)
+	}
+
+	// --skip-existing keeps it without failing
+	skipCmd := NewMakeEntityCmd()
+	skipCmd.Flags().Set("skip-existing", "true")
+	if err := skipCmd.RunE(skipCmd, []string{"User"}); err != nil {
+		t.Fatalf("Command with --skip-existing failed: %v", err)
+	}
+	if content, _ := os.ReadFile(path); string(content) != string(handWritten) {
+		t.Error("--skip-existing modified the existing file")
+	}
+
+	// --interactive asks and honours the answer
+	promptCmd := NewMakeEntityCmd()
+	promptCmd.Flags().Set("interactive", "true")
+	promptCmd.SetIn(strings.NewReader("s\n"))
+	promptCmd.SetOut(io.Discard)
+	if err := promptCmd.RunE(promptCmd, []string{"User"}); err != nil {
+		t.Fatalf("Command with --interactive failed: %v", err)
+	}
+	if content, _ := os.ReadFile(path); string(content) != string(handWritten) {
+		t.Error("--interactive overwrote the file after answering skip")
+	}
+
+	// --force replaces it
+	forceCmd := NewMakeEntityCmd()
+	forceCmd.Flags().Set("force", "true")
+	if err := forceCmd.RunE(forceCmd, []string{"User"}); err != nil {
+		t.Fatalf("Command with --force failed: %v", err)
+	}
+	if content, _ := os.ReadFile(path); string(content) == string(handWritten) {
+		t.Error("--force did not overwrite the existing file")
+	}
+}
+
+func TestMakeAllOverwriteFlagsReachSubcommands(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	os.WriteFile("go.mod", []byte("module github.com/user/test-project\n\ngo 1.21\n"), 0644)
+
+	if err := NewMakeAllCmd().RunE(NewMakeAllCmd(), []string{"User", "CreateUser"}); err != nil {
+		t.Fatalf("First run failed: %v", err)
+	}
+
+	usecasePath := filepath.Join("internal", "usecases", "create_user_usecase.go")
+	custom := []byte("package usecases\n\n// business logic lives here\n")
+	os.WriteFile(usecasePath, custom, 0644)
+
+	if err := NewMakeAllCmd().RunE(NewMakeAllCmd(), []string{"User", "CreateUser"}); !errors.Is(err, internal.ErrFileExists) {
+		t.Fatalf("Expected ErrFileExists on second run, got %v", err)
+	}
+
+	cmd := NewMakeAllCmd()
+	cmd.Flags().Set("skip-existing", "true")
+	if err := cmd.RunE(cmd, []string{"User", "CreateUser"}); err != nil {
+		t.Fatalf("Run with --skip-existing failed: %v", err)
+	}
+	if content, _ := os.ReadFile(usecasePath); string(content) != string(custom) {
+		t.Error("make all --skip-existing overwrote a customized use case")
+	}
+}
+
+func TestMakeAllIsAllOrNothing(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	os.WriteFile("go.mod", []byte("module github.com/user/test-project\n\ngo 1.21\n"), 0644)
+	entityPath := filepath.Join("internal", "domain", "entities", "user.go")
+
+	// A handler written by hand is a conflict, found before anything is
+	// written
+	handlerPath := filepath.Join("internal", "handlers", "create_user_handler.go")
+	os.MkdirAll(filepath.Dir(handlerPath), 0755)
+	os.WriteFile(handlerPath, []byte("package handlers\n"), 0644)
+	if err := NewMakeAllCmd().RunE(NewMakeAllCmd(), []string{"User", "CreateUser"}); !errors.Is(err, internal.ErrFileExists) {
+		t.Fatalf("Expected ErrFileExists, got %v", err)
+	}
+	if _, err := os.Stat(entityPath); err == nil {
+		t.Error("make all wrote the entity before failing on the handler")
+	}
+	os.Remove(handlerPath)
+
+	// So is a DI container that cannot be merged
+	diPath := filepath.Join("cmd", "test-project", "di", "di.go")
+	os.MkdirAll(filepath.Dir(diPath), 0755)
+	os.WriteFile(diPath, []byte("package di\n\nfunc broken( {\n"), 0644)
+	if err := NewMakeAllCmd().RunE(NewMakeAllCmd(), []string{"User", "CreateUser"}); err == nil || !strings.Contains(err.Error(), "failed to generate DI") {
+		t.Fatalf("Expected a DI error, got %v", err)
+	}
+	if _, err := os.Stat(entityPath); err == nil {
+		t.Error("make all wrote the entity before failing on the DI container")
+	}
+}
+
+func TestDryRunWritesNothing(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	os.WriteFile("go.mod", []byte("module github.com/user/test-project\n\ngo 1.21\n"), 0644)
+
+	tests := []struct {
+		name string
+		cmd  *cobra.Command
+		args []string
+	}{
+		{"init", NewInitCmd(), []string{"dry-project"}},
+		{"make entity", NewMakeEntityCmd(), []string{"User"}},
+		{"make repo", NewMakeRepoCmd(), []string{"User"}},
+		{"make all", NewMakeAllCmd(), []string{"User", "CreateUser"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			AddDryRunFlag(tt.cmd)
+			tt.cmd.SetOut(&out)
+			if err := tt.cmd.ParseFlags([]string{"--dry-run"}); err != nil {
+				t.Fatalf("Failed to parse flags: %v", err)
+			}
+
+			if err := tt.cmd.RunE(tt.cmd, tt.args); err != nil {
+				t.Fatalf("Command execution failed: %v", err)
+			}
+
+			if !strings.Contains(out.String(), "+ create") {
+				t.Errorf("Expected the plan to list created files, got:\n%s", out.String())
+			}
+
+			entries, _ := os.ReadDir(".")
+			if len(entries) != 1 {
+				t.Errorf("Dry run touched the filesystem: %d entries in project root", len(entries))
+			}
+		})
+	}
+}
+
+func TestMakeEntityWithFields(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	os.WriteFile("go.mod", []byte("module test\n\ngo 1.21\n"), 0644)
+
+	cmd := NewMakeEntityCmd()
+	err := cmd.RunE(cmd, []string{"Product", "name:string", "price:float64", "tags:[]string", "created_at:time.Time"})
+	if err != nil {
+		t.Fatalf("Command execution failed: %v", err)
+	}
+
+	content, _ := os.ReadFile(filepath.Join("internal", "domain", "entities", "product.go"))
+	for _, want := range []string{`"time"`, "ID        string", "Price     float64", "Tags      []string", "CreatedAt time.Time"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("Entity is missing %q:\n%s", want, content)
+		}
+	}
+
+	// Other generators read the schema back from the entity struct
+	mapperCmd := NewMakeMapperCmd()
+	if err := mapperCmd.RunE(mapperCmd, []string{"Product"}); err != nil {
+		t.Fatalf("Mapper generation failed: %v", err)
+	}
+
+	mapper, _ := os.ReadFile(filepath.Join("internal", "domain", "mappers", "product_mapper.go"))
+	for _, want := range []string{"type ProductDTO struct", "CreatedAt: dto.CreatedAt", `json:"created_at"`} {
+		if !strings.Contains(string(mapper), want) {
+			t.Errorf("Mapper is missing %q:\n%s", want, mapper)
+		}
+	}
+
+	invalid := NewMakeEntityCmd()
+	if err := invalid.RunE(invalid, []string{"Broken", "price"}); err == nil {
+		t.Error("Expected an error for an invalid field definition")
+	}
+}
+
+func TestMakeEntityStructTags(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	os.WriteFile("go.mod", []byte("module test\n\ngo 1.21\n"), 0644)
+	os.WriteFile(".sazerac.yaml", []byte("tags:\n  keys: [json, yaml]\n  naming: camelCase\n"), 0644)
+
+	cmd := NewMakeEntityCmd()
+	err := cmd.RunE(cmd, []string{"Customer", "created_at:time.Time", "email:string:json=email_address,omitempty"})
+	if err != nil {
+		t.Fatalf("Command execution failed: %v", err)
+	}
+
+	content, _ := os.ReadFile(filepath.Join("internal", "domain", "entities", "customer.go"))
+	for _, want := range []string{
+		"`json:\"createdAt\" yaml:\"createdAt\"`",
+		"`json:\"email_address,omitempty\" yaml:\"email\"`",
+	} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("Entity is missing tag %s:\n%s", want, content)
+		}
+	}
+
+	os.WriteFile(".sazerac.yaml", []byte("tags:\n  naming: PascalCase\n"), 0644)
+	if err := NewMakeEntityCmd().RunE(NewMakeEntityCmd(), []string{"Other"}); err == nil {
+		t.Error("Expected an error for an invalid configuration")
+	}
+}
+
+func TestGenerateFromSchema(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	os.WriteFile("go.mod", []byte("module test\n\ngo 1.21\n"), 0644)
+	os.MkdirAll(filepath.Join("cmd", "test"), 0755)
+	os.WriteFile("schema.yaml", []byte(`entities:
+  - name: User
+    fields: [name:string]
+    usecases: [CreateUser]
+  - name: Order
+    fields: [total:float64]
+    relations:
+      - belongs_to: User
+    usecases: [CreateOrder, CancelOrder]
+    crud: true
+`), 0644)
+
+	// The domain errors used by repositories and handlers are planned once
+	var out strings.Builder
+	cmd := NewGenerateCmd()
+	AddDryRunFlag(cmd)
+	cmd.SetOut(&out)
+	if err := cmd.ParseFlags([]string{"--dry-run", "--kind", "http"}); err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	if err := cmd.RunE(cmd, nil); err != nil {
+		t.Fatalf("Dry run failed: %v", err)
+	}
+	if n := strings.Count(out.String(), "+ create internal/domain/errors.go\n"); n != 1 {
+		t.Errorf("internal/domain/errors.go is planned %d times:\n%s", n, out.String())
+	}
+
+	cmd = NewGenerateCmd()
+	if err := cmd.RunE(cmd, nil); err != nil {
+		t.Fatalf("Command execution failed: %v", err)
+	}
+
+	for _, path := range []string{
+		"internal/domain/entities/user.go",
+		"internal/domain/entities/order.go",
+		"internal/repository/order_repository.go",
+		"infrastructure/database/mysql/order_mysql.go",
+		"internal/domain/mappers/order_mapper.go",
+		"internal/domain/validators/order_validator.go",
+		"internal/usecases/cancel_order_usecase.go",
+		"internal/handlers/create_user_handler.go",
+		"cmd/test/di/di.go",
+	} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected %s to be generated: %v", path, err)
+		}
+	}
+
+	if repo, _ := os.ReadFile(filepath.Join("internal", "repository", "order_repository.go")); !strings.Contains(string(repo), "List(filter OrderFilter) ([]*entities.Order, error)") {
+		t.Errorf("crud: true did not generate the CRUD repository:\n%s", repo)
+	}
+	if validator, _ := os.ReadFile(filepath.Join("internal", "domain", "validators", "order_validator.go")); !strings.Contains(string(validator), "every required field of the Order is set") {
+		t.Errorf("Validator doc comment does not name the entity:\n%s", validator)
+	}
+
+	di, _ := os.ReadFile(filepath.Join("cmd", "test", "di", "di.go"))
+	for _, want := range []string{"CreateUserHandler", "CreateOrderHandler", "CancelOrderHandler"} {
+		if !strings.Contains(string(di), want) {
+			t.Errorf("DI container is missing %s:\n%s", want, di)
+		}
+	}
+
+	// New use cases are added on every run, while the ones written by hand
+	// are kept
+	usecasePath := filepath.Join("internal", "usecases", "create_user_usecase.go")
+	os.WriteFile(usecasePath, []byte("package usecases\n// custom\n"), 0644)
+	os.WriteFile("schema.yaml", []byte(`entities:
+  - name: User
+    fields: [name:string]
+    usecases: [CreateUser, GetUser]
+`), 0644)
+
+	cmd = NewGenerateCmd()
+	if err := cmd.RunE(cmd, nil); err != nil {
+		t.Fatalf("Second run failed: %v", err)
+	}
+	if usecase, _ := os.ReadFile(usecasePath); !strings.Contains(string(usecase), "// custom") {
+		t.Error("Existing use case was overwritten")
+	}
+	if _, err := os.Stat(filepath.Join("internal", "usecases", "get_user_usecase.go")); err != nil {
+		t.Errorf("New use case was not generated: %v", err)
+	}
+
+	// Files the edited schema changes are refused without --force
+	entityPath := filepath.Join("internal", "domain", "entities", "user.go")
+	os.WriteFile("schema.yaml", []byte(`entities:
+  - name: User
+    fields: [name:string, email:string]
+    usecases: [CreateUser, GetUser]
+`), 0644)
+
+	cmd = NewGenerateCmd()
+	if err := cmd.RunE(cmd, nil); !errors.Is(err, internal.ErrFileExists) || !strings.Contains(err.Error(), entityPath) {
+		t.Fatalf("Expected a conflict on %s, got %v", entityPath, err)
+	}
+	if entity, _ := os.ReadFile(entityPath); strings.Contains(string(entity), "Email") {
+		t.Errorf("Entity was overwritten without --force:\n%s", entity)
+	}
+
+	cmd = NewGenerateCmd()
+	cmd.Flags().Set("force", "true")
+	if err := cmd.RunE(cmd, nil); err != nil {
+		t.Fatalf("Forced run failed: %v", err)
+	}
+	if entity, _ := os.ReadFile(entityPath); !strings.Contains(string(entity), "Email") {
+		t.Errorf("Entity was not updated from the schema:\n%s", entity)
+	}
+
+	missing := NewGenerateCmd()
+	missing.Flags().Set("file", "missing.yaml")
+	if err := missing.RunE(missing, nil); err == nil {
+		t.Error("Expected an error for a missing schema file")
+	}
+}
+
+func TestGenerateKeepsHandlerKind(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	os.WriteFile("go.mod", []byte("module test\n\ngo 1.21\n"), 0644)
+	os.MkdirAll(filepath.Join("cmd", "test"), 0755)
+	os.WriteFile("schema.yaml", []byte("entities:\n  - name: User\n    fields: [name:string]\n    usecases: [CreateUser]\n"), 0644)
+
+	cmd := NewGenerateCmd()
+	if err := cmd.RunE(cmd, nil); err != nil {
+		t.Fatalf("Command execution failed: %v", err)
+	}
+
+	// The console handler on disk stays a console handler, the new use case
+	// gets an HTTP one
+	os.WriteFile("schema.yaml", []byte("entities:\n  - name: User\n    fields: [name:string]\n    usecases: [CreateUser, GetUser]\n"), 0644)
+	cmd = NewGenerateCmd()
+	cmd.Flags().Set("kind", "http")
+	if err := cmd.RunE(cmd, nil); err != nil {
+		t.Fatalf("Second run failed: %v", err)
+	}
+
+	di, _ := os.ReadFile(filepath.Join("cmd", "test", "di", "di.go"))
+	if !strings.Contains(string(di), "GetUserHandler.Register(") {
+		t.Errorf("HTTP handler is not registered:\n%s", di)
+	}
+	if strings.Contains(string(di), "CreateUserHandler.Register(") {
+		t.Errorf("Console handler is registered as an HTTP one:\n%s", di)
+	}
+
+	cmd = NewGenerateCmd()
+	cmd.Flags().Set("kind", "http")
+	cmd.Flags().Set("interactive", "true")
+	if err := cmd.RunE(cmd, nil); err == nil || !strings.Contains(err.Error(), "CreateUser already has a console handler") {
+		t.Errorf("Expected a kind conflict error, got %v", err)
+	}
+}
+
+func TestGenerateListFilter(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	os.WriteFile("go.mod", []byte("module test\n\ngo 1.21\n"), 0644)
+	os.MkdirAll(filepath.Join("cmd", "test"), 0755)
+	os.WriteFile("schema.yaml", []byte(`entities:
+  - name: User
+    fields: [name:string]
+    usecases: [ListUsers, GetUser]
+    crud: true
+  - name: Order
+    fields: [total:float64]
+    usecases: [ListOrders]
+`), 0644)
+
+	cmd := NewGenerateCmd()
+	cmd.Flags().Set("kind", "http")
+	if err := cmd.RunE(cmd, nil); err != nil {
+		t.Fatalf("Command execution failed: %v", err)
+	}
+
+	handler, _ := os.ReadFile(filepath.Join("internal", "handlers", "list_users_handler.go"))
+	if !strings.Contains(string(handler), "filter, err := ParseUserFilter(r.URL.Query())") || !strings.Contains(string(handler), "input.Filter = filter") {
+		t.Errorf("List handler does not read the filter:\n%s", handler)
+	}
+	usecase, _ := os.ReadFile(filepath.Join("internal", "usecases", "list_users_usecase.go"))
+	for _, want := range []string{"Filter repository.UserFilter", "([]*entities.User, error)", "uc.Repo.List(input.Filter)"} {
+		if !strings.Contains(string(usecase), want) {
+			t.Errorf("List use case is missing %q:\n%s", want, usecase)
+		}
+	}
+	if handler, _ := os.ReadFile(filepath.Join("internal", "handlers", "list_users_handler.go")); !strings.Contains(string(handler), "writeJSON(w, http.StatusOK, list)") {
+		t.Errorf("List handler does not write the list:\n%s", handler)
+	}
+	// Without CRUD methods the repository cannot list
+	if usecase, _ := os.ReadFile(filepath.Join("internal", "usecases", "list_orders_usecase.go")); !strings.Contains(string(usecase), "// TODO: the repository has no List") {
+		t.Errorf("List use case of a repository without List:\n%s", usecase)
+	}
+	for _, path := range []string{"get_user_handler.go", "list_orders_handler.go"} {
+		if handler, _ := os.ReadFile(filepath.Join("internal", "handlers", path)); strings.Contains(string(handler), "Filter") {
+			t.Errorf("%s reads a filter:\n%s", path, handler)
+		}
+	}
+}
+
+func TestMakeAllAccumulatesDI(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	os.WriteFile("go.mod", []byte("module github.com/user/test-project\n\ngo 1.21\n"), 0644)
+
+	for _, args := range [][]string{{"User", "CreateUser"}, {"Order", "CreateOrder"}, {"User", "DeleteUser"}} {
+		if err := NewMakeAllCmd().RunE(NewMakeAllCmd(), args); err != nil {
+			t.Fatalf("make all %v failed: %v", args, err)
+		}
+	}
+
+	diPath := filepath.Join("cmd", "test-project", "di", "di.go")
+	di, _ := os.ReadFile(diPath)
+	for _, want := range []string{
+		"CreateUserHandler:  CreateUserHandler",
+		"CreateOrderHandler: CreateOrderHandler",
+		"DeleteUserHandler:  DeleteUserHandler",
+		"OrderRepo := mysql.NewOrderMySQLRepo(db)",
+	} {
+		if !strings.Contains(string(di), want) {
+			t.Errorf("DI container is missing %q:\n%s", want, di)
+		}
+	}
+	if n := strings.Count(string(di), "UserRepo :="); n != 1 {
+		t.Errorf("UserRepo is declared %d times, want 1", n)
+	}
+
+	// Wiring the same use case again leaves the container alone
+	cmd := NewMakeDiCmd()
+	if err := cmd.RunE(cmd, []string{"CreateOrder", "Order"}); err != nil {
+		t.Fatalf("make di failed: %v", err)
+	}
+	if again, _ := os.ReadFile(diPath); string(again) != string(di) {
+		t.Errorf("make di changed an up to date container:\n%s", again)
+	}
+}
+
+func TestMakeAllPatchesMain(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	os.WriteFile("go.mod", []byte("module github.com/user/test-project\n\ngo 1.21\n"), 0644)
+
+	// main.go as generated by init is replaced without --force
+	mainPath := filepath.Join("cmd", "test-project", "main.go")
+	scaffold, _ := internal.RenderTemplate(templates.FS, "project/main.go.tpl", map[string]any{
+		"Module":      "github.com/user/test-project",
+		"ProjectName": "test-project",
+	})
+	os.MkdirAll(filepath.Dir(mainPath), 0755)
+	os.WriteFile(mainPath, scaffold, 0644)
+
+	if err := NewMakeAllCmd().RunE(NewMakeAllCmd(), []string{"User", "CreateUser"}); err != nil {
+		t.Fatalf("make all failed: %v", err)
+	}
+
+	content, _ := os.ReadFile(mainPath)
+	if strings.Contains(string(content), "is ready") || !strings.Contains(string(content), "container.CreateUserHandler.Run()") {
+		t.Fatalf("main.go was not wired:\n%s", content)
+	}
+
+	// Customized main.go keeps its code and runs every handler
+	custom := strings.Replace(string(content), "defer container.Close()", "defer container.Close()\n\tlog.Println(\"custom\")", 1)
+	os.WriteFile(mainPath, []byte(custom), 0644)
+
+	if err := NewMakeAllCmd().RunE(NewMakeAllCmd(), []string{"Order", "CreateOrder"}); err != nil {
+		t.Fatalf("make all failed: %v", err)
+	}
+
+	content, _ = os.ReadFile(mainPath)
+	for _, want := range []string{`log.Println("custom")`, "container.CreateUserHandler.Run()", "container.CreateOrderHandler.Run()"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("main.go is missing %q:\n%s", want, content)
+		}
+	}
+}
+
+func TestMakeRepoPostgres(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	os.WriteFile("go.mod", []byte("module github.com/user/test-project\n\ngo 1.21\n"), 0644)
+
+	cmd := NewMakeAllCmd()
+	cmd.Flags().Set("driver", "postgres")
+	if err := cmd.RunE(cmd, []string{"User", "CreateUser", "id:int64", "email:string"}); err != nil {
+		t.Fatalf("Command execution failed: %v", err)
+	}
+
+	repo, err := os.ReadFile(filepath.Join("infrastructure", "database", "postgres", "user_postgres.go"))
+	if err != nil {
+		t.Fatalf("Postgres repository was not generated: %v", err)
+	}
+	for _, want := range []string{
+		"type UserPostgresRepo struct",
+		`INSERT INTO "users" ("id", "email") VALUES ($1, $2) ON CONFLICT ("id") DO UPDATE SET "email" = EXCLUDED."email" RETURNING "id"`,
+		"Scan(&e.ID)",
+		"FindByID(id int64)",
+		"errors.Is(err, sql.ErrNoRows)",
+		"domain.ErrNotFound",
+	} {
+		if !strings.Contains(string(repo), want) {
+			t.Errorf("Repository is missing %q:\n%s", want, repo)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join("internal", "domain", "errors.go")); err != nil {
+		t.Errorf("Domain errors were not generated: %v", err)
+	}
+
+	di, _ := os.ReadFile(filepath.Join("cmd", "test-project", "di", "di.go"))
+	if !strings.Contains(string(di), "postgres.NewUserPostgresRepo(postgresDB)") {
+		t.Errorf("DI container does not wire the postgres repository:\n%s", di)
+	}
+
+	// The driver also comes from the project configuration
+	os.WriteFile(".sazerac.yaml", []byte("database:\n  driver: postgres\n"), 0644)
+	repoCmd := NewMakeRepoCmd()
+	if err := repoCmd.RunE(repoCmd, []string{"Order", "total:float64"}); err != nil {
+		t.Fatalf("make repo failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join("infrastructure", "database", "postgres", "order_postgres.go")); err != nil {
+		t.Errorf("Configured driver was not used: %v", err)
+	}
+
+	invalid := NewMakeRepoCmd()
+	invalid.Flags().Set("driver", "oracle")
+	if err := invalid.RunE(invalid, []string{"Order"}); err == nil {
+		t.Error("Expected an error for an unknown driver")
+	}
+}
+
+func TestMakeRepoSQLite(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	os.WriteFile("go.mod", []byte("module github.com/user/test-project\n\ngo 1.21\n"), 0644)
+
+	cmd := NewMakeAllCmd()
+	cmd.Flags().Set("driver", "sqlite")
+	if err := cmd.RunE(cmd, []string{"Note", "CreateNote", "title:string"}); err != nil {
+		t.Fatalf("Command execution failed: %v", err)
+	}
+
+	repo, err := os.ReadFile(filepath.Join("infrastructure", "database", "sqlite", "note_sqlite.go"))
+	if err != nil {
+		t.Fatalf("SQLite repository was not generated: %v", err)
+	}
+	for _, want := range []string{
+		"type NoteSQLiteRepo struct",
+		`INSERT INTO "notes" ("id", "title") VALUES (?, ?) ON CONFLICT ("id") DO UPDATE SET "title" = EXCLUDED."title" RETURNING "id"`,
+		"domain.ErrNotFound",
+	} {
+		if !strings.Contains(string(repo), want) {
+			t.Errorf("Repository is missing %q:\n%s", want, repo)
+		}
+	}
+
+	di, _ := os.ReadFile(filepath.Join("cmd", "test-project", "di", "di.go"))
+	for _, want := range []string{
+		`_ "modernc.org/sqlite"`,
+		`sql.Open("sqlite", sqlitePath)`,
+		"sqlite.NewNoteSQLiteRepo(sqliteDB)",
+		"sqliteDB.SetMaxOpenConns(1)",
+	} {
+		if !strings.Contains(string(di), want) {
+			t.Errorf("DI container is missing %q:\n%s", want, di)
+		}
+	}
+}
+
+func TestMakeRepoMemory(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	os.WriteFile("go.mod", []byte("module github.com/user/test-project\n\ngo 1.21\n"), 0644)
+	os.WriteFile(".sazerac.yaml", []byte("database:\n  driver: memory\n"), 0644)
+
+	cmd := NewMakeRepoCmd()
+	if err := cmd.RunE(cmd, []string{"Product", "id:int", "name:string"}); err != nil {
+		t.Fatalf("Command execution failed: %v", err)
+	}
+
+	repo, err := os.ReadFile(filepath.Join("infrastructure", "database", "memory", "product_memory.go"))
+	if err != nil {
+		t.Fatalf("Memory repository was not generated: %v", err)
+	}
+	for _, want := range []string{
+		"type ProductMemoryRepo struct",
+		"mu    sync.RWMutex",
+		"items map[int]entities.Product",
+		"func NewProductMemoryRepo() repository.ProductRepository",
+		"r.items[e.ID] = *e",
+		"return nil, domain.ErrNotFound",
+		"return &e, nil",
+	} {
+		if !strings.Contains(string(repo), want) {
+			t.Errorf("Repository is missing %q:\n%s", want, repo)
+		}
+	}
+}
+
+func TestMakeRepoMySQL(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	os.WriteFile("go.mod", []byte("module github.com/user/test-project\n\ngo 1.21\n"), 0644)
+
+	tests := []struct {
+		name   string
+		args   []string
+		want   []string
+		reject []string
+	}{
+		{
+			name: "Known fields",
+			args: []string{"Order", "id:int", "total:float64"},
+			want: []string{
+				"INSERT INTO `orders` (`id`, `total`) VALUES (?, ?) ON DUPLICATE KEY UPDATE `total` = VALUES(`total`)",
+				"res, err := r.DB.Exec(query, e.ID, e.Total)",
+				"e.ID = int(id)",
+				"Scan(&e.ID, &e.Total)",
+				"domain.ErrNotFound",
+				`fmt.Errorf("find Order %v: %w", id, err)`,
+			},
+			reject: []string{"TODO"},
+		},
+		{
+			name:   "Unknown fields",
+			args:   []string{"Invoice"},
+			want:   []string{"// TODO: implement"},
+			reject: []string{"INSERT INTO"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := NewMakeRepoCmd()
+			cmd.Flags().Set("driver", "mysql")
+			if err := cmd.RunE(cmd, tt.args); err != nil {
+				t.Fatalf("Command execution failed: %v", err)
+			}
+
+			repo, _ := os.ReadFile(filepath.Join("infrastructure", "database", "mysql", internal.ToSnake(tt.args[0])+"_mysql.go"))
+			for _, want := range tt.want {
+				if !strings.Contains(string(repo), want) {
+					t.Errorf("Repository is missing %q:\n%s", want, repo)
+				}
+			}
+			for _, reject := range tt.reject {
+				if strings.Contains(string(repo), reject) {
+					t.Errorf("Repository should not contain %q:\n%s", reject, repo)
+				}
+			}
+		})
+	}
+}
+
+func TestMakeRepoCRUD(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	os.WriteFile("go.mod", []byte("module github.com/user/test-project\n\ngo 1.21\n"), 0644)
+
+	for _, driver := range []string{"memory", "mysql", "postgres", "sqlite"} {
+		t.Run(driver, func(t *testing.T) {
+			cmd := NewMakeRepoCmd()
+			cmd.Flags().Set("driver", driver)
+			cmd.Flags().Set("crud", "true")
+			if err := cmd.RunE(cmd, []string{"User", "name:string"}); err != nil {
+				t.Fatalf("Command execution failed: %v", err)
+			}
+
+			repo, _ := os.ReadFile(filepath.Join("infrastructure", "database", driver, "user_"+driver+".go"))
+			for _, want := range []string{
+				"Create(e *entities.User) error",
+				"Update(e *entities.User) error",
+				"Delete(id string) error",
+				"FindByID(id string) (*entities.User, error)",
+				"List(filter repository.UserFilter) ([]*entities.User, error)",
+				"Count(filter repository.UserFilter) (int, error)",
+				"domain.ErrAlreadyExists",
+				"domain.ErrNotFound",
+			} {
+				if !strings.Contains(string(repo), want) {
+					t.Errorf("Repository is missing %q:\n%s", want, repo)
+				}
+			}
+		})
+	}
+
+	iface, _ := os.ReadFile(filepath.Join("internal", "repository", "user_repository.go"))
+	if !strings.Contains(string(iface), "Count(filter UserFilter) (int, error)") {
+		t.Errorf("Repository interface is missing the CRUD methods:\n%s", iface)
+	}
+	if !strings.Contains(string(iface), "conditions of filter.\ntype UserRepository interface {") {
+		t.Errorf("Repository interface is detached from its doc comment:\n%s", iface)
+	}
+	errs, _ := os.ReadFile(filepath.Join("internal", "domain", "errors.go"))
+	if !strings.Contains(string(errs), "ErrAlreadyExists") {
+		t.Errorf("Domain errors are missing ErrAlreadyExists:\n%s", errs)
+	}
+
+	// Regenerating without --crud keeps the methods the repository has
+	cmd := NewMakeRepoCmd()
+	cmd.Flags().Set("driver", "memory")
+	if err := cmd.RunE(cmd, []string{"User", "name:string"}); err != nil {
+		t.Fatalf("Regenerating the repository failed: %v", err)
+	}
+	if again, _ := os.ReadFile(filepath.Join("internal", "repository", "user_repository.go")); string(again) != string(iface) {
+		t.Errorf("Repository interface lost the CRUD methods:\n%s", again)
+	}
+}
+
+func TestMakeRepoFilter(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	os.WriteFile("go.mod", []byte("module github.com/user/test-project\n\ngo 1.21\n"), 0644)
+
+	cmd := NewMakeRepoCmd()
+	cmd.Flags().Set("driver", "postgres")
+	cmd.Flags().Set("crud", "true")
+	if err := cmd.RunE(cmd, []string{"User", "id:int64", "name:string", "age:int", "tags:[]string"}); err != nil {
+		t.Fatalf("Command execution failed: %v", err)
+	}
+
+	files := map[string][]string{
+		"internal/repository/user_filter.go": {
+			"Name *string",
+			"Age  *int",
+			"After *int64",
+			`var UserSortFields = []string{"id", "name", "age"}`,
+			"domain.ErrInvalidFilter",
+		},
+		"internal/handlers/user_filter.go": {
+			"func ParseUserFilter(query url.Values) (repository.UserFilter, error)",
+			`query.Get("age")`,
+			"strconv.Atoi(v)",
+			`query.Get("after")`,
+			"strconv.ParseInt(v, 10, 64)",
+		},
+		"infrastructure/database/postgres/user_postgres.go": {
+			"fmt.Sprintf(`\"name\" = $%d`, len(args))",
+			"fmt.Sprintf(`\"id\" > $%d`, len(args))",
+			"fmt.Sprintf(` LIMIT $%d`, len(args))",
+			`case "age":`,
+			`query += " ORDER BY " + column + direction`,
+		},
+	}
+	for path, wants := range files {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Errorf("%s was not generated: %v", path, err)
+			continue
+		}
+		for _, want := range wants {
+			if !strings.Contains(string(content), want) {
+				t.Errorf("%s is missing %q:\n%s", path, want, content)
+			}
+		}
+		if strings.HasSuffix(path, "_filter.go") && strings.Contains(string(content), "Tags") {
+			t.Errorf("%s should not filter by slices:\n%s", path, content)
+		}
+	}
+}
+
+func TestMakeAllContext(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	os.WriteFile("go.mod", []byte("module github.com/user/test-project\n\ngo 1.21\n"), 0644)
+	os.WriteFile(".sazerac.yaml", []byte("database:\n  driver: postgres\ncontext: true\n"), 0644)
+
+	cmd := NewMakeAllCmd()
+	cmd.Flags().Set("crud", "true")
+	if err := cmd.RunE(cmd, []string{"User", "CreateUser", "name:string"}); err != nil {
+		t.Fatalf("Command execution failed: %v", err)
+	}
+
+	files := map[string][]string{
+		"internal/repository/user_repository.go": {
+			"Save(ctx context.Context, e *entities.User) error",
+			"List(ctx context.Context, filter UserFilter) ([]*entities.User, error)",
+		},
+		"infrastructure/database/postgres/user_postgres.go": {
+			"FindByID(ctx context.Context, id string)",
+			"conn(ctx, r.DB).QueryRowContext(ctx, query, id)",
+			"conn(ctx, r.DB).ExecContext(ctx, query, id)",
+			"conn(ctx, r.DB).QueryContext(ctx, query, args...)",
+		},
+		"internal/usecases/create_user_usecase.go": {
+			"Execute(ctx context.Context, input CreateUserInput)",
+			"uc.Repo.Create(ctx, entity)",
+		},
+		"internal/handlers/create_user_handler.go": {
+			"Run(ctx context.Context) error",
+			"h.UC.Execute(ctx, input)",
+		},
+		"cmd/test-project/main.go": {
+			"ctx := context.Background()",
+			"container.CreateUserHandler.Run(ctx)",
+		},
+	}
+	for path, wants := range files {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Errorf("%s was not generated: %v", path, err)
+			continue
+		}
+		for _, want := range wants {
+			if !strings.Contains(string(content), want) {
+				t.Errorf("%s is missing %q:\n%s", path, want, content)
+			}
+		}
+	}
+}
+
+func TestMakeAllTx(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	os.WriteFile("go.mod", []byte("module github.com/user/test-project\n\ngo 1.21\n"), 0644)
+
+	// Transactions travel in the context
+	cmd := NewMakeUseCaseCmd()
+	cmd.Flags().Set("tx", "true")
+	if err := cmd.RunE(cmd, []string{"Transfer", "Account", "balance:int"}); err == nil {
+		t.Error("--tx without context should fail")
+	}
+
+	os.WriteFile(".sazerac.yaml", []byte("database:\n  driver: sqlite\ncontext: true\n"), 0644)
+
+	cmd = NewMakeAllCmd()
+	cmd.Flags().Set("tx", "true")
+	if err := cmd.RunE(cmd, []string{"Account", "Transfer", "balance:int"}); err != nil {
+		t.Fatalf("Command execution failed: %v", err)
+	}
+
+	files := map[string][]string{
+		"internal/repository/unit_of_work.go": {
+			"Do(ctx context.Context, fn func(ctx context.Context) error) error",
+		},
+		"infrastructure/database/sqlite/unit_of_work.go": {
+			"func NewUnitOfWork(db *sql.DB) repository.UnitOfWork",
+			"tx.Rollback()",
+			"func conn(ctx context.Context, db *sql.DB) querier",
+		},
+		"infrastructure/database/sqlite/account_sqlite.go": {
+			"conn(ctx, r.DB).QueryRowContext(ctx, query, id)",
+		},
+		"internal/usecases/transfer_usecase.go": {
+			"func NewTransferUseCase(repo repository.AccountRepository, uow repository.UnitOfWork) *TransferUseCase",
+			"uc.UoW.Do(ctx, func(ctx context.Context) error {",
+		},
+		"cmd/test-project/di/di.go": {
+			"sqliteUoW := sqlite.NewUnitOfWork(sqliteDB)",
+			"TransferUC := usecases.NewTransferUseCase(AccountRepo, sqliteUoW)",
+		},
+	}
+	for path, wants := range files {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Errorf("%s was not generated: %v", path, err)
+			continue
+		}
+		for _, want := range wants {
+			if !strings.Contains(string(content), want) {
+				t.Errorf("%s is missing %q:\n%s", path, want, content)
+			}
+		}
+	}
+}
+
+func TestMakeMigration(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	now = func() time.Time { return time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	os.WriteFile("go.mod", []byte("module github.com/user/test-project\n\ngo 1.21\n"), 0644)
+	os.WriteFile(".sazerac.yaml", []byte("database:\n  driver: postgres\n"), 0644)
+
+	all := NewMakeAllCmd()
+	if err := all.RunE(all, []string{"User", "CreateUser", "name:string", "age:int"}); err != nil {
+		t.Fatalf("make all failed: %v", err)
+	}
+
+	mainPath := filepath.Join("cmd", "test-project", "main.go")
+	unpatched, _ := os.ReadFile(mainPath)
+
+	cmd := NewMakeMigrationCmd()
+	if cmd.Use != "migration <Entity>" {
+		t.Errorf("Expected Use to be 'migration <Entity>', got %q", cmd.Use)
+	}
+	if err := cmd.RunE(cmd, []string{"User"}); err != nil {
+		t.Fatalf("Command execution failed: %v", err)
+	}
+
+	files := map[string][]string{
+		"migrations/20261018120000_create_users.up.sql": {
+			`CREATE TABLE "users" (`,
+			`"id" TEXT NOT NULL PRIMARY KEY,`,
+			`"age" BIGINT NOT NULL`,
+		},
+		"migrations/20261018120000_create_users.down.sql": {
+			`DROP TABLE "users";`,
+		},
+		"migrations/migrations.go": {
+			"//go:embed *.sql",
+		},
+		"infrastructure/database/migrate/migrate.go": {
+			"func Up(ctx context.Context, db *sql.DB, fsys fs.FS) error",
+			"VALUES ($1)",
+		},
+		"cmd/test-project/main.go": {
+			"migrate.Up(context.Background(), container.DB, migrations.FS)",
+		},
+	}
+	for path, wants := range files {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Errorf("%s was not generated: %v", path, err)
+			continue
+		}
+		for _, want := range wants {
+			if !strings.Contains(string(content), want) {
+				t.Errorf("%s is missing %q:\n%s", path, want, content)
+			}
+		}
+	}
+
+	// The table is created once, later changes need migrations of their own
+	if err := cmd.RunE(cmd, []string{"User"}); err == nil {
+		t.Error("Creating the users table twice should fail")
+	}
+
+	// --interactive asks before patching main.go
+	os.WriteFile(mainPath, unpatched, 0644)
+	var out strings.Builder
+	ask := NewMakeMigrationCmd()
+	ask.Flags().Set("interactive", "true")
+	ask.SetIn(strings.NewReader("s\n"))
+	ask.SetOut(&out)
+	if err := ask.RunE(ask, []string{"Order", "id:string", "total:float64"}); err != nil {
+		t.Fatalf("Interactive run failed: %v", err)
+	}
+	if !strings.Contains(out.String(), mainPath+" already exists") {
+		t.Errorf("Expected a prompt for main.go, got:\n%s", out.String())
+	}
+	if content, _ := os.ReadFile(mainPath); string(content) != string(unpatched) {
+		t.Errorf("Skipped main.go was patched:\n%s", content)
+	}
+
+	memory := NewMakeMigrationCmd()
+	memory.Flags().Set("driver", "memory")
+	if err := memory.RunE(memory, []string{"Order", "total:float64"}); err == nil {
+		t.Error("The memory driver has no migrations")
+	}
+}
+
+func TestMakeMigrationDiff(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	now = func() time.Time { return time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	os.WriteFile("go.mod", []byte("module github.com/user/test-project\n\ngo 1.21\n"), 0644)
+	os.WriteFile(".sazerac.yaml", []byte("database:\n  driver: postgres\n"), 0644)
+
+	entity := NewMakeEntityCmd()
+	if err := entity.RunE(entity, []string{"User", "name:string", "nickname:*string"}); err != nil {
+		t.Fatalf("make entity failed: %v", err)
+	}
+	cmd := NewMakeMigrationCmd()
+	cmd.Flags().Set("diff", "true")
+	// Without earlier migrations the table is created
+	if err := cmd.RunE(cmd, []string{"User"}); err != nil {
+		t.Fatalf("Command execution failed: %v", err)
+	}
+	if _, err := os.Stat("migrations/20261018120000_create_users.up.sql"); err != nil {
+		t.Fatalf("The create migration was not generated: %v", err)
+	}
+
+	path := filepath.Join("internal", "domain", "entities", "user.go")
+	content, _ := os.ReadFile(path)
+	content = []byte(strings.Replace(string(content), "Nickname *string `json:\"nickname\" db:\"nickname\"`", "Nickname string `json:\"nickname\" db:\"nickname\"`\n\tAge int `json:\"age\" db:\"age\"`", 1))
+	os.WriteFile(path, content, 0644)
+
+	now = func() time.Time { return time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC) }
+	if err := cmd.RunE(cmd, []string{"User"}); err != nil {
+		t.Fatalf("Command execution failed: %v", err)
+	}
+
+	files := map[string][]string{
+		"migrations/20261019090000_alter_users.up.sql": {
+			`UPDATE "users" SET "nickname" = '' WHERE "nickname" IS NULL;`,
+			`ALTER TABLE "users" ALTER COLUMN "nickname" SET NOT NULL;`,
+			`ALTER TABLE "users" ADD COLUMN "age" BIGINT DEFAULT 0 NOT NULL;`,
+		},
+		"migrations/20261019090000_alter_users.down.sql": {
+			`ALTER TABLE "users" ALTER COLUMN "nickname" DROP NOT NULL;`,
+			`ALTER TABLE "users" DROP COLUMN "age";`,
+		},
+	}
+	for path, wants := range files {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Errorf("%s was not generated: %v", path, err)
+			continue
+		}
+		for _, want := range wants {
+			if !strings.Contains(string(content), want) {
+				t.Errorf("%s is missing %q:\n%s", path, want, content)
+			}
+		}
+	}
+
+	// The migrations now leave the table the entity needs
+	now = func() time.Time { return time.Date(2026, 10, 20, 9, 0, 0, 0, time.UTC) }
+	if err := cmd.RunE(cmd, []string{"User"}); err != nil {
+		t.Fatalf("Command execution failed: %v", err)
+	}
+	if matches, _ := filepath.Glob("migrations/20261020090000_*"); len(matches) != 0 {
+		t.Errorf("An up to date table should need no migration, got %v", matches)
+	}
+}
+
+func TestImportSQL(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	legacyDB := filepath.Join(originalDir, "..", "testdata", "legacy.db")
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	os.WriteFile("go.mod", []byte("module github.com/user/test-project\n\ngo 1.21\n"), 0644)
+	os.WriteFile(".sazerac.yaml", []byte("database:\n  driver: postgres\n"), 0644)
+	os.WriteFile("schema.sql", []byte(`CREATE TABLE person (
+    person_id BIGSERIAL PRIMARY KEY,
+    full_name VARCHAR(120) NOT NULL,
+    birth_date DATE
+);
+CREATE TABLE order_items (
+    id BIGINT NOT NULL PRIMARY KEY,
+    quantity INTEGER NOT NULL
+);
+CREATE TABLE person_tags (person_id BIGINT, tag TEXT, PRIMARY KEY (person_id, tag));
+`), 0644)
+
+	cmd := NewImportSQLCmd()
+	if cmd.Use != "sql [schema.sql]" {
+		t.Errorf("Expected Use to be 'sql [schema.sql]', got %q", cmd.Use)
+	}
+	if err := cmd.RunE(cmd, []string{"schema.sql"}); err != nil {
+		t.Fatalf("Command execution failed: %v", err)
+	}
+
+	files := map[string][]string{
+		"internal/domain/entities/person.go": {
+			"ID        int64      `json:\"id\" db:\"person_id\"`",
+			"FullName  string",
+			"BirthDate *time.Time",
+		},
+		"internal/domain/entities/order_item.go": {
+			"type OrderItem struct",
+			"Quantity int32",
+		},
+		"infrastructure/database/postgres/person_postgres.go": {
+			`SELECT "person_id", "full_name", "birth_date" FROM "person" WHERE "person_id" = $1`,
+		},
+		"infrastructure/database/postgres/order_item_postgres.go": {
+			`FROM "order_items"`,
+		},
+		"internal/repository/person_repository.go": {
+			"type PersonRepository interface",
+		},
+		"internal/domain/mappers/order_item_mapper.go": {
+			"type OrderItemDTO struct",
+		},
+		".sazerac.yaml": {
+			"tables:\n    Person: person\n",
+		},
+	}
+	for path, wants := range files {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Errorf("%s was not generated: %v", path, err)
+			continue
+		}
+		for _, want := range wants {
+			if !strings.Contains(string(content), want) {
+				t.Errorf("%s is missing %q:\n%s", path, want, content)
+			}
+		}
+	}
+	if _, err := os.Stat("internal/domain/entities/person_tag.go"); err == nil {
+		t.Error("A table without a single column key should be skipped")
+	}
+
+	// Later commands keep the table the entity was imported from
+	repo := NewMakeRepoCmd()
+	repo.Flags().Set("driver", "mysql")
+	if err := repo.RunE(repo, []string{"Person"}); err != nil {
+		t.Fatalf("make repo failed: %v", err)
+	}
+	content, _ := os.ReadFile("infrastructure/database/mysql/person_mysql.go")
+	if !strings.Contains(string(content), "FROM `person` WHERE") {
+		t.Errorf("The MySQL repository should query the person table:\n%s", content)
+	}
+
+	sqlite := NewImportSQLCmd()
+	sqlite.Flags().Set("sqlite", legacyDB)
+	sqlite.Flags().Set("table", "users")
+	if err := sqlite.RunE(sqlite, nil); err != nil {
+		t.Fatalf("Command execution failed: %v", err)
+	}
+	content, _ = os.ReadFile("internal/domain/entities/user.go")
+	if !strings.Contains(string(content), "Nickname  *string") || !strings.Contains(string(content), "CreatedAt time.Time") {
+		t.Errorf("The users table was not imported:\n%s", content)
+	}
+
+	for _, args := range [][]string{nil, {"missing.sql"}} {
+		cmd := NewImportSQLCmd()
+		if err := cmd.RunE(cmd, args); err == nil {
+			t.Errorf("import sql %v should fail", args)
+		}
+	}
+}
+
+func TestMakeHandlerHTTP(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	os.WriteFile("go.mod", []byte("module github.com/user/test-project\n\ngo 1.22\n"), 0644)
+	os.WriteFile(".sazerac.yaml", []byte("database:\n  driver: memory\n"), 0644)
+
+	all := NewMakeAllCmd()
+	all.Flags().Set("kind", "http")
+	if err := all.RunE(all, []string{"User", "CreateUser", "name:string"}); err != nil {
+		t.Fatalf("make all failed: %v", err)
+	}
+
+	// A use case of the entity with an ID in its input
+	os.MkdirAll("internal/usecases", 0755)
+	os.WriteFile("internal/usecases/get_user_usecase.go", []byte(`package usecases
+
+import "github.com/user/test-project/internal/repository"
+
+type GetUserInput struct {
+	ID int64
+}
+
+type GetUserUseCase struct {
+	Repo repository.UserRepository
+}
+`), 0644)
+	handler := NewMakeHandlerCmd()
+	handler.Flags().Set("kind", "http")
+	if err := handler.RunE(handler, []string{"GetUser", "GetUser"}); err != nil {
+		t.Fatalf("make handler failed: %v", err)
+	}
+	di := NewMakeDiCmd()
+	if err := di.RunE(di, []string{"GetUser", "User"}); err != nil {
+		t.Fatalf("make di failed: %v", err)
+	}
+
+	// List use cases answer with a message of their own
+	usecase := NewMakeUseCaseCmd()
+	if err := usecase.RunE(usecase, []string{"ListUsers", "User"}); err != nil {
+		t.Fatalf("make usecase failed: %v", err)
+	}
+	handler = NewMakeHandlerCmd()
+	handler.Flags().Set("kind", "grpc")
+	if err := handler.RunE(handler, []string{"ListUsers", "ListUsers"}); err != nil {
+		t.Fatalf("make handler failed: %v", err)
+	}
+
+	files := map[string][]string{
+		"internal/handlers/create_user_handler.go": {
+			`mux.Handle("POST /users", h)`,
+			"json.NewDecoder(r.Body).Decode(&input)",
+			"writeJSON(w, http.StatusCreated, entity)",
+		},
+		"internal/handlers/get_user_handler.go": {
+			`mux.Handle("GET /users/{id}", h)`,
+			`id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)`,
+			"input.ID = id",
+		},
+		"internal/handlers/respond.go": {
+			"case errors.Is(err, domain.ErrInvalidInput), errors.Is(err, domain.ErrInvalidFilter):",
+			"return http.StatusNotFound",
+		},
+		"internal/domain/errors.go": {
+			`ErrInvalidInput = errors.New("invalid input")`,
+		},
+		"cmd/test-project/di/di.go": {
+			"CreateUserHandler.Register(router)",
+			"GetUserHandler.Register(router)",
+			"Router: ",
+		},
+		"cmd/test-project/main.go": {
+			"if err := serve(container.Router); err != nil {",
+		},
+		"cmd/test-project/server.go": {
+			"signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)",
+			"server.Shutdown(ctx)",
+		},
+	}
+	for path, wants := range files {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Errorf("%s was not generated: %v", path, err)
+			continue
+		}
+		for _, want := range wants {
+			if !strings.Contains(string(content), want) {
+				t.Errorf("%s is missing %q:\n%s", path, want, content)
+			}
+		}
+	}
+	if content, _ := os.ReadFile("cmd/test-project/main.go"); strings.Contains(string(content), "Run(") {
+		t.Errorf("main.go runs an HTTP handler:\n%s", content)
+	}
+
+	bad := NewMakeHandlerCmd()
+	bad.Flags().Set("kind", "smtp")
+	if err := bad.RunE(bad, []string{"GetUser", "GetUser"}); err == nil {
+		t.Error("An unknown kind should fail")
+	}
+}
+
+func TestMakeHandlerRouter(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	os.WriteFile("go.mod", []byte("module github.com/user/test-project\n\ngo 1.22\n"), 0644)
+	os.WriteFile(".sazerac.yaml", []byte("database:\n  driver: memory\n"), 0644)
+
+	all := NewMakeAllCmd()
+	all.Flags().Set("kind", "http")
+	all.Flags().Set("router", "echo")
+	if err := all.RunE(all, []string{"User", "CreateUser", "name:string"}); err != nil {
+		t.Fatalf("make all failed: %v", err)
+	}
+
+	// The router of the first handler is kept for the next ones
+	os.MkdirAll("internal/usecases", 0755)
+	os.WriteFile("internal/usecases/get_user_usecase.go", []byte(`package usecases
+
+type GetUserInput struct {
+	ID string
+}
+`), 0644)
+	handler := NewMakeHandlerCmd()
+	handler.Flags().Set("kind", "http")
+	if err := handler.RunE(handler, []string{"GetUser", "GetUser"}); err != nil {
+		t.Fatalf("make handler failed: %v", err)
+	}
+	di := NewMakeDiCmd()
+	if err := di.RunE(di, []string{"GetUser", "User"}); err != nil {
+		t.Fatalf("make di failed: %v", err)
+	}
+
+	// List use cases answer with a message of their own
+	usecase := NewMakeUseCaseCmd()
+	if err := usecase.RunE(usecase, []string{"ListUsers", "User"}); err != nil {
+		t.Fatalf("make usecase failed: %v", err)
+	}
+	handler = NewMakeHandlerCmd()
+	handler.Flags().Set("kind", "grpc")
+	if err := handler.RunE(handler, []string{"ListUsers", "ListUsers"}); err != nil {
+		t.Fatalf("make handler failed: %v", err)
+	}
+
+	files := map[string][]string{
+		".sazerac.yaml": {
+			"http:\n  router: echo\n",
+		},
+		"internal/handlers/create_user_handler.go": {
+			`e.Add("POST", "/users", h.Handle)`,
+			"func (h *CreateUserHandler) Handle(c echo.Context) error {",
+			"w, r := c.Response(), c.Request()",
+			"return nil\n\t}",
+		},
+		"internal/handlers/get_user_handler.go": {
+			`e.Add("GET", "/users/:id", h.Handle)`,
+			`input.ID = c.Param("id")`,
+		},
+		"cmd/test-project/di/di.go": {
+			"router := echo.New()",
+			"Router            *echo.Echo",
+			"GetUserHandler.Register(router)",
+		},
+	}
+	for path, wants := range files {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Errorf("%s was not generated: %v", path, err)
+			continue
+		}
+		for _, want := range wants {
+			if !strings.Contains(string(content), want) {
+				t.Errorf("%s is missing %q:\n%s", path, want, content)
+			}
+		}
+	}
+
+	other := NewMakeHandlerCmd()
+	other.Flags().Set("kind", "http")
+	other.Flags().Set("router", "chi")
+	if err := other.RunE(other, []string{"ListUsers", "ListUsers"}); err == nil {
+		t.Error("A router other than the configured one should fail")
+	}
+}
+
+func TestMakeHandlerGRPC(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	os.WriteFile("go.mod", []byte("module github.com/user/test-project\n\ngo 1.22\n"), 0644)
+	os.WriteFile(".sazerac.yaml", []byte("database:\n  driver: memory\n"), 0644)
+
+	all := NewMakeAllCmd()
+	all.Flags().Set("kind", "grpc")
+	if err := all.RunE(all, []string{"User", "CreateUser", "name:string", "created_at:time.Time"}); err != nil {
+		t.Fatalf("make all failed: %v", err)
+	}
+
+	// A use case of the entity with an ID in its input
+	os.WriteFile("internal/usecases/get_user_usecase.go", []byte(`package usecases
+
+import "github.com/user/test-project/internal/repository"
+
+type GetUserInput struct {
+	ID string
+}
+
+type GetUserUseCase struct {
+	Repo repository.UserRepository
+}
+`), 0644)
+	handler := NewMakeHandlerCmd()
+	handler.Flags().Set("kind", "grpc")
+	if err := handler.RunE(handler, []string{"GetUser", "GetUser"}); err != nil {
+		t.Fatalf("make handler failed: %v", err)
+	}
+	di := NewMakeDiCmd()
+	if err := di.RunE(di, []string{"GetUser", "User"}); err != nil {
+		t.Fatalf("make di failed: %v", err)
+	}
+
+	// List use cases answer with a message of their own
+	usecase := NewMakeUseCaseCmd()
+	if err := usecase.RunE(usecase, []string{"ListUsers", "User"}); err != nil {
+		t.Fatalf("make usecase failed: %v", err)
+	}
+	handler = NewMakeHandlerCmd()
+	handler.Flags().Set("kind", "grpc")
+	if err := handler.RunE(handler, []string{"ListUsers", "ListUsers"}); err != nil {
+		t.Fatalf("make handler failed: %v", err)
+	}
+
+	// Use cases without input fields get a request with the entity fields,
+	// or only the ID for Get and Delete
+	os.WriteFile("internal/usecases/delete_user_usecase.go", []byte(`package usecases
+
+type DeleteUserInput struct {
+	// TODO: add definition
+}
+`), 0644)
+	handler = NewMakeHandlerCmd()
+	handler.Flags().Set("kind", "grpc")
+	if err := handler.RunE(handler, []string{"DeleteUser", "DeleteUser"}); err != nil {
+		t.Fatalf("make handler failed: %v", err)
+	}
+
+	files := map[string][]string{
+		"proto/user/v1/user.proto": {
+			"package user.v1;",
+			`import "google/protobuf/timestamp.proto";`,
+			`option go_package = "github.com/user/test-project/gen/user/v1;userv1";`,
+			"message User {\n  string id = 1;\n  string name = 2;\n  google.protobuf.Timestamp created_at = 3;\n}",
+			"message GetUserRequest {\n  string id = 1;\n}",
+			"service CreateUserService {\n  rpc CreateUser(CreateUserRequest) returns (User);\n}",
+			"service GetUserService {\n  rpc GetUser(GetUserRequest) returns (User);\n}",
+			"message ListUsersRequest {\n  string id = 1;\n  string name = 2;\n  google.protobuf.Timestamp created_at = 3;\n}",
+			"message ListUsersResponse {\n  repeated User items = 1;\n}",
+			"message DeleteUserRequest {\n  string id = 1;\n}",
+			"service ListUsersService {\n  rpc ListUsers(ListUsersRequest) returns (ListUsersResponse);\n}",
+		},
+		"internal/handlers/grpc/list_users_handler.go": {
+			"(*userv1.ListUsersResponse, error)",
+			"res.Items = append(res.Items, userToProto(e))",
+			"// TODO: add the fields of ListUsersInput and set them from req",
+		},
+		"internal/handlers/grpc/create_user_handler.go": {
+			"userv1.UnimplementedCreateUserServiceServer",
+			"userv1.RegisterCreateUserServiceServer(server, h)",
+			"return nil, toStatus(err)",
+		},
+		"internal/handlers/grpc/get_user_handler.go": {
+			"ID: req.Id,",
+			"return userToProto(entity), nil",
+		},
+		"internal/handlers/grpc/status.go": {
+			"case errors.Is(err, domain.ErrNotFound):\n\t\treturn codes.NotFound",
+			"return codes.Internal",
+		},
+		"internal/handlers/grpc/user_message.go": {
+			"CreatedAt: timestamppb.New(e.CreatedAt),",
+		},
+		"cmd/test-project/di/di.go": {
+			"CreateUserHandler.Register(grpcServer)",
+			"GetUserHandler.Register(grpcServer)",
+			"GRPCServer: ",
+		},
+		"cmd/test-project/main.go": {
+			"if err := serveGRPC(container.GRPCServer); err != nil {",
+		},
+		"cmd/test-project/grpc_server.go": {
+			`os.Getenv("GRPC_ADDR")`,
+			"server.GracefulStop()",
+		},
+	}
+	for path, wants := range files {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Errorf("%s was not generated: %v", path, err)
+			continue
+		}
+		for _, want := range wants {
+			if !strings.Contains(string(content), want) {
+				t.Errorf("%s is missing %q:\n%s", path, want, content)
+			}
+		}
+	}
+	if proto, _ := os.ReadFile("proto/user/v1/user.proto"); strings.Count(string(proto), "message User {") != 1 {
+		t.Errorf("The entity message is declared more than once:\n%s", proto)
+	}
+}
+
+func TestMakeHandlerCLI(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	os.WriteFile("go.mod", []byte("module github.com/user/test-project\n\ngo 1.22\n"), 0644)
+	os.WriteFile(".sazerac.yaml", []byte("database:\n  driver: memory\n"), 0644)
+
+	// A use case whose input the flags fill
+	os.MkdirAll("internal/usecases", 0755)
+	os.WriteFile("internal/usecases/import_users_usecase.go", []byte(`package usecases
+
+import (
+	"time"
+
+	"github.com/user/test-project/internal/repository"
+)
+
+type ImportUsersInput struct {
+	Path   string
+	Limit  int
+	Since  time.Time
+	Labels map[string]string
+}
+
+type ImportUsersUseCase struct {
+	Repo repository.UserRepository
+}
+`), 0644)
+	handler := NewMakeHandlerCmd()
+	handler.Flags().Set("kind", "cli")
+	if err := handler.RunE(handler, []string{"ImportUsers", "ImportUsers"}); err != nil {
+		t.Fatalf("make handler failed: %v", err)
+	}
+	all := NewMakeAllCmd()
+	all.Flags().Set("kind", "cli")
+	if err := all.RunE(all, []string{"User", "CreateUser", "name:string"}); err != nil {
+		t.Fatalf("make all failed: %v", err)
+	}
+	di := NewMakeDiCmd()
+	if err := di.RunE(di, []string{"ImportUsers", "User"}); err != nil {
+		t.Fatalf("make di failed: %v", err)
+	}
+
+	files := map[string][]string{
+		"internal/handlers/cli/import_users_handler.go": {
+			`Use:   "import-users",`,
+			`cmd.Flags().StringVar(&input.Path, "path", "", "path")`,
+			`cmd.Flags().IntVar(&input.Limit, "limit", 0, "limit")`,
+			`cmd.Flags().Var((*timeValue)(&input.Since), "since", "since (RFC 3339)")`,
+			`cmd.Flags().StringToStringVar(&input.Labels, "labels", nil, "labels")`,
+			"result, err := h.UC.Execute(input)",
+			"return writeOutput(cmd.OutOrStdout(), output, result)",
+		},
+		"internal/handlers/cli/create_user_handler.go": {
+			`Use:   "create-user",`,
+		},
+		"internal/handlers/cli/root.go": {
+			`Use:   "test-project",`,
+			"type timeValue time.Time",
+		},
+		"internal/handlers/cli/output.go": {
+			"func writeOutput(w io.Writer, format outputFormat, result any) error {",
+			"tabwriter.NewWriter",
+		},
+		"cmd/test-project/di/di.go": {
+			`clihandlers "github.com/user/test-project/internal/handlers/cli"`,
+			"cliRoot := clihandlers.NewRoot()",
+			"CreateUserHandler.Register(cliRoot)",
+			"ImportUsersHandler.Register(cliRoot)",
+			"CLI: ",
+		},
+		"cmd/test-project/main.go": {
+			"if err := execute(container.CLI); err != nil {",
+		},
+		"cmd/test-project/cli.go": {
+			"return root.ExecuteContext(ctx)",
+		},
+	}
+	for path, wants := range files {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Errorf("%s was not generated: %v", path, err)
+			continue
+		}
+		for _, want := range wants {
+			if !strings.Contains(string(content), want) {
+				t.Errorf("%s is missing %q:\n%s", path, want, content)
+			}
+		}
+	}
+	if content, _ := os.ReadFile("cmd/test-project/main.go"); strings.Count(string(content), "execute(") != 1 {
+		t.Errorf("main.go should execute the CLI once:\n%s", content)
+	}
+}
+
+func TestMakeHandlerConsumer(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	os.WriteFile("go.mod", []byte("module github.com/user/test-project\n\ngo 1.22\n"), 0644)
+	os.WriteFile(".sazerac.yaml", []byte("context: true\ndatabase:\n  driver: memory\n"), 0644)
+
+	all := NewMakeAllCmd()
+	all.Flags().Set("kind", "consumer")
+	if err := all.RunE(all, []string{"User", "SendWelcomeEmail", "name:string"}); err != nil {
+		t.Fatalf("make all failed: %v", err)
+	}
+	handler := NewMakeHandlerCmd()
+	handler.Flags().Set("kind", "consumer")
+	handler.Flags().Set("topic", "users.created")
+	if err := handler.RunE(handler, []string{"CreateProfile", "SendWelcomeEmail"}); err != nil {
+		t.Fatalf("make handler failed: %v", err)
+	}
+
+	files := map[string][]string{
+		"internal/handlers/consumer/send_welcome_email_handler.go": {
+			`const SendWelcomeEmailTopic = "send-welcome-email"`,
+			"return broker.Subscribe(SendWelcomeEmailTopic, func(ctx context.Context, msg messaging.Message) error {",
+			"json.Unmarshal(msg.Payload, &input)",
+			"_, err := h.UC.Execute(ctx, input)",
+		},
+		"internal/handlers/consumer/create_profile_handler.go": {
+			`const CreateProfileTopic = "users.created"`,
+		},
+		"internal/handlers/consumer/retry.go": {
+			"func DeadLetterTopic(topic string) string {",
+			"errors.Is(err, domain.ErrInvalidInput)",
+			"dlq.Publish(ctx, messaging.Message{Topic: DeadLetterTopic(msg.Topic)",
+		},
+		"internal/messaging/messaging.go": {
+			"type Subscriber interface {",
+			"type Broker interface {",
+		},
+		"infrastructure/messaging/inmemory/broker.go": {
+			"func (b *Broker) Publish(ctx context.Context, msg messaging.Message) error {",
+			"func (b *Broker) Run(ctx context.Context) error {",
+		},
+		"internal/domain/errors.go": {
+			"ErrInvalidInput",
+		},
+		"cmd/test-project/di/di.go": {
+			`consumerhandlers "github.com/user/test-project/internal/handlers/consumer"`,
+			"broker := inmemory.NewBroker()",
+			"if err := SendWelcomeEmailHandler.Register(broker); err != nil {\n\t\treturn nil, err\n\t}",
+			"Broker                  messaging.Broker",
+		},
+		"cmd/test-project/main.go": {
+			"if err := consume(container.Broker); err != nil {",
+		},
+		"cmd/test-project/consumer.go": {
+			"if err := subscriber.Run(ctx); err != nil {",
+		},
+	}
+	for path, wants := range files {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Errorf("%s was not generated: %v", path, err)
+			continue
+		}
+		for _, want := range wants {
+			if !strings.Contains(string(content), want) {
+				t.Errorf("%s is missing %q:\n%s", path, want, content)
+			}
+		}
+	}
+
+	// make di finds the kind from the handler
+	di := NewMakeDiCmd()
+	if err := di.RunE(di, []string{"SendWelcomeEmail", "User"}); err != nil {
+		t.Fatalf("make di failed: %v", err)
+	}
+	if content, _ := os.ReadFile("cmd/test-project/di/di.go"); strings.Count(string(content), "Register(broker)") != 1 {
+		t.Errorf("The handler should be registered once:\n%s", content)
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	os.WriteFile("go.mod", []byte("module github.com/user/test-project\n\ngo 1.22\n"), 0644)
+	os.WriteFile(".sazerac.yaml", []byte("database:\n  driver: memory\n"), 0644)
+
+	spec := NewOpenAPICmd()
+	if err := spec.RunE(spec, nil); err == nil {
+		t.Error("A project without HTTP handlers should fail")
+	}
+
+	all := NewMakeAllCmd()
+	all.Flags().Set("kind", "http")
+	all.Flags().Set("router", "chi")
+	if err := all.RunE(all, []string{"User", "CreateUser", "name:string"}); err != nil {
+		t.Fatalf("make all failed: %v", err)
+	}
+	console := NewMakeHandlerCmd()
+	if err := console.RunE(console, []string{"ImportUsers", "ImportUsers"}); err != nil {
+		t.Fatalf("make handler failed: %v", err)
+	}
+
+	if err := spec.RunE(spec, nil); err != nil {
+		t.Fatalf("openapi failed: %v", err)
+	}
+	content, err := os.ReadFile("openapi.yaml")
+	if err != nil {
+		t.Fatalf("openapi.yaml was not generated: %v", err)
+	}
+	for _, want := range []string{
+		"  title: test-project\n",
+		"  /users:\n    post:\n",
+		"              $ref: '#/components/schemas/CreateUserInput'\n",
+		"                $ref: '#/components/schemas/User'\n",
+		"    User:\n      type: object\n      properties:\n        id:\n          type: string\n        name:\n          type: string\n",
+	} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("openapi.yaml is missing %q:\n%s", want, content)
+		}
+	}
+	if strings.Contains(string(content), "ImportUsers") {
+		t.Errorf("openapi.yaml documents a console handler:\n%s", content)
+	}
+
+	// The spec follows the code without --force
+	os.WriteFile("openapi.yaml", []byte("openapi: 3.0.3\n"), 0644)
+	spec = NewOpenAPICmd()
+	spec.Flags().Set("api-version", "2.0.0")
+	if err := spec.RunE(spec, nil); err != nil {
+		t.Fatalf("openapi failed: %v", err)
+	}
+	if content, _ := os.ReadFile("openapi.yaml"); !strings.Contains(string(content), "  version: 2.0.0\n") {
+		t.Errorf("openapi.yaml was not regenerated:\n%s", content)
+	}
+}
@@ -0,0 +1,214 @@
+package commands
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/fsjorgeluis/sazerac/internal"
+	"github.com/spf13/cobra"
+)
+
+func NewGenerateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate every component described by a schema file",
+		Long: `Generate every component described by a schema file.
+
+The schema (YAML or JSON) lists the entities with their fields, relations and
+use cases, and sazerac runs the equivalent of make all for all of them:
+
+  entities:
+    - name: User
+      fields: [name:string, email:string]
+      usecases: [CreateUser]
+    - name: Order
+      fields:
+        - total:float64
+        - name: note
+          type: string
+          optional: true
+      relations:
+        - belongs_to: User
+      usecases: [CreateOrder]
+      crud: true
+
+The schema is the source of truth: entities, repositories, mappers and
+validators are generated from it, and new use cases are added to the DI
+container and main.go on every run. Use cases and handlers are only created
+when missing, since they hold your own code. After editing the schema, the
+files it changes are refused like with any make command: pass --force to
+regenerate them (use cases and handlers included), --interactive to decide
+for every file or --skip-existing to keep them.
+
+crud: true gives the repository of an entity the full set of CRUD methods
+(see make repo --crud); --crud does it for every entity. --kind picks the
+kind of the handlers and --router the router of HTTP handlers (see make
+handler). Existing handlers keep their kind unless --force replaces them.`,
+		Example: "  sazerac generate -f schema.yaml",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, _ := cmd.Flags().GetString("file")
+
+			schema, err := internal.LoadSchema(path)
+			if err != nil {
+				return err
+			}
+
+			cfg, err := internal.LoadConfig()
+			if err != nil {
+				return err
+			}
+
+			resolved, err := schema.Fields()
+			if err != nil {
+				return err
+			}
+
+			driver, err := driverOption(cmd)
+			if err != nil {
+				return err
+			}
+			kind, err := kindOption(cmd)
+			if err != nil {
+				return err
+			}
+
+			// Files derived from the schema are always regenerated, scaffolds
+			// the user fills in are not
+			owned, scaffold := &internal.Plan{}, &internal.Plan{}
+			var wirings []internal.Wiring
+			var router internal.Router
+
+			for _, e := range schema.Entities {
+				name := internal.ToPascalCase(e.Name)
+				fields := resolved[name]
+				fields.ApplyTags(cfg.Tags)
+
+				if _, err := planEntity(owned, name, fields); err != nil {
+					return err
+				}
+				crud, err := crudOption(cmd, name)
+				if err != nil {
+					return err
+				}
+				if _, _, err := planRepo(owned, name, fields, repoOptions{Driver: driver, CRUD: crud || e.CRUD}); err != nil {
+					return err
+				}
+				if _, err := planMapper(owned, name, fields); err != nil {
+					return err
+				}
+				if _, err := planValidator(owned, name, fields); err != nil {
+					return err
+				}
+
+				for _, uc := range e.UseCases {
+					filter := hasFilter(name, owned)
+					if _, err := planUseCase(scaffold, uc, name, fields, useCaseOptions{CRUD: hasCRUD(name, owned)}); err != nil {
+						return err
+					}
+					if kind == internal.KindHTTP && router.Name == "" {
+						// The router is configured with the first handler
+						if router, err = routerOption(cmd, owned); err != nil {
+							return err
+						}
+					}
+					kept, err := keptHandler(cmd, uc, kind)
+					if err != nil {
+						return err
+					}
+					if kept {
+						// Wired as the kind of the handler on disk
+						wirings = append(wirings, internal.Wiring{UseCase: uc, Entity: name, Driver: driver.Name})
+						continue
+					}
+					if _, err := planHandler(scaffold, uc, uc, handlerOptions{Kind: kind, Entity: name, Fields: fields, Router: router, Filter: filter}); err != nil {
+						return err
+					}
+					wirings = append(wirings, internal.Wiring{UseCase: uc, Entity: name, Driver: driver.Name, Kind: kind, Router: router.Name})
+				}
+			}
+
+			if len(wirings) > 0 {
+				projectName := internal.GetProjectName()
+				if projectName == "" {
+					fmt.Println("⚠️  Warning: Could not determine project name. Skipping DI generation.")
+				} else {
+					if _, err := planDI(owned, projectName, wirings); err != nil {
+						return err
+					}
+					if _, err := planMain(owned, projectName, wirings); err != nil {
+						return err
+					}
+				}
+			}
+
+			if err := applyGenerated(cmd, owned, scaffold); err != nil {
+				return err
+			}
+			driverHint(driver)
+			handlerHint(kind)
+			routerHint(router)
+			return nil
+		},
+	}
+	cmd.Flags().StringP("file", "f", "schema.yaml", "Schema file describing the domain (YAML or JSON)")
+	addOverwriteFlags(cmd)
+	addDriverFlag(cmd)
+	addCRUDFlag(cmd)
+	addKindFlag(cmd)
+	addRouterFlag(cmd)
+
+	return cmd
+}
+
+// keptHandler reports whether useCase has a handler of another kind than
+// kind that generate keeps: only --force replaces it. Interactive runs fail
+// on such conflicts, as the handler to wire depends on the answer.
+func keptHandler(cmd *cobra.Command, useCase, kind string) (bool, error) {
+	existing, err := existingHandlerKind(useCase)
+	if err != nil || existing == "" || existing == kind {
+		return false, err
+	}
+	switch writeOptions(cmd).Mode {
+	case internal.OverwriteForce:
+		return false, nil
+	case internal.OverwritePrompt:
+		return false, fmt.Errorf("%s already has a %s handler: generate it with --kind %s, or replace it with --force", useCase, existing, existing)
+	}
+	return true, nil
+}
+
+// applyGenerated commits the plans of generate. Without an explicit overwrite
+// flag the scaffolds are only created, while the owned files refuse to
+// replace files that would change, as with every make command.
+func applyGenerated(cmd *cobra.Command, owned, scaffold *internal.Plan) error {
+	// Files both plans hold, such as the domain errors, are owned
+	scaffold.Changes = slices.DeleteFunc(scaffold.Changes, func(c *internal.FileChange) bool {
+		return owned.Change(c.Path) != nil
+	})
+
+	ownedOpts, scaffoldOpts := writeOptions(cmd), writeOptions(cmd)
+	if scaffoldOpts.Mode == internal.OverwriteNever {
+		scaffoldOpts.Mode = internal.OverwriteSkip
+	}
+
+	if boolFlag(cmd, "dry-run") {
+		owned.Print(cmd.OutOrStdout(), ownedOpts.Mode)
+		scaffold.Print(cmd.OutOrStdout(), scaffoldOpts.Mode)
+		return nil
+	}
+
+	if err := owned.Apply(ownedOpts); err != nil {
+		return err
+	}
+	if err := scaffold.Apply(scaffoldOpts); err != nil {
+		return err
+	}
+
+	for _, change := range append(owned.Changes, scaffold.Changes...) {
+		served("Served 🥃:", change)
+	}
+	fmt.Println("✔️  Everything served successfully 🥃")
+
+	return nil
+}
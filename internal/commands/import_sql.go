@@ -0,0 +1,185 @@
+package commands
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/fsjorgeluis/sazerac/internal"
+	"github.com/spf13/cobra"
+)
+
+func NewImportSQLCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sql [schema.sql]",
+		Short: "Generate entities, repositories and mappers from an existing database",
+		Long: `Generate entities, repositories and mappers from an existing database.
+
+The tables are read from the CREATE TABLE statements of a SQL script, such as
+a schema dump (mysqldump --no-data, pg_dump --schema-only, sqlite3 .schema),
+or straight from a SQLite database file with --sqlite. Every table becomes an
+entity named after it in singular (order_items is OrderItem), with a field
+per column:
+
+  - integer, float, boolean, text, date/time, binary and JSON columns map to
+    the Go types of the same size (BIGINT is int64, TIMESTAMPTZ time.Time)
+  - nullable columns are optional fields, pointers for scalar types
+  - the primary key is the ID field, whatever the column is called
+  - columns named unlike their field keep their name in the db tag
+
+Tables are stored in the tables their entities were read from: the ones not
+named as make would name them are recorded in database.tables of
+.sazerac.yaml. Tables without a single column primary key are skipped.
+
+--table imports only some tables, --crud gives the repositories every CRUD
+method and --driver picks the database they are written for.`,
+		Example: "  sazerac import sql schema.sql --driver postgres\n  sazerac import sql --sqlite app.db --table users --table orders",
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sqlitePath, _ := cmd.Flags().GetString("sqlite")
+			if (len(args) == 0) == (sqlitePath == "") {
+				return fmt.Errorf("pass either a SQL file or --sqlite with a database file")
+			}
+
+			driver, err := driverOption(cmd)
+			if err != nil {
+				return err
+			}
+
+			// Column types are read in the dialect of the database they
+			// come from, when it is known
+			dialect := driver.Dialect
+			var ddl string
+			if sqlitePath != "" {
+				dialect = internal.DialectSQLite
+				stmts, err := internal.ReadSQLiteSchema(sqlitePath)
+				if err != nil {
+					return err
+				}
+				ddl = strings.Join(stmts, "\n")
+			} else {
+				content, err := os.ReadFile(args[0])
+				if err != nil {
+					return err
+				}
+				ddl = string(content)
+			}
+
+			tables, err := internal.ParseDDL(ddl)
+			if err != nil {
+				return err
+			}
+			only, _ := cmd.Flags().GetStringSlice("table")
+			for _, name := range only {
+				if !slices.ContainsFunc(tables, func(t internal.ImportedTable) bool { return t.Name == name }) {
+					return fmt.Errorf("table %s is not in the schema", name)
+				}
+			}
+
+			plan := &internal.Plan{}
+			changes, err := planImport(cmd, plan, tables, only, dialect, driver)
+			if err != nil {
+				return err
+			}
+			if len(changes) == 0 {
+				return fmt.Errorf("no table to import")
+			}
+
+			if applied, err := commit(cmd, plan); !applied || err != nil {
+				return err
+			}
+
+			for _, change := range changes {
+				served("Served 🥃:", change)
+			}
+			fmt.Println("✔️  Database imported successfully 🥃")
+			driverHint(driver)
+			return nil
+		},
+	}
+	cmd.Flags().String("sqlite", "", "SQLite database file to read the tables from")
+	cmd.Flags().StringSlice("table", nil, "Import only this table (repeatable)")
+	addOverwriteFlags(cmd)
+	addDriverFlag(cmd)
+	addCRUDFlag(cmd)
+
+	return cmd
+}
+
+// planImport adds the entity, repository and mapper of every table to plan,
+// or of the ones in only when it is not empty, and records the tables not
+// named after their entity in .sazerac.yaml
+func planImport(cmd *cobra.Command, plan *internal.Plan, tables []internal.ImportedTable, only []string, dialect internal.Dialect, driver internal.Driver) ([]*internal.FileChange, error) {
+	cfg, err := internal.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []*internal.FileChange
+	mapped := map[string]string{}
+	for _, table := range tables {
+		if len(only) > 0 && !slices.Contains(only, table.Name) {
+			continue
+		}
+
+		fields, err := table.Fields(dialect)
+		if err != nil {
+			fmt.Printf("⚠️  Warning: skipping table %s: %v\n", table.Name, err)
+			continue
+		}
+		fields.ApplyTags(cfg.Tags)
+
+		entity := internal.EntityName(table.Name)
+		if plan.Change(entityPath(entity)) != nil {
+			fmt.Printf("⚠️  Warning: skipping table %s: entity %s was imported already\n", table.Name, entity)
+			continue
+		}
+		if internal.TableName(entity) != table.Name {
+			mapped[entity] = table.Name
+		}
+
+		entityChange, err := planEntity(plan, entity, fields)
+		if err != nil {
+			return nil, err
+		}
+		crud, err := crudOption(cmd, entity)
+		if err != nil {
+			return nil, err
+		}
+		repoChange, infraChange, err := planRepo(plan, entity, fields, repoOptions{Driver: driver, CRUD: crud, Table: table.Name})
+		if err != nil {
+			return nil, err
+		}
+		mapperChange, err := planMapper(plan, entity, fields)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, entityChange, repoChange, infraChange, mapperChange)
+	}
+
+	if len(mapped) > 0 {
+		tables := maps.Clone(cfg.Database.Tables)
+		if tables == nil {
+			tables = map[string]string{}
+		}
+		maps.Copy(tables, mapped)
+
+		content, err := os.ReadFile(internal.ConfigFile)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		content, err = internal.SetConfig(content, tables, "database", "tables")
+		if err != nil {
+			return nil, err
+		}
+		change, err := plan.AddPatch(internal.ConfigFile, content)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
@@ -2,8 +2,9 @@ package commands
 
 import (
 	"fmt"
-	"os"
+	"maps"
 	"path/filepath"
+	"slices"
 
 	"github.com/fsjorgeluis/sazerac/internal"
 	"github.com/fsjorgeluis/sazerac/internal/templates"
@@ -20,9 +21,10 @@ func NewInitCmd() *cobra.Command {
 			module := fmt.Sprintf("github.com/user-name/%s", name)
 
 			paths := map[string]string{
-				"project/main.go.tpl":   filepath.Join(name, "cmd", name, "main.go"),
-				"project/go.mod.tpl":    filepath.Join(name, "go.mod"),
-				"project/readme.dm.tpl": filepath.Join(name, "README.md"),
+				"project/main.go.tpl":      filepath.Join(name, "cmd", name, "main.go"),
+				"project/go.mod.tpl":       filepath.Join(name, "go.mod"),
+				"project/readme.dm.tpl":    filepath.Join(name, "README.md"),
+				"project/sazerac.yaml.tpl": filepath.Join(name, internal.ConfigFile),
 			}
 
 			data := map[string]any{
@@ -30,8 +32,9 @@ func NewInitCmd() *cobra.Command {
 				"Module":      module,
 			}
 
-			for tpl, out := range paths {
-				if err := internal.WriteTemplate(templates.FS, tpl, out, data); err != nil {
+			plan := &internal.Plan{}
+			for _, tpl := range slices.Sorted(maps.Keys(paths)) {
+				if _, err := plan.AddTemplate(templates.FS, tpl, paths[tpl], data); err != nil {
 					return err
 				}
 			}
@@ -48,14 +51,17 @@ func NewInitCmd() *cobra.Command {
 				filepath.Join(name, "cmd", name, "di"),
 			}
 			for _, d := range dirs {
-				if err := os.MkdirAll(filepath.Join(name, d), 0755); err != nil {
-					return err
-				}
+				plan.AddDir(filepath.Join(name, d))
+			}
+
+			if applied, err := commit(cmd, plan); !applied || err != nil {
+				return err
 			}
 
 			fmt.Println("Project ready:", name)
 			return nil
 		},
 	}
+	addOverwriteFlags(cmd)
 	return cmd
 }
@@ -1,8 +1,12 @@
 package commands
 
 import (
+	"bytes"
 	"fmt"
+	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/fsjorgeluis/sazerac/internal"
 	"github.com/fsjorgeluis/sazerac/internal/templates"
@@ -11,84 +15,197 @@ import (
 
 func NewMakeAllCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "all <Entity> <UseCase>",
-		Short: "Generate all resources in a single shot",
-		Args:  cobra.ExactArgs(2),
+		Use:     "all <Entity> <UseCase>",
+		Short:   "Generate all resources in a single shot",
+		Long:    "Generate all resources in a single shot.\n\nOptional field:type arguments define the entity schema (see make entity), and\n--driver the database of the repository (see make repo). --crud generates the\nfull CRUD repository, --tx a use case running in a transaction (see make\nusecase), --kind the kind of handler and --router the router of HTTP handlers\n(see make handler).",
+		Example: "  sazerac make all Product CreateProduct name:string price:float64 --driver postgres",
+		Args:    cobra.MinimumNArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			entity := args[0]
 			usecase := args[1]
 
-			fmt.Println(">> Serving entity 🥃:", entity)
-			entityCmd := NewMakeEntityCmd()
-			if err := entityCmd.RunE(cmd, []string{entity}); err != nil {
+			fields, err := entityFields(entity, args[2:])
+			if err != nil {
 				return err
 			}
+			if fields == nil {
+				cfg, err := internal.LoadConfig()
+				if err != nil {
+					return err
+				}
+				fields = internal.DefaultFields()
+				fields.ApplyTags(cfg.Tags)
+			}
 
-			fmt.Println(">> Serving repo 🥃:", entity)
-			repoCmd := NewMakeRepoCmd()
-			if err := repoCmd.RunE(cmd, []string{entity}); err != nil {
+			driver, err := driverOption(cmd)
+			if err != nil {
 				return err
 			}
-
-			fmt.Println(">> Serving usecase 🥃:", usecase)
-			usecaseCmd := NewMakeUseCaseCmd()
-			if err := usecaseCmd.RunE(cmd, []string{usecase, entity}); err != nil {
+			crud, err := crudOption(cmd, entity)
+			if err != nil {
 				return err
 			}
+			kind, err := kindOption(cmd)
+			if err != nil {
+				return err
+			}
+			tx := boolFlag(cmd, "tx")
 
-			fmt.Println(">> Serving handler 🥃:", usecase)
-			handlerCmd := NewMakeHandlerCmd()
-			if err := handlerCmd.RunE(cmd, []string{usecase, usecase}); err != nil {
+			// Every component is planned first and the plan applied at once,
+			// so a conflict or failure leaves the project untouched
+			plan := &internal.Plan{}
+			entityChange, err := planEntity(plan, entity, fields)
+			if err != nil {
+				return err
+			}
+			repoChange, infraChange, err := planRepo(plan, entity, fields, repoOptions{Driver: driver, CRUD: crud})
+			if err != nil {
+				return err
+			}
+			usecaseChange, err := planUseCase(plan, usecase, entity, fields, useCaseOptions{Tx: tx, CRUD: hasCRUD(entity, plan)})
+			if err != nil {
+				return err
+			}
+			opts := handlerOptions{Kind: kind, Entity: entity, Fields: fields, Filter: hasFilter(entity, plan)}
+			if kind == internal.KindHTTP {
+				if opts.Router, err = routerOption(cmd, plan); err != nil {
+					return err
+				}
+			}
+			handlerChange, err := planHandler(plan, usecase, usecase, opts)
+			if err != nil {
 				return err
 			}
 
-			fmt.Println(">> Serving dependency injection 🥃")
+			var diChange, mainChange *internal.FileChange
 			projectName := internal.GetProjectName()
 			if projectName == "" {
 				fmt.Println("⚠️  Warning: Could not determine project name. Skipping DI generation.")
 			} else {
-				useCasePascal := internal.ToPascalCase(usecase)
-				entityPascal := internal.ToPascalCase(entity)
-				useCaseRoute := internal.ToSnake(useCasePascal)
-
-				out := filepath.Join("cmd", projectName, "di", "di.go")
-				module := internal.GetModuleName()
-
-				data := map[string]any{
-					"UseCase":      useCasePascal,
-					"Entity":       entityPascal,
-					"Module":       module,
-					"ProjectName":  projectName,
-					"UseCaseRoute": useCaseRoute,
-				}
-
-				if err := internal.WriteTemplate(templates.FS, "project/di.go.tpl", out, data); err != nil {
-					fmt.Printf("⚠️  Warning: Failed to generate DI: %v\n", err)
-				} else {
-					fmt.Println("Dependency injection container served 🥃:", out)
+				wirings := []internal.Wiring{{UseCase: usecase, Entity: entity, Driver: driver.Name, Kind: kind, Router: opts.Router.Name, Tx: tx}}
+				if diChange, err = planDI(plan, projectName, wirings); err != nil {
+					return fmt.Errorf("failed to generate DI: %w", err)
 				}
-
-				// Update main.go
-				mainPath := filepath.Join("cmd", projectName, "main.go")
-				mainData := map[string]any{
-					"Module":       module,
-					"ProjectName":  projectName,
-					"UseCase":      useCasePascal,
-					"UseCaseRoute": useCaseRoute,
+				if mainChange, err = planMain(plan, projectName, wirings); err != nil {
+					return fmt.Errorf("failed to update main.go: %w", err)
 				}
+			}
 
-				if err := internal.WriteTemplate(templates.FS, "project/main.go.tpl", mainPath, mainData); err != nil {
-					fmt.Printf("⚠️  Warning: Failed to update main.go: %v\n", err)
-				} else {
-					fmt.Println("Main.go updated 🥃:", mainPath)
-				}
+			applied, err := commit(cmd, plan)
+			if err != nil {
+				return err
+			}
+			if !applied {
+				fmt.Println("Dry run finished, nothing was written 🥃")
+				return nil
 			}
 
+			served("Entity ready:", entityChange)
+			served("Repository served 🥃:", repoChange)
+			served(driver.Type+" implementation served 🥃:", infraChange)
+			served("UseCase served 🥃:", usecaseChange)
+			served("Handler served 🥃:", handlerChange)
+			if diChange != nil {
+				served("Dependency injection container served 🥃:", diChange)
+				served("Main.go updated 🥃:", mainChange)
+			}
+			driverHint(driver)
+			handlerHint(kind)
+			routerHint(opts.Router)
 			fmt.Println("✔️  Everything served successfully 🥃")
 
 			return nil
 		},
 	}
+	addOverwriteFlags(cmd)
+	addDriverFlag(cmd)
+	addCRUDFlag(cmd)
+	addTxFlag(cmd)
+	addKindFlag(cmd)
+	addRouterFlag(cmd)
 
 	return cmd
 }
+
+// planMain adds main.go running the console handler of every wiring to plan,
+// and serving the others with the servers of server.go and grpc_server.go,
+// executing the commands of CLI handlers with cli.go or consuming messages
+// with consumer.go. A main.go that is still the one init generated is
+// replaced, any other is patched so the code written in it is kept.
+func planMain(plan *internal.Plan, projectName string, wirings []internal.Wiring) (*internal.FileChange, error) {
+	out := filepath.Join("cmd", projectName, "main.go")
+	ctx, err := contextOption()
+	if err != nil {
+		return nil, err
+	}
+
+	data := map[string]any{
+		"Module":      internal.GetModuleName(),
+		"ProjectName": projectName,
+		"Context":     ctx,
+	}
+
+	var useCases, serve []string
+	for _, w := range wirings {
+		switch w.Kind {
+		case internal.KindHTTP, internal.KindGRPC, internal.KindCLI, internal.KindConsumer:
+			if !slices.Contains(serve, w.Kind) {
+				serve = append(serve, w.Kind)
+			}
+		default:
+			useCases = append(useCases, w.UseCase)
+		}
+	}
+	for _, kind := range serve {
+		if err := planOnce(plan, serverTemplates[kind], filepath.Join("cmd", projectName, filepath.Base(strings.TrimSuffix(serverTemplates[kind], ".tpl"))), data); err != nil {
+			return nil, err
+		}
+	}
+
+	old, err := os.ReadFile(out)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	scaffold, err := internal.RenderTemplate(templates.FS, "project/main.go.tpl", data)
+	if err != nil {
+		return nil, err
+	}
+
+	content := old
+	if old == nil || bytes.Equal(old, scaffold) {
+		// The main.go of init is replaced, the servers are added to it
+		// like to any other
+		data["UseCases"] = pascalNames(useCases)
+		data["Serve"] = len(serve) > 0
+		if content, err = internal.RenderTemplate(templates.FS, "project/main.go.tpl", data); err != nil {
+			return nil, err
+		}
+		useCases = nil
+	}
+	content, err = internal.MergeMain(content, internal.GetModuleName(), projectName, useCases, ctx, serve)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", out, err)
+	}
+
+	if old == nil {
+		return plan.AddFile(out, content)
+	}
+	return plan.AddPatch(out, content)
+}
+
+// serverTemplates are the templates of the functions main serves or
+// executes the handlers of a kind with
+var serverTemplates = map[string]string{
+	internal.KindHTTP:     "project/server.go.tpl",
+	internal.KindGRPC:     "project/grpc_server.go.tpl",
+	internal.KindCLI:      "project/cli.go.tpl",
+	internal.KindConsumer: "project/consumer.go.tpl",
+}
+
+func pascalNames(names []string) []string {
+	pascal := make([]string, len(names))
+	for i, name := range names {
+		pascal[i] = internal.ToPascalCase(name)
+	}
+	return pascal
+}
@@ -2,8 +2,8 @@ package commands
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
-	"strings"
 
 	"github.com/fsjorgeluis/sazerac/internal"
 	"github.com/fsjorgeluis/sazerac/internal/templates"
@@ -18,36 +18,166 @@ func NewMakeDiCmd() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			usecase := args[0]
 			entity := args[1]
-			useCasePascal := internal.ToPascalCase(usecase)
-			entityPascal := internal.ToPascalCase(entity)
 
 			projectName := internal.GetProjectName()
 			if projectName == "" {
 				return fmt.Errorf("could not determine project name. Make sure you're in the project root directory")
 			}
 
-			out := filepath.Join("cmd", projectName, "di", "di.go")
-
-			// Convert usecase name to route (e.g., CreateUser -> create-user or createuser)
-			useCaseRoute := strings.ToLower(internal.ToSnake(useCasePascal))
+			driver, err := driverOption(cmd)
+			if err != nil {
+				return err
+			}
 
-			data := map[string]any{
-				"UseCase":      useCasePascal,
-				"Entity":       entityPascal,
-				"Module":       internal.GetModuleName(),
-				"ProjectName":  projectName,
-				"UseCaseRoute": useCaseRoute,
+			plan := &internal.Plan{}
+			change, err := planDI(plan, projectName, []internal.Wiring{{UseCase: usecase, Entity: entity, Driver: driver.Name}})
+			if err != nil {
+				return err
 			}
 
-			if err := internal.WriteTemplate(templates.FS, "project/di.go.tpl", out, data); err != nil {
+			if applied, err := commit(cmd, plan); !applied || err != nil {
 				return err
 			}
 
-			fmt.Println("Dependency injection container served 🥃:", out)
+			served("Dependency injection container served 🥃:", change)
 			return nil
 		},
 	}
+	addOverwriteFlags(cmd)
+	addDriverFlag(cmd)
 
 	return cmd
 }
 
+// planDI adds the DI container wiring every use case to plan. An existing
+// container is extended with the wirings it lacks instead of being replaced,
+// so features generated before keep their wiring.
+func planDI(plan *internal.Plan, projectName string, wirings []internal.Wiring) (*internal.FileChange, error) {
+	out := filepath.Join("cmd", projectName, "di", "di.go")
+
+	if err := wireUnitsOfWork(plan, wirings); err != nil {
+		return nil, err
+	}
+	if err := wireHandlerKinds(wirings); err != nil {
+		return nil, err
+	}
+	if err := wireRouters(wirings); err != nil {
+		return nil, err
+	}
+
+	old, err := os.ReadFile(out)
+	if err == nil {
+		merged, err := internal.MergeDI(old, internal.GetModuleName(), wirings)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", out, err)
+		}
+		return plan.AddPatch(out, merged)
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	// A new container only opens the connection, the wiring is added to it
+	// like to an existing one
+	driver, err := internal.LookupDriver(wirings[0].Driver)
+	if err != nil {
+		return nil, err
+	}
+	scaffold, err := internal.RenderTemplate(templates.FS, "project/di.go.tpl", map[string]any{"Driver": driver})
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := internal.MergeDI(scaffold, internal.GetModuleName(), wirings)
+	if err != nil {
+		return nil, err
+	}
+
+	return plan.AddFile(out, content)
+}
+
+// wireUnitsOfWork marks the wirings whose use case was generated with --tx,
+// unless they are marked already, and plans the unit of work of their
+// driver if it does not exist yet
+func wireUnitsOfWork(plan *internal.Plan, wirings []internal.Wiring) error {
+	for i, w := range wirings {
+		if !w.Tx {
+			path := useCasePath(w.UseCase)
+			tx, err := internal.TakesUnitOfWork(path, w.UseCase)
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			if !tx {
+				continue
+			}
+		}
+
+		driver, err := internal.LookupDriver(w.Driver)
+		if err != nil {
+			return err
+		}
+		data := map[string]any{"Module": internal.GetModuleName(), "Driver": driver}
+		if err := planUnitOfWork(plan, driver, data); err != nil {
+			return err
+		}
+		wirings[i].Tx = true
+	}
+	return nil
+}
+
+// wireHandlerKinds reads the kind of the wirings without one from their
+// handler, console when it does not exist yet
+func wireHandlerKinds(wirings []internal.Wiring) error {
+	for i, w := range wirings {
+		if w.Kind != "" {
+			continue
+		}
+		kind, err := existingHandlerKind(w.UseCase)
+		if err != nil {
+			return err
+		}
+		if kind == "" {
+			kind = internal.KindConsole
+		}
+		wirings[i].Kind = kind
+	}
+	return nil
+}
+
+// existingHandlerKind returns the kind of the handler of useCase, or "" when
+// it does not exist yet
+func existingHandlerKind(useCase string) (string, error) {
+	for _, kind := range packagedKinds {
+		if _, err := os.Stat(kindHandlerPath(kind, useCase)); err == nil {
+			return kind, nil
+		}
+	}
+	path := handlerPath(useCase)
+	kind, err := internal.HandlerKind(path, internal.ToPascalCase(useCase)+"Handler")
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+	return kind, nil
+}
+
+// wireRouters sets the router of the HTTP wirings without one to the router
+// configured for the project
+func wireRouters(wirings []internal.Wiring) error {
+	for i, w := range wirings {
+		if w.Kind != internal.KindHTTP || w.Router != "" {
+			continue
+		}
+		cfg, err := internal.LoadConfig()
+		if err != nil {
+			return err
+		}
+		wirings[i].Router = cfg.HTTP.Router
+	}
+	return nil
+}
@@ -1,7 +1,7 @@
 package commands
 
 import (
-	"fmt"
+	"os"
 	"path/filepath"
 
 	"github.com/fsjorgeluis/sazerac/internal"
@@ -13,25 +13,92 @@ func NewMakeEntityCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "entity <Name>",
 		Short: "Generates a domain entity",
-		Args:  cobra.ExactArgs(1),
+		Long: `Generates a domain entity.
+
+Fields are given as name:type, e.g. name:string price:float64 tags:[]string
+created_at:time.Time. Pointers, slices, maps and imported types are supported;
+use the full import path for packages other than the well known ones
+(github.com/acme/money.Amount). A trailing ? marks the field as optional
+(nickname?:string). An ID string field is added when none is declared, and
+ID and Name are used when no field is given at all.
+
+Struct tags are generated from the tags section of .sazerac.yaml (json and db
+in snake_case by default). Extra key=value segments override a tag for one
+field: email:string:json=email_address,omitempty:db=mail.`,
+		Example: "  sazerac make entity Product name:string price:float64 tags:[]string created_at:time.Time",
+		Args:    cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			name := args[0]
-			namePascal := internal.ToPascalCase(name)
 
-			out := filepath.Join("internal/domain/entities", internal.ToSnake(name)+".go")
+			fields, err := entityFields(name, args[1:])
+			if err != nil {
+				return err
+			}
+			if fields == nil {
+				cfg, err := internal.LoadConfig()
+				if err != nil {
+					return err
+				}
+				fields = internal.DefaultFields()
+				fields.ApplyTags(cfg.Tags)
+			}
 
-			data := map[string]any{
-				"Name": namePascal,
+			plan := &internal.Plan{}
+			change, err := planEntity(plan, name, fields)
+			if err != nil {
+				return err
 			}
 
-			if err := internal.WriteTemplate(templates.FS, "entity/entity.go.tpl", out, data); err != nil {
+			if applied, err := commit(cmd, plan); !applied || err != nil {
 				return err
 			}
 
-			fmt.Println("Entity ready:", out)
+			served("Entity ready:", change)
 			return nil
 		},
 	}
+	addOverwriteFlags(cmd)
 
 	return cmd
 }
+
+// planEntity adds the entity struct to plan
+func planEntity(plan *internal.Plan, name string, fields internal.Fields) (*internal.FileChange, error) {
+	data := map[string]any{
+		"Name":   internal.ToPascalCase(name),
+		"Fields": fields,
+	}
+
+	return plan.AddTemplate(templates.FS, "entity/entity.go.tpl", entityPath(name), data)
+}
+
+// entityPath returns where the entity struct is generated
+func entityPath(entity string) string {
+	return filepath.Join("internal/domain/entities", internal.ToSnake(entity)+".go")
+}
+
+// entityFields returns the fields given on the command line or, when there
+// are none, the ones declared by the existing entity struct, with the struct
+// tags configured for the project. It returns nil when the schema is unknown.
+func entityFields(entity string, defs []string) (internal.Fields, error) {
+	cfg, err := internal.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	var fields internal.Fields
+	if len(defs) > 0 {
+		fields, err = internal.ParseFields(defs)
+	} else {
+		fields, err = internal.LoadEntityFields(entityPath(entity), internal.ToPascalCase(entity))
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	fields.ApplyTags(cfg.Tags)
+	return fields, nil
+}
@@ -2,7 +2,10 @@ package commands
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/fsjorgeluis/sazerac/internal"
 	"github.com/fsjorgeluis/sazerac/internal/templates"
@@ -13,33 +16,436 @@ func NewMakeHandlerCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "handler <Name> <UseCase>",
 		Short: "Generate the handler for a use case",
-		Args:  cobra.ExactArgs(2),
+		Long: `Generate the handler for a use case.
+
+--kind picks what the handler is:
+
+  console  Run() executes the use case once and prints the result (default)
+  http     a handler registered on the router of the project
+  grpc     a gRPC service with a .proto definition
+  cli      a cobra subcommand of the project CLI
+  consumer a subscriber to the messages of a topic
+
+HTTP handlers decode the JSON body into the use case input, execute it and
+write the entity as JSON. The route comes from the use case name: CreateUser
+is POST /users, GetUser GET /users/{id}, ListUsers GET /users, UpdateUser PUT
+/users/{id} and DeleteUser DELETE /users/{id}; --route sets another one.
+{id} is stored in the ID field of the input, when it has one, and the
+query parameters of GET routes on the collection in its Filter field, with
+Parse<Entity>Filter (see make repo --crud). Errors wrapping
+domain.ErrInvalidInput (validators return them) are 400, domain.ErrNotFound
+404 and domain.ErrAlreadyExists 409.
+
+--router picks the router HTTP handlers are generated for: stdlib (a Go 1.22
+ServeMux, the default), chi, gin or echo. It decides the signature of the
+handlers, how they register their route and read path parameters, and the
+router the DI container creates. The first HTTP handler stores the router in
+http.router of .sazerac.yaml, and the later ones must use the same.
+
+gRPC handlers serve the <UseCase>Service declared in
+proto/<entity>/v1/<entity>.proto, which gets the entity message, a request
+message with the fields of the use case input and the service. The adapter
+in internal/handlers/grpc converts the request to the input and the entity
+to its message, and maps domain errors to status codes (InvalidArgument,
+NotFound, AlreadyExists). Run protoc or buf to generate the Go code of the
+.proto files into gen.
+
+CLI handlers add a command named after the use case (CreateUser is
+create-user) with a flag per field of the use case input (--name, --user-id,
+time.Time fields in RFC 3339). The command executes the use case and prints
+the result as a table, or as JSON with --output json.
+
+Consumer handlers subscribe to a topic (the use case name in kebab-case, or
+--topic) of a messaging.Broker, decode the JSON payload of every message
+into the use case input and execute it. Failures are retried with backoff,
+except invalid payloads and domain.ErrInvalidInput, and messages that keep
+failing are published on <topic>.dead-letter. The first consumer handler
+also generates the messaging port and an in-memory broker.
+
+make all and make di register HTTP handlers on the Router of the DI
+container, gRPC handlers on its GRPCServer, CLI handlers on its CLI root
+command and consumer handlers on its Broker, and main.go serves or executes
+them.`,
+		Example: "  sazerac make handler GetUser GetUser --kind http\n  sazerac make handler Deactivate DeactivateUser --kind http --route \"POST /users/{id}/deactivate\"\n  sazerac make handler GetUser GetUser --kind http --router chi\n  sazerac make handler CreateUser CreateUser --kind grpc\n  sazerac make handler ListUsers ListUsers --kind cli\n  sazerac make handler SendWelcome SendWelcomeEmail --kind consumer --topic users.created",
+		Args:    cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			name := args[0]
 			usecase := args[1]
-			namePascal := internal.ToPascalCase(name)
-			useCasePascal := internal.ToPascalCase(usecase)
-
-			out := filepath.Join(
-				"internal/handlers",
-				internal.ToSnake(name)+"_handler.go",
-			)
-
-			data := map[string]any{
-				"Name":    namePascal,
-				"UseCase": useCasePascal,
-				"Module":  internal.GetModuleName(),
+
+			kind, err := kindOption(cmd)
+			if err != nil {
+				return err
 			}
+			route, _ := cmd.Flags().GetString("route")
+			topic, _ := cmd.Flags().GetString("topic")
 
-			err := internal.WriteTemplate(templates.FS, "handler/handler.go.tpl", out, data)
+			plan := &internal.Plan{}
+			opts := handlerOptions{Kind: kind, Route: route, Topic: topic}
+			if kind == internal.KindHTTP {
+				if opts.Router, err = routerOption(cmd, plan); err != nil {
+					return err
+				}
+			}
+			change, err := planHandler(plan, name, usecase, opts)
 			if err != nil {
 				return err
 			}
 
-			fmt.Println("Handler served 🥃:", out)
+			if applied, err := commit(cmd, plan); !applied || err != nil {
+				return err
+			}
+
+			served("Handler served 🥃:", change)
+			handlerHint(kind)
+			routerHint(opts.Router)
 			return nil
 		},
 	}
+	addOverwriteFlags(cmd)
+	addKindFlag(cmd)
+	addRouterFlag(cmd)
+	cmd.Flags().String("route", "", `Route of an http handler, e.g. "GET /users/{id}"`)
+	cmd.Flags().String("topic", "", "Topic of a consumer handler, the use case name in kebab-case by default")
 
 	return cmd
 }
+
+// handlerOptions decides how a handler is generated
+type handlerOptions struct {
+	Kind   string          // console by default
+	Entity string          // entity of the use case, read from the use case when empty
+	Fields internal.Fields // fields of the entity, read from the entity when nil
+	Route  string          // ServeMux pattern of http handlers, from the use case name when empty
+	Router internal.Router // router of http handlers, the ServeMux when zero
+	Topic  string          // topic of consumer handlers, from the use case name when empty
+	Filter bool            // the entity has a Parse<Entity>Filter, looked up on disk when false
+}
+
+// addKindFlag registers --kind, which picks the kind of handler
+func addKindFlag(cmd *cobra.Command) {
+	cmd.Flags().String("kind", internal.KindConsole, fmt.Sprintf("Handler kind (%s)", strings.Join(internal.HandlerKinds, ", ")))
+}
+
+// kindOption returns the handler kind chosen with --kind
+func kindOption(cmd *cobra.Command) (string, error) {
+	kind := ""
+	if flag := cmd.Flag("kind"); flag != nil {
+		kind = flag.Value.String()
+	}
+	return internal.LookupHandlerKind(kind)
+}
+
+// handlerPath returns where the handler called name is generated
+func handlerPath(name string) string {
+	return filepath.Join("internal/handlers", internal.ToSnake(name)+"_handler.go")
+}
+
+// packagedKinds are the handler kinds generated in a package of their own,
+// internal/handlers/<kind>
+var packagedKinds = []string{internal.KindGRPC, internal.KindCLI, internal.KindConsumer}
+
+// kindHandlerPath returns where the handler of kind called name is generated
+func kindHandlerPath(kind, name string) string {
+	if slices.Contains(packagedKinds, kind) {
+		return filepath.Join("internal/handlers", kind, internal.ToSnake(name)+"_handler.go")
+	}
+	return handlerPath(name)
+}
+
+// handlerHint tells what else the handlers of kind need
+func handlerHint(kind string) {
+	switch kind {
+	case internal.KindGRPC:
+		fmt.Println("ℹ️  gRPC handlers need the code protoc generates from the .proto files: protoc -I proto --go_out=gen --go_opt=paths=source_relative --go-grpc_out=gen --go-grpc_opt=paths=source_relative proto/*/v1/*.proto (or buf generate), and go get google.golang.org/grpc google.golang.org/protobuf")
+	case internal.KindCLI:
+		fmt.Println("ℹ️  CLI handlers need cobra: go get github.com/spf13/cobra")
+	case internal.KindConsumer:
+		fmt.Println("ℹ️  Consumer handlers run on the in-memory broker of infrastructure/messaging/inmemory, swap it in the DI container for another messaging.Broker to use a real one")
+	}
+}
+
+// routerHint tells how to get the module of router, when it is not in the
+// standard library
+func routerHint(router internal.Router) {
+	if router.Module != "" {
+		fmt.Printf("ℹ️  HTTP handlers for %s need its module: go get %s\n", router.Name, router.Module)
+	}
+}
+
+// planHandler adds the handler of a use case to plan
+func planHandler(plan *internal.Plan, name, usecase string, opts handlerOptions) (*internal.FileChange, error) {
+	ctx, err := contextOption()
+	if err != nil {
+		return nil, err
+	}
+
+	data := map[string]any{
+		"Name":    internal.ToPascalCase(name),
+		"UseCase": internal.ToPascalCase(usecase),
+		"Module":  internal.GetModuleName(),
+		"Context": ctx,
+		// List use cases return a slice of entities
+		"List": internal.UseCaseOperation(usecase) == internal.OpList,
+	}
+
+	switch opts.Kind {
+	case internal.KindHTTP:
+		if err := httpHandlerData(plan, usecase, opts, data); err != nil {
+			return nil, err
+		}
+		return plan.AddTemplate(templates.FS, "handler/http.go.tpl", handlerPath(name), data)
+	case internal.KindGRPC:
+		if err := grpcHandlerData(plan, usecase, opts, data); err != nil {
+			return nil, err
+		}
+		return plan.AddTemplate(templates.FS, "handler/grpc.go.tpl", kindHandlerPath(opts.Kind, name), data)
+	case internal.KindCLI:
+		if err := cliHandlerData(plan, usecase, data); err != nil {
+			return nil, err
+		}
+		return plan.AddTemplate(templates.FS, "handler/cli.go.tpl", kindHandlerPath(opts.Kind, name), data)
+	case internal.KindConsumer:
+		if err := consumerHandlerData(plan, usecase, opts, data); err != nil {
+			return nil, err
+		}
+		return plan.AddTemplate(templates.FS, "handler/consumer.go.tpl", kindHandlerPath(opts.Kind, name), data)
+	}
+
+	return plan.AddTemplate(templates.FS, "handler/handler.go.tpl", handlerPath(name), data)
+}
+
+// useCaseInput returns the fields of the input of usecase, from its file or,
+// when it is not written yet, from plan. They are nil for unknown use cases.
+func useCaseInput(plan *internal.Plan, usecase string) (internal.Fields, error) {
+	path := useCasePath(usecase)
+	name := internal.ToPascalCase(usecase) + "Input"
+	input, err := internal.LoadEntityFields(path, name)
+	if os.IsNotExist(err) {
+		if change := plan.Change(path); change != nil {
+			return internal.ParseEntityFields(change.Content, name)
+		}
+		return nil, nil
+	}
+	return input, err
+}
+
+// handlerEntity returns the entity of the use case a handler executes
+func handlerEntity(usecase string, opts handlerOptions) (string, error) {
+	if opts.Entity != "" {
+		return internal.ToPascalCase(opts.Entity), nil
+	}
+	entity, err := internal.UseCaseEntity(useCasePath(usecase), usecase)
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	if entity == "" {
+		// Unknown use case, the entity is what follows the verb: GetUser
+		words := strings.Split(internal.ToSnake(usecase), "_")
+		entity = internal.ToPascalCase(words[len(words)-1])
+	}
+	return entity, nil
+}
+
+// httpHandlerData adds the route and router of an http handler, and the ID
+// field of the use case input receiving {id}, to data. The helpers writing
+// responses are planned with the first http handler.
+func httpHandlerData(plan *internal.Plan, usecase string, opts handlerOptions, data map[string]any) error {
+	entity, err := handlerEntity(usecase, opts)
+	if err != nil {
+		return err
+	}
+
+	route := internal.RESTRoute(usecase, entity)
+	if opts.Route != "" {
+		var err error
+		if route, err = internal.ParseRoute(opts.Route); err != nil {
+			return err
+		}
+	}
+	data["Route"] = route
+
+	router := opts.Router
+	if router.Name == "" {
+		router, _ = internal.LookupRouter("")
+	}
+	data["Router"] = router
+
+	input, err := useCaseInput(plan, usecase)
+	if err != nil {
+		return err
+	}
+	if id := input.Get("ID"); id != nil && route.HasID() && (id.Type == "string" || id.Ordered()) {
+		data["ID"] = id
+		if parse := id.Parse(router.Value("id")); parse != "" {
+			data["IDParse"] = parse
+			data["IDImports"] = []string{"strconv"}
+			if id.Type == "time.Time" {
+				data["IDImports"] = []string{"time"}
+			}
+		}
+	}
+
+	// List routes read the filter of the use cases taking one
+	takesFilter := input.Get("Filter") != nil || (input == nil && internal.RESTRoute(usecase, entity).List())
+	if route.List() && takesFilter && (opts.Filter || hasFilter(entity, plan)) {
+		data["Filter"] = internal.ToPascalCase(entity)
+	}
+
+	data["Errors"] = internal.DomainErrors
+	if err := planDomainErrors(plan, data); err != nil {
+		return err
+	}
+	return planOnce(plan, "handler/respond.go.tpl", filepath.Join("internal/handlers", "respond.go"), data)
+}
+
+// grpcHandlerData adds the entity, the use case input and the .proto file of
+// a gRPC handler to data. The entity message, the request and the service
+// are added to the .proto file of the entity, and the helpers converting
+// entities and errors are planned with the first handler of the entity.
+func grpcHandlerData(plan *internal.Plan, usecase string, opts handlerOptions, data map[string]any) error {
+	entity, err := handlerEntity(usecase, opts)
+	if err != nil {
+		return err
+	}
+	fields := opts.Fields
+	if fields == nil {
+		if fields, err = entityFields(entity, nil); err != nil {
+			return err
+		}
+		if fields == nil {
+			fields = internal.DefaultFields()
+		}
+	}
+	input, err := useCaseInput(plan, usecase)
+	if err != nil {
+		return err
+	}
+
+	proto := internal.EntityProto(internal.GetModuleName(), entity)
+	data["Entity"] = entity
+	data["Fields"] = fields
+	data["Input"] = input
+	data["Proto"] = proto
+	data["ToProto"] = strings.ToLower(entity[:1]) + entity[1:] + "ToProto"
+	data["Timestamp"] = slices.ContainsFunc(fields, func(f internal.Field) bool {
+		return strings.Contains(f.SetProto("v", "m"), "timestamppb.")
+	})
+
+	// A use case without input fields yet gets a request carrying the
+	// entity, or its ID for Get and Delete, to fill the input from
+	useCase := internal.ToPascalCase(usecase)
+	request := input
+	if len(request) == 0 {
+		request = fields
+		switch internal.UseCaseOperation(usecase) {
+		case internal.OpGet, internal.OpDelete:
+			if id := fields.Get("ID"); id != nil {
+				request = internal.Fields{*id}
+			}
+		}
+	}
+	decls := []string{internal.ProtoMessage(entity, fields), internal.ProtoMessage(useCase+"Request", request)}
+	response := entity
+	if data["List"] == true {
+		// The entities of List use cases are the items of a response
+		response = useCase + "Response"
+		decls = append(decls, fmt.Sprintf("message %s {\n  repeated %s items = 1;\n}\n", response, entity))
+	}
+	data["Response"] = response
+	imports := append(fields.ProtoImports(), request.ProtoImports()...)
+	decls = append(decls, internal.ProtoService(useCase, useCase+"Request", response))
+	if err := planProto(plan, proto, data, imports, decls...); err != nil {
+		return err
+	}
+
+	data["Errors"] = internal.DomainErrors
+	if err := planDomainErrors(plan, data); err != nil {
+		return err
+	}
+	if err := planOnce(plan, "handler/grpc_status.go.tpl", filepath.Join("internal/handlers/grpc", "status.go"), data); err != nil {
+		return err
+	}
+	return planOnce(plan, "handler/grpc_message.go.tpl", filepath.Join("internal/handlers/grpc", internal.ToSnake(entity)+"_message.go"), data)
+}
+
+// cliHandlerData adds the command of a CLI handler and the use case input
+// its flags fill to data. The root command and the helpers printing results
+// are planned with the first CLI handler.
+func cliHandlerData(plan *internal.Plan, usecase string, data map[string]any) error {
+	input, err := useCaseInput(plan, usecase)
+	if err != nil {
+		return err
+	}
+
+	command := internal.CommandName(usecase)
+	data["Command"] = command
+	data["Short"] = strings.ToUpper(command[:1]) + strings.ReplaceAll(command[1:], "-", " ")
+	data["Input"] = input
+	data["ProjectName"] = internal.GetProjectName()
+
+	if err := planOnce(plan, "handler/cli_root.go.tpl", filepath.Join("internal/handlers/cli", "root.go"), data); err != nil {
+		return err
+	}
+	return planOnce(plan, "handler/cli_output.go.tpl", filepath.Join("internal/handlers/cli", "output.go"), data)
+}
+
+// consumerHandlerData adds the topic of a consumer handler to data. The
+// messaging port, the in-memory broker and the retry helpers are planned with
+// the first consumer handler.
+func consumerHandlerData(plan *internal.Plan, usecase string, opts handlerOptions, data map[string]any) error {
+	data["Topic"] = internal.CommandName(usecase)
+	if opts.Topic != "" {
+		data["Topic"] = opts.Topic
+	}
+
+	data["Errors"] = internal.DomainErrors
+	if err := planDomainErrors(plan, data); err != nil {
+		return err
+	}
+	for tpl, path := range map[string]string{
+		"messaging/messaging.go.tpl":    filepath.Join("internal/messaging", "messaging.go"),
+		"messaging/memory.go.tpl":       filepath.Join("infrastructure/messaging/inmemory", "broker.go"),
+		"handler/consumer_retry.go.tpl": filepath.Join("internal/handlers/consumer", "retry.go"),
+	} {
+		if err := planOnce(plan, tpl, path, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// planProto adds the declarations the .proto file lacks to it, creating it
+// when missing. Several handlers of a plan add to the same file.
+func planProto(plan *internal.Plan, proto internal.ProtoPackage, data map[string]any, imports []string, decls ...string) error {
+	if change := plan.Change(proto.Path); change != nil {
+		merged, err := internal.MergeProto(change.Content, imports, decls...)
+		if err != nil {
+			return fmt.Errorf("%s: %w", proto.Path, err)
+		}
+		change.Content = merged
+		return nil
+	}
+
+	old, err := os.ReadFile(proto.Path)
+	if os.IsNotExist(err) {
+		if old, err = internal.RenderTemplate(templates.FS, "handler/grpc.proto.tpl", data); err != nil {
+			return err
+		}
+		merged, err := internal.MergeProto(old, imports, decls...)
+		if err != nil {
+			return err
+		}
+		_, err = plan.AddFile(proto.Path, merged)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	merged, err := internal.MergeProto(old, imports, decls...)
+	if err != nil {
+		return fmt.Errorf("%s: %w", proto.Path, err)
+	}
+	_, err = plan.AddPatch(proto.Path, merged)
+	return err
+}
@@ -1,7 +1,6 @@
 package commands
 
 import (
-	"fmt"
 	"path/filepath"
 
 	"github.com/fsjorgeluis/sazerac/internal"
@@ -13,30 +12,47 @@ func NewMakeMapperCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "mapper <Entity>",
 		Short: "Generate a entity mapper <-> DTO",
-		Args:  cobra.ExactArgs(1),
+		Long:  "Generate a entity mapper <-> DTO.\n\nThe entity fields are read from its struct, or from field:type arguments (see make entity).",
+		Args:  cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			entity := args[0]
-			entityPascal := internal.ToPascalCase(entity)
 
-			out := filepath.Join(
-				"internal/domain/mappers",
-				internal.ToSnake(entity)+"_mapper.go",
-			)
-
-			data := map[string]any{
-				"Entity": entityPascal,
-				"Module": internal.GetModuleName(),
+			fields, err := entityFields(entity, args[1:])
+			if err != nil {
+				return err
 			}
 
-			err := internal.WriteTemplate(templates.FS, "mapper/mapper.go.tpl", out, data)
+			plan := &internal.Plan{}
+			change, err := planMapper(plan, entity, fields)
 			if err != nil {
 				return err
 			}
 
-			fmt.Println("Mapper served 🥃:", out)
+			if applied, err := commit(cmd, plan); !applied || err != nil {
+				return err
+			}
+
+			served("Mapper served 🥃:", change)
 			return nil
 		},
 	}
+	addOverwriteFlags(cmd)
 
 	return cmd
 }
+
+// planMapper adds the entity <-> DTO mapper to plan
+func planMapper(plan *internal.Plan, entity string, fields internal.Fields) (*internal.FileChange, error) {
+	out := filepath.Join(
+		"internal/domain/mappers",
+		internal.ToSnake(entity)+"_mapper.go",
+	)
+
+	data := map[string]any{
+		"Entity": internal.ToPascalCase(entity),
+		"Module": internal.GetModuleName(),
+		"Fields": fields,
+	}
+
+	return plan.AddTemplate(templates.FS, "mapper/mapper.go.tpl", out, data)
+}
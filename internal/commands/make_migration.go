@@ -0,0 +1,186 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsjorgeluis/sazerac/internal"
+	"github.com/fsjorgeluis/sazerac/internal/templates"
+	"github.com/spf13/cobra"
+)
+
+// now is the clock migration versions are taken from
+var now = time.Now
+
+func NewMakeMigrationCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migration <Entity>",
+		Short: "Generate the SQL migration creating the table of an entity",
+		Long: `Generate the SQL migration creating the table of an entity.
+
+The up and down files are written to migrations/, named after the current UTC
+time (migrations/20261018120000_create_users.up.sql), in the SQL dialect of
+--driver (mysql, postgres or sqlite, database.driver in .sazerac.yaml by
+default). The entity fields are read from its struct, or from field:type
+arguments (see make entity); ID is the primary key.
+
+The first migration also generates migrations/migrations.go, embedding the
+SQL files in the binary, and the runner in infrastructure/database/migrate.
+main.go is patched to apply the pending migrations at startup, on the
+connection of the DI container, when it creates one.
+
+--diff compares the entity struct with the table the earlier migrations
+leave, and writes the ALTER TABLE statements adding, dropping and changing
+columns (migrations/20261019090000_alter_users.up.sql) and the ones reverting
+them. SQLite cannot change columns, so the table is rebuilt instead.`,
+		Example: "  sazerac make migration User --driver postgres\n  sazerac make migration User --diff",
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			entity := args[0]
+
+			fields, err := entityFields(entity, args[1:])
+			if err != nil {
+				return err
+			}
+			if fields == nil {
+				return fmt.Errorf("the fields of %s are unknown, generate the entity first or pass field:type arguments", entity)
+			}
+
+			driver, err := driverOption(cmd)
+			if err != nil {
+				return err
+			}
+
+			plan := &internal.Plan{}
+			up, down, err := planMigration(plan, entity, fields, driver, boolFlag(cmd, "diff"))
+			if err != nil {
+				return err
+			}
+			if up == nil {
+				fmt.Printf("✔️  The table of %s is up to date, no migration needed 🥃\n", entity)
+				return nil
+			}
+			mainChange, err := planMigrationMain(plan, driver)
+			if err != nil {
+				fmt.Printf("⚠️  Warning: main.go does not apply the migrations: %v\n", err)
+			}
+
+			if applied, err := commit(cmd, plan); !applied || err != nil {
+				return err
+			}
+
+			served("Migration served 🥃:", up)
+			served("Migration served 🥃:", down)
+			if mainChange != nil {
+				served("Main.go updated 🥃:", mainChange)
+			}
+			return nil
+		},
+	}
+	addOverwriteFlags(cmd)
+	addDriverFlag(cmd)
+	cmd.Flags().Bool("diff", false, "Alter the table the earlier migrations created to match the entity")
+
+	return cmd
+}
+
+// planMigration adds the migration creating the table of entity to plan,
+// with the runner applying it when the project has none yet. With diff, and
+// a table created by earlier migrations, the migration alters that table
+// instead; no migration is added when it is up to date.
+func planMigration(plan *internal.Plan, entity string, fields internal.Fields, driver internal.Driver, diff bool) (*internal.FileChange, *internal.FileChange, error) {
+	if driver.Dialect == "" {
+		return nil, nil, fmt.Errorf("the %s driver has no SQL migrations, choose one with --driver", driver.Name)
+	}
+	table, err := sqlTable(driver.Dialect, entity, fields)
+	if err != nil {
+		return nil, nil, err
+	}
+	if table == nil {
+		return nil, nil, fmt.Errorf("%s has no ID field to use as primary key", entity)
+	}
+
+	data := map[string]any{
+		"Entity":  internal.ToPascalCase(entity),
+		"Module":  internal.GetModuleName(),
+		"Table":   table,
+		"Dialect": driver.Dialect,
+	}
+
+	name, tpl := "create_"+table.Name, "migration/create"
+	if diff {
+		tables, err := internal.MigratedTables(internal.MigrationsDir)
+		if err != nil {
+			return nil, nil, err
+		}
+		if columns, ok := tables[table.Name]; ok {
+			up, down := table.AlterTable(columns)
+			if len(up) == 0 {
+				return nil, nil, nil
+			}
+			data["Up"], data["Down"] = up, down
+			name, tpl = "alter_"+table.Name, "migration/alter"
+		}
+	}
+	if tpl == "migration/create" {
+		if existing, err := internal.FindMigration(name); err != nil || existing != "" {
+			if err == nil {
+				err = fmt.Errorf("migration %s exists already: %s, use --diff to alter the table", name, existing)
+			}
+			return nil, nil, err
+		}
+	}
+
+	upPath, downPath := internal.MigrationPaths(name, now())
+	up, err := plan.AddTemplate(templates.FS, tpl+".up.sql.tpl", upPath, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	down, err := plan.AddTemplate(templates.FS, tpl+".down.sql.tpl", downPath, data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := planOnce(plan, "migration/migrations.go.tpl", filepath.Join(internal.MigrationsDir, "migrations.go"), data); err != nil {
+		return nil, nil, err
+	}
+	if err := planOnce(plan, "migration/migrate.go.tpl", filepath.Join("infrastructure", "database", "migrate", "migrate.go"), data); err != nil {
+		return nil, nil, err
+	}
+
+	return up, down, nil
+}
+
+// planMigrationMain patches main.go to apply the migrations on the
+// connection the DI container opens for driver
+func planMigrationMain(plan *internal.Plan, driver internal.Driver) (*internal.FileChange, error) {
+	projectName := internal.GetProjectName()
+	if projectName == "" {
+		return nil, fmt.Errorf("could not determine project name")
+	}
+
+	di, err := os.ReadFile(filepath.Join("cmd", projectName, "di", "di.go"))
+	if err != nil {
+		return nil, err
+	}
+	conn, err := internal.ConnectionField(di, driver.Conn)
+	if err != nil {
+		return nil, err
+	}
+	if conn == "" {
+		return nil, fmt.Errorf("the DI container has no %s connection yet, run make all or make di with --driver %s", driver.Name, driver.Name)
+	}
+
+	out := filepath.Join("cmd", projectName, "main.go")
+	old, err := os.ReadFile(out)
+	if err != nil {
+		return nil, err
+	}
+	content, err := internal.MergeMigrations(old, internal.GetModuleName(), conn)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", out, err)
+	}
+	return plan.AddPatch(out, content)
+}
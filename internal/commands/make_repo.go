@@ -2,7 +2,10 @@ package commands
 
 import (
 	"fmt"
+	"maps"
+	"os"
 	"path/filepath"
+	"slices"
 
 	"github.com/fsjorgeluis/sazerac/internal"
 	"github.com/fsjorgeluis/sazerac/internal/templates"
@@ -12,44 +15,282 @@ import (
 func NewMakeRepoCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "repo <Entity>",
-		Short: "Generate a dummy repository and its MySQL implementation",
-		Args:  cobra.ExactArgs(1),
+		Short: "Generate a repository and its database implementation",
+		Long: `Generate a repository and its database implementation.
+
+The entity fields are read from its struct, or from field:type arguments (see
+make entity).
+
+--driver picks the database: memory, mysql, postgres or sqlite. memory keeps
+the entities in a map guarded by a sync.RWMutex and needs no database at
+all, which also makes it a fake for use case tests. The SQL implementations
+use database/sql: Save is an upsert (ON DUPLICATE KEY UPDATE for mysql, ON
+CONFLICT ... RETURNING for postgres and sqlite) and FindByID scans the row
+into the entity. sqlite stores everything in a local file (SQLITE_PATH,
+app.db by default). Every implementation returns domain.ErrNotFound for
+missing entities. When the fields are unknown the SQL implementations are
+TODO stubs. The default is database.driver in .sazerac.yaml.
+
+--crud adds Create, Update, Delete, List and Count. List and Count take an
+<Entity>Filter (field conditions, sort, limit/offset and an after cursor),
+which handlers can read from query parameters with Parse<Entity>Filter.`,
+		Example: "  sazerac make repo User --driver postgres",
+		Args:    cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			entity := args[0]
-			entityPascal := internal.ToPascalCase(entity)
-
-			// Repository interface
-			outInterface := filepath.Join(
-				"internal/repository",
-				internal.ToSnake(entity)+"_repository.go",
-			)
-
-			// Infrastructure implementation
-			outInfra := filepath.Join(
-				"infrastructure/database/mysql",
-				internal.ToSnake(entity)+"_mysql.go",
-			)
-
-			data := map[string]any{
-				"Entity": entityPascal,
-				"Module": internal.GetModuleName(),
+
+			fields, err := entityFields(entity, args[1:])
+			if err != nil {
+				return err
 			}
 
-			err := internal.WriteTemplate(templates.FS, "repository/repo_interface.go.tpl", outInterface, data)
+			driver, err := driverOption(cmd)
 			if err != nil {
 				return err
 			}
 
-			err = internal.WriteTemplate(templates.FS, "repository/repo_mysql.go.tpl", outInfra, data)
+			crud, err := crudOption(cmd, entity)
 			if err != nil {
 				return err
 			}
 
-			fmt.Println("Repository served 🥃:", outInterface)
-			fmt.Println("MySQL dummy implementation served 🥃:", outInfra)
+			plan := &internal.Plan{}
+			interfaceChange, infraChange, err := planRepo(plan, entity, fields, repoOptions{Driver: driver, CRUD: crud})
+			if err != nil {
+				return err
+			}
+
+			if applied, err := commit(cmd, plan); !applied || err != nil {
+				return err
+			}
+
+			served("Repository served 🥃:", interfaceChange)
+			served(driver.Type+" implementation served 🥃:", infraChange)
+			driverHint(driver)
 			return nil
 		},
 	}
+	addOverwriteFlags(cmd)
+	addDriverFlag(cmd)
+	addCRUDFlag(cmd)
 
 	return cmd
 }
+
+// repoOptions decides how a repository is generated
+type repoOptions struct {
+	Driver internal.Driver
+	CRUD   bool   // declare Create, Update, Delete, List and Count too
+	Table  string // table of the SQL implementations, see sqlTable by default
+}
+
+// planRepo adds the repository interface and its implementation to plan
+func planRepo(plan *internal.Plan, entity string, fields internal.Fields, opts repoOptions) (*internal.FileChange, *internal.FileChange, error) {
+	driver := opts.Driver
+
+	ctx, err := contextOption()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Repository interface
+	outInterface := repoInterfacePath(entity)
+
+	// Infrastructure implementation
+	outInfra := filepath.Join(
+		"infrastructure/database",
+		driver.Package,
+		internal.ToSnake(entity)+"_"+driver.Package+".go",
+	)
+
+	id := fields.Get("ID")
+	if id == nil {
+		id = &internal.DefaultFields()[0]
+	}
+
+	data := map[string]any{
+		"Entity":  internal.ToPascalCase(entity),
+		"Module":  internal.GetModuleName(),
+		"Fields":  fields,
+		"ID":      id,
+		"Driver":  driver,
+		"CRUD":    opts.CRUD,
+		"Context": ctx,
+		"Errors":  internal.DomainErrors,
+	}
+	if driver.Dialect != "" {
+		table, err := sqlTable(driver.Dialect, entity, fields)
+		if err != nil {
+			return nil, nil, err
+		}
+		if table != nil && opts.Table != "" {
+			table.Name = opts.Table
+		}
+		data["Table"] = table
+	}
+
+	if err := planDomainErrors(plan, data); err != nil {
+		return nil, nil, err
+	}
+
+	interfaceChange, err := plan.AddTemplate(templates.FS, "repository/repo_interface.go.tpl", outInterface, data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	infraChange, err := plan.AddTemplate(templates.FS, driver.Template, outInfra, data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if table, _ := data["Table"].(*internal.SQLTable); table != nil && table.JSON() {
+		out := filepath.Join("infrastructure/database", driver.Package, "json_column.go")
+		if err := planOnce(plan, "repository/json_column.go.tpl", out, data); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if opts.CRUD {
+		if err := planFilter(plan, entity, fields, data); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if ctx {
+		// Repositories taking a context join the transactions of the unit
+		// of work of their package
+		if err := planUnitOfWork(plan, driver, data); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return interfaceChange, infraChange, nil
+}
+
+// planFilter adds the filter List takes and the function parsing it from
+// query parameters
+func planFilter(plan *internal.Plan, entity string, fields internal.Fields, data map[string]any) error {
+	if fields == nil {
+		// Unknown fields, the filter only sorts and pages by ID
+		filterData := maps.Clone(data)
+		filterData["Fields"] = internal.Fields{*data["ID"].(*internal.Field)}
+		data = filterData
+	}
+
+	snake := internal.ToSnake(entity)
+	if _, err := plan.AddTemplate(templates.FS, "repository/filter.go.tpl", filepath.Join("internal/repository", snake+"_filter.go"), data); err != nil {
+		return err
+	}
+	_, err := plan.AddTemplate(templates.FS, "handler/filter.go.tpl", filterPath(entity), data)
+	return err
+}
+
+// filterPath returns where Parse<Entity>Filter is generated
+func filterPath(entity string) string {
+	return filepath.Join("internal/handlers", internal.ToSnake(entity)+"_filter.go")
+}
+
+// hasFilter reports whether Parse<Entity>Filter exists, or is in one of plans
+func hasFilter(entity string, plans ...*internal.Plan) bool {
+	for _, plan := range plans {
+		if plan.Change(filterPath(entity)) != nil {
+			return true
+		}
+	}
+	_, err := os.Stat(filterPath(entity))
+	return err == nil
+}
+
+// hasCRUD reports whether the repository of entity has the CRUD methods,
+// which are generated with its filter
+func hasCRUD(entity string, plans ...*internal.Plan) bool {
+	return hasFilter(entity, plans...)
+}
+
+// unitOfWorkPath is where the UnitOfWork port is generated
+var unitOfWorkPath = filepath.Join("internal", "repository", "unit_of_work.go")
+
+// planUnitOfWork creates the UnitOfWork port and its implementation for
+// driver, unless they exist already
+func planUnitOfWork(plan *internal.Plan, driver internal.Driver, data map[string]any) error {
+	if err := planOnce(plan, "repository/unit_of_work.go.tpl", unitOfWorkPath, data); err != nil {
+		return err
+	}
+	out := filepath.Join("infrastructure/database", driver.Package, "unit_of_work.go")
+	return planOnce(plan, driver.UnitOfWork, out, data)
+}
+
+// planOnce adds a file shared by several components to plan, unless it
+// exists or is planned already
+func planOnce(plan *internal.Plan, tpl, path string, data map[string]any) error {
+	if plan.Change(path) != nil {
+		return nil
+	}
+	if _, err := os.Stat(path); err == nil || !os.IsNotExist(err) {
+		return err
+	}
+	_, err := plan.AddTemplate(templates.FS, tpl, path, data)
+	return err
+}
+
+// errorsPath is where the domain errors used by repositories are generated
+var errorsPath = filepath.Join("internal", "domain", "errors.go")
+
+// planDomainErrors creates the errors shared by every repository once, and
+// adds the ones an existing errors file lacks
+func planDomainErrors(plan *internal.Plan, data map[string]any) error {
+	if plan.Change(errorsPath) != nil {
+		return nil
+	}
+
+	old, err := os.ReadFile(errorsPath)
+	if os.IsNotExist(err) {
+		_, err = plan.AddTemplate(templates.FS, "repository/errors.go.tpl", errorsPath, data)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	merged, err := internal.MergeDomainErrors(old)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errorsPath, err)
+	}
+	_, err = plan.AddPatch(errorsPath, merged)
+	return err
+}
+
+// repoInterfacePath returns where the repository interface of entity is
+// generated
+func repoInterfacePath(entity string) string {
+	return filepath.Join("internal/repository", internal.ToSnake(entity)+"_repository.go")
+}
+
+// addCRUDFlag registers --crud
+func addCRUDFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("crud", false, "Generate Create, Update, Delete, List and Count repository methods")
+}
+
+// crudOption reports whether the repository of entity gets the CRUD methods:
+// when --crud is set or the existing repository already declares them
+func crudOption(cmd *cobra.Command, entity string) (bool, error) {
+	if boolFlag(cmd, "crud") {
+		return true, nil
+	}
+
+	methods, err := internal.RepositoryMethods(repoInterfacePath(entity), internal.ToPascalCase(entity)+"Repository")
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return slices.Contains(methods, "Create"), nil
+}
+
+// driverHint reminds which module provides the database/sql driver
+func driverHint(driver internal.Driver) {
+	if driver.Module != "" {
+		fmt.Printf("ℹ️  The %s driver needs %s: go get %s\n", driver.Name, driver.Module, driver.Module)
+	}
+}
@@ -13,39 +13,118 @@ func NewMakeUseCaseCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "usecase <Name> <Entity>",
 		Short: "Generate a usecase",
-		Args:  cobra.ExactArgs(2),
+		Long: `Generate a usecase.
+
+The entity fields are read from its struct, or from field:type arguments (see
+make entity).
+
+The verb the name starts with decides the body of Execute: Create stores the
+entity of the input, Get finds it by ID, Update and Delete change it, and
+other names get a demo body to replace with the business logic.
+
+--tx runs the body of Execute in a transaction of the repository.UnitOfWork
+the use case receives: the repository calls made with the transaction
+context are committed together, or rolled back when the body returns an
+error. It needs context: true in .sazerac.yaml, as the transaction travels
+in the context.`,
+		Example: "  sazerac make usecase TransferFunds Account --tx",
+		Args:    cobra.MinimumNArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			name := args[0]
 			entity := args[1]
-			namePascal := internal.ToPascalCase(name)
-			entityPascal := internal.ToPascalCase(entity)
-
-			out := filepath.Join(
-				"internal/usecases",
-				internal.ToSnake(name)+"_usecase.go",
-			)
-
-			data := map[string]any{
-				"Name":   namePascal,
-				"Entity": entityPascal,
-				"Module": internal.GetModuleName(),
-			}
 
-			err := internal.WriteTemplate(
-				templates.FS,
-				"usecase/usecase.go.tpl",
-				out,
-				data,
-			)
+			fields, err := entityFields(entity, args[2:])
+			if err != nil {
+				return err
+			}
+			if fields == nil {
+				fields = internal.DefaultFields()
+			}
 
+			plan := &internal.Plan{}
+			opts := useCaseOptions{Tx: boolFlag(cmd, "tx"), CRUD: hasCRUD(entity)}
+			change, err := planUseCase(plan, name, entity, fields, opts)
 			if err != nil {
 				return err
 			}
 
-			fmt.Println("UseCase served 🥃:", out)
+			if applied, err := commit(cmd, plan); !applied || err != nil {
+				return err
+			}
+
+			served("UseCase served 🥃:", change)
 			return nil
 		},
 	}
+	addOverwriteFlags(cmd)
+	addTxFlag(cmd)
 
 	return cmd
 }
+
+// addTxFlag registers --tx
+func addTxFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("tx", false, "Run the use case in a transaction of a UnitOfWork")
+}
+
+// useCaseOptions decides how a use case is generated
+type useCaseOptions struct {
+	Tx   bool // run Execute in a transaction
+	CRUD bool // the repository has the CRUD methods and List takes a filter
+}
+
+// planUseCase adds the use case to plan
+func planUseCase(plan *internal.Plan, name, entity string, fields internal.Fields, opts useCaseOptions) (*internal.FileChange, error) {
+	tx := opts.Tx
+	out := useCasePath(name)
+
+	ctx, err := contextOption()
+	if err != nil {
+		return nil, err
+	}
+
+	if tx && !ctx {
+		return nil, fmt.Errorf("--tx needs context: true in .sazerac.yaml, transactions are passed to the repositories in the context")
+	}
+
+	// The operation of the verb decides the body of Execute and its input:
+	// Get and Delete take the ID, Create and Update the whole entity
+	op := internal.UseCaseOperation(name)
+	id := fields.Get("ID")
+	var input internal.Fields
+	switch {
+	case id == nil && op != internal.OpCreate && op != internal.OpList:
+		op = ""
+	case op == internal.OpCreate || op == internal.OpUpdate:
+		input = fields
+	case op == internal.OpGet || op == internal.OpDelete:
+		input = internal.Fields{*id}
+	}
+
+	data := map[string]any{
+		"Name":      internal.ToPascalCase(name),
+		"Entity":    internal.ToPascalCase(entity),
+		"Module":    internal.GetModuleName(),
+		"Fields":    fields,
+		"Context":   ctx,
+		"Tx":        tx,
+		"Operation": op,
+		"CRUD":      opts.CRUD,
+		"ID":        id,
+		"Input":     input,
+		"Filter":    opts.CRUD && op == internal.OpList,
+	}
+
+	if tx {
+		if err := planOnce(plan, "repository/unit_of_work.go.tpl", unitOfWorkPath, data); err != nil {
+			return nil, err
+		}
+	}
+
+	return plan.AddTemplate(templates.FS, "usecase/usecase.go.tpl", out, data)
+}
+
+// useCasePath returns where the use case called name is generated
+func useCasePath(name string) string {
+	return filepath.Join("internal/usecases", internal.ToSnake(name)+"_usecase.go")
+}
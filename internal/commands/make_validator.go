@@ -1,7 +1,6 @@
 package commands
 
 import (
-	"fmt"
 	"path/filepath"
 
 	"github.com/fsjorgeluis/sazerac/internal"
@@ -13,35 +12,59 @@ func NewMakeValidatorCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "validator <Entity>",
 		Short: "Generate a simple validator",
-		Args:  cobra.ExactArgs(1),
+		Long:  "Generate a simple validator.\n\nThe entity fields are read from its struct, or from field:type arguments (see make entity).",
+		Args:  cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			entity := args[0]
-			entityPascal := internal.ToPascalCase(entity)
 
-			out := filepath.Join(
-				"internal/domain/validators",
-				internal.ToSnake(entity)+"_validator.go",
-			)
-
-			data := map[string]any{
-				"Entity": entityPascal,
+			fields, err := entityFields(entity, args[1:])
+			if err != nil {
+				return err
 			}
 
-			err := internal.WriteTemplate(
-				templates.FS,
-				"validator/validator.go.tpl",
-				out,
-				data,
-			)
-
+			plan := &internal.Plan{}
+			change, err := planValidator(plan, entity, fields)
 			if err != nil {
 				return err
 			}
 
-			fmt.Println("Validator served 🥃:", out)
+			if applied, err := commit(cmd, plan); !applied || err != nil {
+				return err
+			}
+
+			served("Validator served 🥃:", change)
 			return nil
 		},
 	}
+	addOverwriteFlags(cmd)
 
 	return cmd
 }
+
+// planValidator adds the entity validator to plan
+func planValidator(plan *internal.Plan, entity string, fields internal.Fields) (*internal.FileChange, error) {
+	out := filepath.Join(
+		"internal/domain/validators",
+		internal.ToSnake(entity)+"_validator.go",
+	)
+
+	data := map[string]any{
+		"Entity": internal.ToPascalCase(entity),
+		"Module": internal.GetModuleName(),
+		"Fields": fields,
+		"Errors": internal.DomainErrors,
+	}
+	if fields != nil {
+		// Validation errors wrap domain.ErrInvalidInput
+		if err := planDomainErrors(plan, data); err != nil {
+			return nil, err
+		}
+	}
+
+	return plan.AddTemplate(
+		templates.FS,
+		"validator/validator.go.tpl",
+		out,
+		data,
+	)
+}
@@ -0,0 +1,89 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fsjorgeluis/sazerac/internal"
+	"github.com/spf13/cobra"
+)
+
+func NewOpenAPICmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "openapi",
+		Short: "Generate the OpenAPI spec of the HTTP handlers",
+		Long: `Generate the OpenAPI 3 spec of the HTTP handlers of the project.
+
+The handlers of internal/handlers are read with go/parser: the route their
+Register method adds (for any --router), the use case of their UC field and
+the status they write on success. Every operation gets its path parameters,
+the use case input as the JSON request body of POST, PUT and PATCH routes,
+the result of the use case as the response and the error responses of
+writeError (400, 404, 409 and 500). Entities of internal/domain/entities
+and use case inputs become schemas, with the property names encoding/json
+gives their fields.
+
+The spec is derived from the code, so it is replaced on every run.`,
+		Example: "  sazerac openapi\n  sazerac openapi -o api/openapi.yaml --title \"Users API\" --api-version 1.2.0",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			output, _ := cmd.Flags().GetString("output")
+			title, _ := cmd.Flags().GetString("title")
+			version, _ := cmd.Flags().GetString("api-version")
+			if title == "" {
+				title = internal.GetProjectName()
+			}
+
+			plan := &internal.Plan{}
+			change, err := planOpenAPI(plan, output, title, version)
+			if err != nil {
+				return err
+			}
+
+			// The spec is derived from the code, so it is replaced without
+			// --force
+			opts := writeOptions(cmd)
+			if opts.Mode == internal.OverwriteNever {
+				opts.Mode = internal.OverwriteForce
+			}
+			if boolFlag(cmd, "dry-run") {
+				plan.Print(cmd.OutOrStdout(), opts.Mode)
+				return nil
+			}
+			if err := plan.Apply(opts); err != nil {
+				return err
+			}
+			served("OpenAPI spec served 🥃:", change)
+			return nil
+		},
+	}
+	cmd.Flags().StringP("output", "o", "openapi.yaml", "File the spec is written to")
+	cmd.Flags().String("title", "", "Title of the API, the project name by default")
+	cmd.Flags().String("api-version", "0.1.0", "Version of the API")
+	addOverwriteFlags(cmd)
+
+	return cmd
+}
+
+// planOpenAPI adds the OpenAPI spec of the HTTP handlers of the project to
+// plan
+func planOpenAPI(plan *internal.Plan, out, title, version string) (*internal.FileChange, error) {
+	ops, err := internal.APIOperations("internal/handlers", "internal/usecases")
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if len(ops) == 0 {
+		return nil, fmt.Errorf("no HTTP handlers found in internal/handlers, generate them with make handler --kind http")
+	}
+
+	entities, err := internal.LoadStructs("internal/domain/entities")
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := internal.OpenAPISpec(title, version, entities, ops)
+	if err != nil {
+		return nil, err
+	}
+	return plan.AddFile(out, content)
+}
@@ -0,0 +1,178 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fsjorgeluis/sazerac/internal"
+	"github.com/spf13/cobra"
+)
+
+// AddDryRunFlag registers the global --dry-run flag on the root command
+func AddDryRunFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().Bool("dry-run", false, "Print the files that would be created or modified without writing anything")
+}
+
+// addOverwriteFlags registers the flags that decide what happens with files
+// that already exist on disk
+func addOverwriteFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("force", false, "Overwrite existing files")
+	cmd.Flags().Bool("skip-existing", false, "Keep existing files and only create missing ones")
+	cmd.Flags().BoolP("interactive", "i", false, "Ask whether to overwrite, skip or diff each existing file")
+	cmd.MarkFlagsMutuallyExclusive("force", "skip-existing", "interactive")
+}
+
+// writeOptions builds the write options from the overwrite flags. Commands
+// that run other commands (make all) pass their own *cobra.Command along, so
+// a missing flag simply means its default.
+func writeOptions(cmd *cobra.Command) internal.WriteOptions {
+	opts := internal.WriteOptions{
+		Mode: internal.OverwriteNever,
+		In:   cmd.InOrStdin(),
+		Out:  cmd.OutOrStdout(),
+	}
+
+	switch {
+	case boolFlag(cmd, "force"):
+		opts.Mode = internal.OverwriteForce
+	case boolFlag(cmd, "skip-existing"):
+		opts.Mode = internal.OverwriteSkip
+	case boolFlag(cmd, "interactive"):
+		opts.Mode = internal.OverwritePrompt
+	}
+
+	return opts
+}
+
+// boolFlag reads a boolean flag from cmd or its parents, treating an
+// undefined flag as false
+func boolFlag(cmd *cobra.Command, name string) bool {
+	flag := cmd.Flag(name)
+	return flag != nil && flag.Value.String() == "true"
+}
+
+// commit applies plan, or only prints it when --dry-run is set. It reports
+// whether the plan was applied.
+func commit(cmd *cobra.Command, plan *internal.Plan) (bool, error) {
+	opts := writeOptions(cmd)
+	if boolFlag(cmd, "dry-run") {
+		plan.Print(cmd.OutOrStdout(), opts.Mode)
+		return false, nil
+	}
+
+	if err := plan.Apply(opts); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// addDriverFlag registers --driver, which picks the database repositories
+// are generated for
+func addDriverFlag(cmd *cobra.Command) {
+	cmd.Flags().String("driver", "", fmt.Sprintf("Database driver (%s), defaults to database.driver in %s",
+		strings.Join(internal.DriverNames(), ", "), internal.ConfigFile))
+}
+
+// driverOption returns the driver chosen with --driver, or the one configured
+// for the project
+func driverOption(cmd *cobra.Command) (internal.Driver, error) {
+	name := ""
+	if flag := cmd.Flag("driver"); flag != nil {
+		name = flag.Value.String()
+	}
+	if name == "" {
+		cfg, err := internal.LoadConfig()
+		if err != nil {
+			return internal.Driver{}, err
+		}
+		name = cfg.Database.Driver
+	}
+
+	return internal.LookupDriver(name)
+}
+
+// addRouterFlag registers --router, which picks the router HTTP handlers
+// are generated for
+func addRouterFlag(cmd *cobra.Command) {
+	cmd.Flags().String("router", "", fmt.Sprintf("Router of HTTP handlers (%s), defaults to http.router in %s",
+		strings.Join(internal.RouterNames(), ", "), internal.ConfigFile))
+}
+
+// routerOption returns the router chosen with --router, or the one configured
+// for the project. A project keeps the router of its first HTTP handler: when
+// plan is not nil and none is configured yet, the router is stored in the
+// config file by plan, and choosing another one later is an error.
+func routerOption(cmd *cobra.Command, plan *internal.Plan) (internal.Router, error) {
+	name := ""
+	if flag := cmd.Flag("router"); flag != nil {
+		name = flag.Value.String()
+	}
+	cfg, err := internal.LoadConfig()
+	if err != nil {
+		return internal.Router{}, err
+	}
+	configured := cfg.HTTP.Router
+	if name == "" {
+		name = configured
+	}
+	router, err := internal.LookupRouter(name)
+	if err != nil {
+		return internal.Router{}, err
+	}
+	if configured != "" && configured != router.Name {
+		return internal.Router{}, fmt.Errorf("the HTTP handlers of the project use %s, set http.router in %s to generate them for %s",
+			configured, internal.ConfigFile, router.Name)
+	}
+
+	if configured == "" && plan != nil && plan.Change(internal.ConfigFile) == nil {
+		content, err := os.ReadFile(internal.ConfigFile)
+		if err != nil && !os.IsNotExist(err) {
+			return internal.Router{}, err
+		}
+		if content, err = internal.SetConfig(content, router.Name, "http", "router"); err != nil {
+			return internal.Router{}, err
+		}
+		if _, err := plan.AddPatch(internal.ConfigFile, content); err != nil {
+			return internal.Router{}, err
+		}
+	}
+	return router, nil
+}
+
+// served prints msg for a written file or notes why it was left alone
+func served(msg string, change *internal.FileChange) {
+	switch {
+	case change.Written:
+		fmt.Println(msg, change.Path)
+	case change.Unchanged():
+		fmt.Println("Already up to date:", change.Path)
+	default:
+		fmt.Println("Kept existing file:", change.Path)
+	}
+}
+
+// contextOption reports whether the generated code takes a context.Context,
+// as configured in .sazerac.yaml
+func contextOption() (bool, error) {
+	cfg, err := internal.LoadConfig()
+	if err != nil {
+		return false, err
+	}
+	return cfg.Context, nil
+}
+
+// sqlTable returns the table entity is stored in, named as database.tables
+// in .sazerac.yaml says when the project maps it to an existing table. It
+// returns nil when the fields have no ID.
+func sqlTable(dialect internal.Dialect, entity string, fields internal.Fields) (*internal.SQLTable, error) {
+	cfg, err := internal.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	table := internal.NewSQLTable(dialect, entity, fields)
+	if table != nil {
+		table.Name = cfg.Database.Table(entity)
+	}
+	return table, nil
+}
@@ -0,0 +1,188 @@
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFile is the per-project configuration file, relative to the project root
+const ConfigFile = ".sazerac.yaml"
+
+// Naming strategies for generated struct tags
+const (
+	NamingSnakeCase = "snake_case"
+	NamingCamelCase = "camelCase"
+)
+
+// TagKeys are the struct tags sazerac knows how to generate
+var TagKeys = []string{"json", "db", "yaml", "validate", "bson"}
+
+// Config is the project configuration stored in .sazerac.yaml
+type Config struct {
+	Tags     TagsConfig     `yaml:"tags"`
+	Database DatabaseConfig `yaml:"database"`
+	HTTP     HTTPConfig     `yaml:"http"`
+	// Context makes repositories, use cases and handlers take a
+	// context.Context as first argument
+	Context bool `yaml:"context"`
+}
+
+// TagsConfig decides which struct tags entity fields get and how they are named
+type TagsConfig struct {
+	Keys   []string `yaml:"keys"`
+	Naming string   `yaml:"naming"`
+}
+
+// DatabaseConfig decides which database repositories are generated for
+type DatabaseConfig struct {
+	Driver string `yaml:"driver"`
+	// Tables maps entities to the existing tables they are stored in when
+	// these are not named after them, e.g. Person: person
+	Tables map[string]string `yaml:"tables,omitempty"`
+}
+
+// HTTPConfig decides how HTTP handlers are served
+type HTTPConfig struct {
+	// Router HTTP handlers are generated for, set by the first of them and
+	// the net/http ServeMux when empty
+	Router string `yaml:"router"`
+}
+
+// Table returns the table entity is stored in
+func (c DatabaseConfig) Table(entity string) string {
+	if table, ok := c.Tables[ToPascalCase(entity)]; ok {
+		return table
+	}
+	return TableName(entity)
+}
+
+// DefaultConfig is used when no config file exists. Such projects were
+// created before the file, so they keep the MySQL repositories and the
+// signatures without context they had; init turns both on for new ones.
+func DefaultConfig() Config {
+	return Config{
+		Tags: TagsConfig{
+			Keys:   []string{"json", "db"},
+			Naming: NamingSnakeCase,
+		},
+		Database: DatabaseConfig{
+			Driver: DriverMySQL,
+		},
+	}
+}
+
+// LoadConfig reads .sazerac.yaml from the current directory. Settings missing
+// from the file, or the whole file, fall back to DefaultConfig.
+func LoadConfig() (Config, error) {
+	cfg := DefaultConfig()
+
+	content, err := os.ReadFile(ConfigFile)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+
+	if err := yaml.Unmarshal(content, &cfg); err != nil {
+		return cfg, fmt.Errorf("%s: %w", ConfigFile, err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return cfg, fmt.Errorf("%s: %w", ConfigFile, err)
+	}
+
+	return cfg, nil
+}
+
+// Validate reports unknown settings
+func (c Config) Validate() error {
+	if c.Tags.Naming != NamingSnakeCase && c.Tags.Naming != NamingCamelCase {
+		return fmt.Errorf("unknown tags.naming %q, expected %s or %s", c.Tags.Naming, NamingSnakeCase, NamingCamelCase)
+	}
+	for _, key := range c.Tags.Keys {
+		if !slices.Contains(TagKeys, key) {
+			return fmt.Errorf("unknown tag %q in tags.keys, expected one of %v", key, TagKeys)
+		}
+	}
+	if _, err := LookupDriver(c.Database.Driver); err != nil {
+		return fmt.Errorf("database.driver: %w", err)
+	}
+	if _, err := LookupRouter(c.HTTP.Router); err != nil {
+		return fmt.Errorf("http.router: %w", err)
+	}
+	return nil
+}
+
+// topLevelKey matches the first line of a top-level setting or its comment
+var topLevelKey = regexp.MustCompile(`(?m)^([#\w])`)
+
+// SetConfig sets the setting at keys (e.g. database, tables) of the config
+// file content to value, keeping the other settings and their comments.
+// Missing mappings on the way are created.
+func SetConfig(content []byte, value any, keys ...string) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", ConfigFile, err)
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+
+	var node yaml.Node
+	if err := node.Encode(value); err != nil {
+		return nil, err
+	}
+
+	mapping := doc.Content[0]
+	for i, key := range keys {
+		if mapping.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("%s: %s is not a mapping", ConfigFile, strings.Join(keys[:i], "."))
+		}
+		var found *yaml.Node
+		for j := 0; j+1 < len(mapping.Content); j += 2 {
+			if mapping.Content[j].Value == key {
+				found = mapping.Content[j+1]
+				break
+			}
+		}
+		if i == len(keys)-1 {
+			if found != nil {
+				found.Kind, found.Tag, found.Value, found.Content, found.Style = node.Kind, node.Tag, node.Value, node.Content, node.Style
+			} else {
+				mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, &node)
+			}
+			break
+		}
+		if found == nil {
+			found = &yaml.Node{Kind: yaml.MappingNode}
+			mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, found)
+		}
+		mapping = found
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+
+	// The encoder drops blank lines, put them back between the top-level
+	// settings
+	var out []string
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	for i, line := range lines {
+		if i > 0 && topLevelKey.MatchString(line) && strings.HasPrefix(lines[i-1], " ") {
+			out = append(out, "")
+		}
+		out = append(out, line)
+	}
+	return []byte(strings.Join(out, "\n") + "\n"), nil
+}
@@ -0,0 +1,117 @@
+package internal
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get current directory: %v", err)
+	}
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+	defer os.Chdir(originalDir)
+
+	tests := []struct {
+		name       string
+		content    string
+		wantKeys   []string
+		wantNaming string
+		wantErr    bool
+	}{
+		{
+			name:       "No config file",
+			wantKeys:   []string{"json", "db"},
+			wantNaming: NamingSnakeCase,
+		},
+		{
+			name:       "Custom tags",
+			content:    "tags:\n  keys: [json, yaml, validate]\n  naming: camelCase\n",
+			wantKeys:   []string{"json", "yaml", "validate"},
+			wantNaming: NamingCamelCase,
+		},
+		{
+			name:       "Partial config keeps defaults",
+			content:    "tags:\n  naming: camelCase\n",
+			wantKeys:   []string{"json", "db"},
+			wantNaming: NamingCamelCase,
+		},
+		{
+			name:    "Unknown naming",
+			content: "tags:\n  naming: kebab-case\n",
+			wantErr: true,
+		},
+		{
+			name:    "Unknown tag",
+			content: "tags:\n  keys: [xml]\n",
+			wantErr: true,
+		},
+		{
+			name:    "Unknown driver",
+			content: "database:\n  driver: oracle\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Remove(ConfigFile)
+			if tt.content != "" {
+				os.WriteFile(ConfigFile, []byte(tt.content), 0644)
+			}
+
+			cfg, err := LoadConfig()
+			if tt.wantErr {
+				if err == nil {
+					t.Error("LoadConfig() expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadConfig() failed: %v", err)
+			}
+			if !slices.Equal(cfg.Tags.Keys, tt.wantKeys) || cfg.Tags.Naming != tt.wantNaming {
+				t.Errorf("LoadConfig() = %+v, expected keys %v and naming %s", cfg.Tags, tt.wantKeys, tt.wantNaming)
+			}
+		})
+	}
+}
+
+func TestSetConfig(t *testing.T) {
+	content := "# Sazerac project configuration\n\ntags:\n  # Struct tags\n  keys: [json, db]\n\ndatabase:\n  driver: sqlite\n\n# Context comment\ncontext: true\n"
+
+	result, err := SetConfig([]byte(content), map[string]string{"Person": "person"}, "database", "tables")
+	if err != nil {
+		t.Fatalf("SetConfig() failed: %v", err)
+	}
+	expected := "# Sazerac project configuration\n\ntags:\n  # Struct tags\n  keys: [json, db]\n\ndatabase:\n  driver: sqlite\n  tables:\n    Person: person\n\n# Context comment\ncontext: true\n"
+	if string(result) != expected {
+		t.Errorf("SetConfig() = %q, expected %q", result, expected)
+	}
+
+	// Existing settings are replaced, missing mappings created
+	result, err = SetConfig(result, false, "context")
+	if err != nil {
+		t.Fatalf("SetConfig() failed: %v", err)
+	}
+	result, err = SetConfig(result, "chi", "http", "router")
+	if err != nil {
+		t.Fatalf("SetConfig() failed: %v", err)
+	}
+	if !strings.Contains(string(result), "\ncontext: false\nhttp:\n  router: chi\n") {
+		t.Errorf("SetConfig() = %q, expected context: false and http.router: chi", result)
+	}
+
+	if result, err := SetConfig(nil, "sqlite", "database", "driver"); err != nil || string(result) != "database:\n  driver: sqlite\n" {
+		t.Errorf("SetConfig() on an empty file = %q, %v", result, err)
+	}
+	if _, err := SetConfig([]byte("database: sqlite\n"), "x", "database", "driver"); err == nil {
+		t.Error("Setting a key inside a scalar should fail")
+	}
+}
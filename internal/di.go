@@ -0,0 +1,385 @@
+package internal
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// Wiring is a use case registered in the DI container together with the
+// entity whose repository it receives and the driver of that repository
+type Wiring struct {
+	UseCase string
+	Entity  string
+	Driver  string
+	Tx      bool   // the use case also receives the UnitOfWork of the driver
+	Kind    string // kind of the handler, console when empty
+	Router  string // router of HTTP handlers, stdlib when empty
+}
+
+// MergeDI adds the repositories, use cases and handlers of wirings that the
+// DI container in src does not have yet. Whatever is already wired, including
+// code written by hand, is kept as it is, so merging twice changes nothing.
+func MergeDI(src []byte, module string, wirings []Wiring) ([]byte, error) {
+	p, err := NewPatcher("di.go", src)
+	if err != nil {
+		return nil, err
+	}
+
+	container := p.Struct("Container")
+	constructor := p.Func("NewContainer")
+	if container == nil || constructor == nil || constructor.Body == nil {
+		return nil, fmt.Errorf("no Container struct and NewContainer function to add the wiring to")
+	}
+	ret, lit := containerLiteral(constructor)
+	if lit == nil {
+		return nil, fmt.Errorf("NewContainer does not return a &Container{...} literal")
+	}
+
+	members := fieldNames(container)
+	declared := DeclaredNames(constructor.Body)
+	placeholders := nilConnections(constructor.Body)
+	keys := literalKeys(lit)
+	registered := registeredHandlers(constructor.Body)
+
+	var stmts, fields strings.Builder
+	var elems []string
+	var setups, closers []string
+	for _, w := range wirings {
+		useCase, entity := ToPascalCase(w.UseCase), ToPascalCase(w.Entity)
+		repo, uc, handler := entity+"Repo", useCase+"UC", useCase+"Handler"
+		driver, err := LookupDriver(w.Driver)
+		if err != nil {
+			return nil, err
+		}
+
+		if stmt := placeholders[driver.Conn]; driver.Setup != "" && stmt != nil {
+			// A connection declared as nil, e.g. var db *sql.DB = nil, is
+			// opened instead: repositories cannot use it as it is
+			p.Replace(stmt, driver.Setup)
+			addSetupImports(p, driver)
+			delete(placeholders, driver.Conn)
+		}
+		if !declared[repo] {
+			if driver.Setup != "" && !declared[driver.Conn] {
+				// The first repository of a driver opens its connection
+				setups = append(setups, driver.Setup)
+				addSetupImports(p, driver)
+
+				field := "DB"
+				if !members[field] || keys[field] {
+					// Further connections get a field of their own
+					field = driver.Type + "DB"
+					closers = append(closers, field)
+				}
+				if !members[field] {
+					fmt.Fprintf(&fields, "%s *sql.DB\n", field)
+					members[field] = true
+				}
+				if !keys[field] {
+					elems = append(elems, field+": "+driver.Conn)
+					keys[field] = true
+				}
+				declared[driver.Conn] = true
+			}
+
+			fmt.Fprintf(&stmts, "%s := %s.New%s(%s)\n", repo, driver.Package, driver.RepoType(entity), driver.Conn)
+			p.AddImport(module + "/infrastructure/database/" + driver.Package)
+			declared[repo] = true
+		}
+		if !declared[uc] || !declared[handler] {
+			fmt.Fprintf(&stmts, "\n// Initialize %s use case and handler\n", useCase)
+		}
+		if !declared[uc] {
+			args := repo
+			if w.Tx {
+				// One unit of work per driver, shared by its use cases
+				uow := driver.Package + "UoW"
+				if !declared[uow] {
+					fmt.Fprintf(&stmts, "%s := %s.NewUnitOfWork(%s)\n", uow, driver.Package, driver.Conn)
+					p.AddImport(module + "/infrastructure/database/" + driver.Package)
+					declared[uow] = true
+				}
+				args += ", " + uow
+			}
+			fmt.Fprintf(&stmts, "%s := usecases.New%sUseCase(%s)\n", uc, useCase, args)
+			p.AddImport(module + "/internal/usecases")
+			declared[uc] = true
+		}
+		handlers := handlersPackage(w.Kind)
+		if !declared[handler] {
+			fmt.Fprintf(&stmts, "%s := %s.New%sHandler(%s)\n", handler, handlers, useCase, uc)
+			addHandlersImport(p, module, w.Kind)
+			declared[handler] = true
+		}
+		host, ok := handlerHosts[w.Kind]
+		if w.Kind == KindHTTP {
+			router, err := LookupRouter(w.Router)
+			if err != nil {
+				return nil, err
+			}
+			host.New, host.Type, host.Imports = router.New, router.Type, []string{router.Import}
+		}
+		if ok && !registered[handler] {
+			if !declared[host.Var] {
+				// The handlers register themselves on what the container
+				// holds for main to serve
+				fmt.Fprintf(&stmts, "\n// %s\n%s := %s\n", host.Comment, host.Var, host.New)
+				addHostImports(p, module, host)
+				declared[host.Var] = true
+			}
+			register := "%s.Register(%s)\n"
+			if host.Fails {
+				register = "if err := %s.Register(%s); err != nil {\nreturn nil, err\n}\n"
+			}
+			fmt.Fprintf(&stmts, register, handler, host.Var)
+			registered[handler] = true
+			if !members[host.Field] {
+				fmt.Fprintf(&fields, "%s %s\n", host.Field, host.Type)
+				addHostImports(p, module, host)
+				members[host.Field] = true
+			}
+			if !keys[host.Field] {
+				elems = append(elems, host.Field+": "+host.Var)
+				keys[host.Field] = true
+			}
+		}
+		if !members[handler] {
+			fmt.Fprintf(&fields, "%s *%s.%s\n", handler, handlers, handler)
+			addHandlersImport(p, module, w.Kind)
+			members[handler] = true
+		}
+		if !keys[handler] {
+			elems = append(elems, handler+": "+handler)
+			keys[handler] = true
+		}
+	}
+
+	if len(setups) > 0 {
+		p.Insert(constructor.Body.Lbrace+1, "\n"+strings.Join(setups, "\n\n")+"\n")
+	}
+	if closer := p.Method("Container", "Close"); closer != nil && len(closers) > 0 {
+		recv := closer.Recv.List[0].Names[0].Name
+		var code strings.Builder
+		for _, field := range closers {
+			fmt.Fprintf(&code, "\nif %[1]s.%[2]s != nil {\nif err := %[1]s.%[2]s.Close(); err != nil {\nreturn err\n}\n}\n", recv, field)
+		}
+		p.Insert(closer.Body.Lbrace+1, code.String())
+	}
+	if fields.Len() > 0 {
+		p.AddField(container, fields.String())
+	}
+	if stmts.Len() > 0 {
+		p.InsertBefore(ret, strings.TrimPrefix(stmts.String(), "\n")+"\n")
+	}
+	p.AddElements(lit, elems...)
+
+	return p.Bytes()
+}
+
+// addSetupImports adds the imports of the Setup of driver
+func addSetupImports(p *Patcher, driver Driver) {
+	for _, path := range driver.Imports {
+		p.AddImport(path)
+	}
+	if driver.SQLDriver != "" {
+		p.AddNamedImport("_", driver.SQLDriver)
+	}
+	p.AddImport("database/sql")
+}
+
+// nilConnections returns the var statements of body declaring a single
+// *sql.DB without a value or as nil, by the name of the variable
+func nilConnections(body *ast.BlockStmt) map[string]ast.Stmt {
+	stmts := map[string]ast.Stmt{}
+	for _, stmt := range body.List {
+		decl, ok := stmt.(*ast.DeclStmt)
+		if !ok {
+			continue
+		}
+		gen, ok := decl.Decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.VAR || len(gen.Specs) != 1 {
+			continue
+		}
+		spec := gen.Specs[0].(*ast.ValueSpec)
+		if len(spec.Names) != 1 || len(spec.Values) > 1 || spec.Type == nil || types.ExprString(spec.Type) != "*sql.DB" {
+			continue
+		}
+		if len(spec.Values) == 1 {
+			if ident, ok := spec.Values[0].(*ast.Ident); !ok || ident.Name != "nil" {
+				continue
+			}
+		}
+		stmts[spec.Names[0].Name] = stmt
+	}
+	return stmts
+}
+
+// TakesUnitOfWork reports whether the constructor of useCase, declared in
+// the Go file at filePath, receives a UnitOfWork
+func TakesUnitOfWork(filePath, useCase string) (bool, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), filePath, nil, 0)
+	if err != nil {
+		return false, err
+	}
+
+	name := "New" + ToPascalCase(useCase) + "UseCase"
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv != nil || fn.Name.Name != name {
+			continue
+		}
+		for _, param := range fn.Type.Params.List {
+			if sel, ok := param.Type.(*ast.SelectorExpr); ok && sel.Sel.Name == "UnitOfWork" {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// ConnectionField returns the field of the Container in the DI container
+// src that holds the connection opened in the variable conn, or "" when there
+// is none
+func ConnectionField(src []byte, conn string) (string, error) {
+	p, err := NewPatcher("di.go", src)
+	if err != nil {
+		return "", err
+	}
+	constructor := p.Func("NewContainer")
+	if constructor == nil || constructor.Body == nil {
+		return "", nil
+	}
+	_, lit := containerLiteral(constructor)
+	if lit == nil {
+		return "", nil
+	}
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		key, ok := kv.Key.(*ast.Ident)
+		if value, isIdent := kv.Value.(*ast.Ident); ok && isIdent && value.Name == conn {
+			return key.Name, nil
+		}
+	}
+	return "", nil
+}
+
+// registeredHandlers returns the variables of body whose Register method is
+// called, the handlers registered on a handlerHost
+func registeredHandlers(body *ast.BlockStmt) map[string]bool {
+	registered := map[string]bool{}
+	ast.Inspect(body, func(n ast.Node) bool {
+		if call, ok := n.(*ast.CallExpr); ok {
+			if sel, ok := call.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "Register" {
+				if ident, ok := sel.X.(*ast.Ident); ok {
+					registered[ident.Name] = true
+				}
+			}
+		}
+		return true
+	})
+	return registered
+}
+
+// handlerHost is what the DI container registers the handlers of a kind on
+type handlerHost struct {
+	Var     string // variable of NewContainer holding it
+	New     string // expression creating it
+	Comment string
+	Field   string // field of the Container holding it
+	Type    string
+	Imports []string // packages of New and Type, in the module when they start with /
+	Fails   bool     // Register returns an error
+}
+
+// handlerHosts are the hosts of the handler kinds that register themselves
+var handlerHosts = map[string]handlerHost{
+	// The router of HTTP handlers is the one of the wiring, a ServeMux here
+	KindHTTP: {"router", "http.NewServeMux()", "Routes of the HTTP handlers", "Router", "*http.ServeMux", []string{"net/http"}, false},
+	KindGRPC: {"grpcServer", "grpc.NewServer()", "Services of the gRPC handlers", "GRPCServer", "*grpc.Server", []string{"google.golang.org/grpc"}, false},
+	KindCLI:  {"cliRoot", "clihandlers.NewRoot()", "Commands of the CLI handlers", "CLI", "*cobra.Command", []string{"github.com/spf13/cobra"}, false},
+	KindConsumer: {
+		"broker", "inmemory.NewBroker()", "Subscriptions of the consumer handlers, swap the in-memory broker for\n// another messaging.Broker to use a real one", "Broker", "messaging.Broker",
+		[]string{"/internal/messaging", "/infrastructure/messaging/inmemory"}, true,
+	},
+}
+
+// addHostImports imports the packages host uses
+func addHostImports(p *Patcher, module string, host handlerHost) {
+	for _, path := range host.Imports {
+		if strings.HasPrefix(path, "/") {
+			path = module + path
+		}
+		p.AddImport(path)
+	}
+}
+
+// handlersPackage returns the name the package of the handlers of kind is
+// imported with. Handlers other than console and HTTP ones live in packages
+// of their own.
+func handlersPackage(kind string) string {
+	switch kind {
+	case KindGRPC, KindCLI, KindConsumer:
+		return kind + "handlers"
+	}
+	return "handlers"
+}
+
+// addHandlersImport imports the package of the handlers of kind
+func addHandlersImport(p *Patcher, module, kind string) {
+	if name := handlersPackage(kind); name != "handlers" {
+		p.AddNamedImport(name, module+"/internal/handlers/"+kind)
+		return
+	}
+	p.AddImport(module + "/internal/handlers")
+}
+
+// containerLiteral finds the `return &Container{...}, ...` statement of fn
+func containerLiteral(fn *ast.FuncDecl) (*ast.ReturnStmt, *ast.CompositeLit) {
+	for _, stmt := range fn.Body.List {
+		ret, ok := stmt.(*ast.ReturnStmt)
+		if !ok || len(ret.Results) == 0 {
+			continue
+		}
+		unary, ok := ret.Results[0].(*ast.UnaryExpr)
+		if !ok || unary.Op != token.AND {
+			continue
+		}
+		if lit, ok := unary.X.(*ast.CompositeLit); ok {
+			if ident, ok := lit.Type.(*ast.Ident); ok && ident.Name == "Container" {
+				return ret, lit
+			}
+		}
+	}
+	return nil, nil
+}
+
+// fieldNames returns the names of the fields of st
+func fieldNames(st *ast.StructType) map[string]bool {
+	names := map[string]bool{}
+	for _, field := range st.Fields.List {
+		for _, name := range field.Names {
+			names[name.Name] = true
+		}
+	}
+	return names
+}
+
+// literalKeys returns the keys set by a keyed composite literal
+func literalKeys(lit *ast.CompositeLit) map[string]bool {
+	keys := map[string]bool{}
+	for _, elt := range lit.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			if ident, ok := kv.Key.(*ast.Ident); ok {
+				keys[ident.Name] = true
+			}
+		}
+	}
+	return keys
+}
@@ -0,0 +1,438 @@
+package internal
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fsjorgeluis/sazerac/internal/templates"
+)
+
+func renderDI(t *testing.T, driver string, wirings []Wiring) []byte {
+	t.Helper()
+
+	d, err := LookupDriver(driver)
+	if err != nil {
+		t.Fatalf("LookupDriver() failed: %v", err)
+	}
+	content, err := RenderTemplate(templates.FS, "project/di.go.tpl", map[string]any{"Driver": d})
+	if err != nil {
+		t.Fatalf("RenderTemplate() failed: %v", err)
+	}
+	content, err = MergeDI(content, "example.com/shop", wirings)
+	if err != nil {
+		t.Fatalf("MergeDI() failed: %v", err)
+	}
+	return content
+}
+
+func TestMergeDI(t *testing.T) {
+	src := renderDI(t, DriverMySQL, []Wiring{{UseCase: "CreateUser", Entity: "User", Driver: DriverMySQL}})
+
+	merged, err := MergeDI(src, "example.com/shop", []Wiring{
+		{UseCase: "CreateUser", Entity: "User", Driver: DriverMySQL},
+		{UseCase: "CreateOrder", Entity: "Order", Driver: DriverMySQL},
+		{UseCase: "CancelOrder", Entity: "Order", Driver: DriverMySQL},
+	})
+	if err != nil {
+		t.Fatalf("MergeDI() failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"CreateUserHandler  *handlers.CreateUserHandler",
+		"CreateOrderHandler *handlers.CreateOrderHandler",
+		"OrderRepo := mysql.NewOrderMySQLRepo(db)",
+		"CancelOrderUC := usecases.NewCancelOrderUseCase(OrderRepo)",
+		"CancelOrderHandler: CancelOrderHandler,",
+	} {
+		if !strings.Contains(string(merged), want) {
+			t.Errorf("Merged container is missing %q:\n%s", want, merged)
+		}
+	}
+	for _, once := range []string{"UserRepo :=", "OrderRepo :=", "CreateUserUC :="} {
+		if n := strings.Count(string(merged), once); n != 1 {
+			t.Errorf("%q appears %d times, want 1", once, n)
+		}
+	}
+
+	again, err := MergeDI(merged, "example.com/shop", []Wiring{{UseCase: "CancelOrder", Entity: "Order", Driver: DriverMySQL}})
+	if err != nil {
+		t.Fatalf("MergeDI() failed: %v", err)
+	}
+	if !bytes.Equal(again, merged) {
+		t.Errorf("Merging an existing wiring changed the container:\n%s", again)
+	}
+}
+
+func TestMergeDI_OpensNilConnection(t *testing.T) {
+	// The DI container of the first versions declared the connection as nil
+	src := []byte(`package di
+
+import (
+	"database/sql"
+
+	"example.com/shop/infrastructure/database/mysql"
+)
+
+type Container struct {
+	DB *sql.DB
+}
+
+func NewContainer() (*Container, error) {
+	// Initialize database connection (optional for demo)
+	var db *sql.DB = nil
+
+	UserRepo := mysql.NewUserMySQLRepo(db)
+	_ = UserRepo
+
+	return &Container{
+		DB: db,
+	}, nil
+}
+`)
+
+	merged, err := MergeDI(src, "example.com/shop", []Wiring{{UseCase: "CreateOrder", Entity: "Order", Driver: DriverMySQL}})
+	if err != nil {
+		t.Fatalf("MergeDI() failed: %v", err)
+	}
+
+	for _, want := range []string{
+		`db, err := sql.Open("mysql", os.Getenv("MYSQL_DSN"))`,
+		`_ "github.com/go-sql-driver/mysql"`,
+		"OrderRepo := mysql.NewOrderMySQLRepo(db)",
+		"DB:                 db,",
+	} {
+		if !strings.Contains(string(merged), want) {
+			t.Errorf("Merged container is missing %q:\n%s", want, merged)
+		}
+	}
+	for _, unwanted := range []string{"var db *sql.DB", "optional for demo", "MySQLDB"} {
+		if strings.Contains(string(merged), unwanted) {
+			t.Errorf("Merged container still has %q:\n%s", unwanted, merged)
+		}
+	}
+}
+
+func TestMergeDI_KeepsHandWrittenCode(t *testing.T) {
+	src := []byte(`package di
+
+import "log"
+
+type Container struct{ Logger *log.Logger }
+
+func NewContainer() (*Container, error) {
+	var db any
+	logger := log.Default()
+	return &Container{Logger: logger}, nil
+}
+`)
+
+	merged, err := MergeDI(src, "example.com/shop", []Wiring{{UseCase: "CreateUser", Entity: "User", Driver: DriverMySQL}})
+	if err != nil {
+		t.Fatalf("MergeDI() failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"import (\n\t\"log\"\n\n\t\"example.com/shop/infrastructure/database/mysql\"",
+		`"example.com/shop/internal/handlers"`,
+		"Logger            *log.Logger",
+		"logger := log.Default()",
+		"Logger:            logger,",
+		"CreateUserHandler: CreateUserHandler,",
+	} {
+		if !strings.Contains(string(merged), want) {
+			t.Errorf("Merged container is missing %q:\n%s", want, merged)
+		}
+	}
+}
+
+func TestMergeDI_UnknownLayout(t *testing.T) {
+	src := []byte("package di\n\nfunc Build() {}\n")
+	if _, err := MergeDI(src, "example.com/shop", []Wiring{{UseCase: "CreateUser", Entity: "User", Driver: DriverMySQL}}); err == nil {
+		t.Error("Expected an error for a file without Container and NewContainer")
+	}
+}
+
+func TestMergeDI_Postgres(t *testing.T) {
+	src := renderDI(t, DriverPostgres, []Wiring{{UseCase: "CreateUser", Entity: "User", Driver: DriverPostgres}})
+
+	for _, want := range []string{
+		`_ "github.com/jackc/pgx/v5/stdlib"`,
+		`postgresDB, err := sql.Open("pgx", os.Getenv("DATABASE_URL"))`,
+		`"example.com/shop/infrastructure/database/postgres"`,
+		"UserRepo := postgres.NewUserPostgresRepo(postgresDB)",
+		"DB:                postgresDB,",
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("Container is missing %q:\n%s", want, src)
+		}
+	}
+
+	// A container without connection gets the one of the first repository
+	src = []byte(`package di
+
+import "database/sql"
+
+type Container struct {
+	DB *sql.DB
+}
+
+func NewContainer() (*Container, error) {
+	return &Container{}, nil
+}
+`)
+	merged, err := MergeDI(src, "example.com/shop", []Wiring{
+		{UseCase: "CreateUser", Entity: "User", Driver: DriverPostgres},
+		{UseCase: "CreateOrder", Entity: "Order", Driver: DriverPostgres},
+	})
+	if err != nil {
+		t.Fatalf("MergeDI() failed: %v", err)
+	}
+	if n := strings.Count(string(merged), "sql.Open"); n != 1 {
+		t.Errorf("Connection opened %d times, want 1:\n%s", n, merged)
+	}
+	if !strings.Contains(string(merged), "DB:                 postgresDB,") {
+		t.Errorf("Container does not keep the connection:\n%s", merged)
+	}
+
+	// Each driver gets its own connection, closed with the container
+	src = renderDI(t, DriverMySQL, []Wiring{{UseCase: "CreateUser", Entity: "User", Driver: DriverMySQL}})
+	merged, err = MergeDI(src, "example.com/shop", []Wiring{{UseCase: "CreateOrder", Entity: "Order", Driver: DriverPostgres}})
+	if err != nil {
+		t.Fatalf("MergeDI() failed: %v", err)
+	}
+	for _, want := range []string{
+		"PostgresDB         *sql.DB",
+		"UserRepo := mysql.NewUserMySQLRepo(db)",
+		"OrderRepo := postgres.NewOrderPostgresRepo(postgresDB)",
+		"PostgresDB:         postgresDB,",
+		"if err := c.PostgresDB.Close(); err != nil {",
+	} {
+		if !strings.Contains(string(merged), want) {
+			t.Errorf("Container is missing %q:\n%s", want, merged)
+		}
+	}
+
+	if _, err := MergeDI(src, "example.com/shop", []Wiring{{UseCase: "CreateInvoice", Entity: "Invoice", Driver: "oracle"}}); err == nil {
+		t.Error("Expected an error for an unknown driver")
+	}
+}
+
+func TestMergeDI_Memory(t *testing.T) {
+	src := renderDI(t, DriverMemory, []Wiring{{UseCase: "CreateUser", Entity: "User", Driver: DriverMemory}})
+
+	if strings.Contains(string(src), "database/sql") {
+		t.Errorf("Container without database imports database/sql:\n%s", src)
+	}
+	if !strings.Contains(string(src), "UserRepo := memory.NewUserMemoryRepo()") {
+		t.Errorf("Container does not wire the memory repository:\n%s", src)
+	}
+
+	// A database added later gets a connection of its own, closed with the
+	// container
+	merged, err := MergeDI(src, "example.com/shop", []Wiring{{UseCase: "CreateOrder", Entity: "Order", Driver: DriverPostgres}})
+	if err != nil {
+		t.Fatalf("MergeDI() failed: %v", err)
+	}
+	for _, want := range []string{
+		`"database/sql"`,
+		"PostgresDB         *sql.DB",
+		"OrderRepo := postgres.NewOrderPostgresRepo(postgresDB)",
+		"if err := c.PostgresDB.Close(); err != nil {",
+	} {
+		if !strings.Contains(string(merged), want) {
+			t.Errorf("Container is missing %q:\n%s", want, merged)
+		}
+	}
+}
+
+func TestMergeDI_UnitOfWork(t *testing.T) {
+	src := renderDI(t, DriverPostgres, []Wiring{
+		{UseCase: "OpenAccount", Entity: "Account", Driver: DriverPostgres, Tx: true},
+		{UseCase: "Transfer", Entity: "Account", Driver: DriverPostgres, Tx: true},
+		{UseCase: "GetAccount", Entity: "Account", Driver: DriverPostgres},
+	})
+
+	for _, want := range []string{
+		"postgresUoW := postgres.NewUnitOfWork(postgresDB)",
+		"OpenAccountUC := usecases.NewOpenAccountUseCase(AccountRepo, postgresUoW)",
+		"TransferUC := usecases.NewTransferUseCase(AccountRepo, postgresUoW)",
+		"GetAccountUC := usecases.NewGetAccountUseCase(AccountRepo)",
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("Container is missing %q:\n%s", want, src)
+		}
+	}
+	if n := strings.Count(string(src), "NewUnitOfWork"); n != 1 {
+		t.Errorf("Container creates the unit of work %d times, expected once:\n%s", n, src)
+	}
+
+	memory := renderDI(t, DriverMemory, []Wiring{{UseCase: "CreateUser", Entity: "User", Driver: DriverMemory, Tx: true}})
+	if !strings.Contains(string(memory), "memoryUoW := memory.NewUnitOfWork()") {
+		t.Errorf("Container does not create the memory unit of work:\n%s", memory)
+	}
+}
+
+func TestMergeDI_HTTP(t *testing.T) {
+	src := renderDI(t, DriverMemory, []Wiring{
+		{UseCase: "CreateUser", Entity: "User", Driver: DriverMemory, Kind: KindHTTP},
+		{UseCase: "ImportUsers", Entity: "User", Driver: DriverMemory},
+	})
+
+	for _, want := range []string{
+		`"net/http"`,
+		"Router             *http.ServeMux",
+		"router := http.NewServeMux()",
+		"CreateUserHandler.Register(router)",
+		"Router:             router,",
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("Container is missing %q:\n%s", want, src)
+		}
+	}
+	if strings.Contains(string(src), "ImportUsersHandler.Register") {
+		t.Errorf("Console handler is registered on the router:\n%s", src)
+	}
+
+	// New handlers share the router, registered handlers are left alone
+	merged, err := MergeDI(src, "example.com/shop", []Wiring{
+		{UseCase: "CreateUser", Entity: "User", Driver: DriverMemory, Kind: KindHTTP},
+		{UseCase: "GetUser", Entity: "User", Driver: DriverMemory, Kind: KindHTTP},
+	})
+	if err != nil {
+		t.Fatalf("MergeDI() failed: %v", err)
+	}
+	for once, want := range map[string]int{
+		"http.NewServeMux()":                 1,
+		"CreateUserHandler.Register(router)": 1,
+		"GetUserHandler.Register(router)":    1,
+		"Router:":                            1,
+	} {
+		if n := strings.Count(string(merged), once); n != want {
+			t.Errorf("%q appears %d times, want %d:\n%s", once, n, want, merged)
+		}
+	}
+}
+
+func TestMergeDI_Router(t *testing.T) {
+	src := renderDI(t, DriverMemory, []Wiring{
+		{UseCase: "CreateUser", Entity: "User", Driver: DriverMemory, Kind: KindHTTP, Router: RouterGin},
+	})
+
+	for _, want := range []string{
+		`"github.com/gin-gonic/gin"`,
+		"Router            *gin.Engine",
+		"router := gin.New()",
+		"CreateUserHandler.Register(router)",
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("Container is missing %q:\n%s", want, src)
+		}
+	}
+	if strings.Contains(string(src), "net/http") {
+		t.Errorf("Container imports net/http for a gin router:\n%s", src)
+	}
+}
+
+func TestMergeDI_GRPC(t *testing.T) {
+	src := renderDI(t, DriverMemory, []Wiring{
+		{UseCase: "CreateUser", Entity: "User", Driver: DriverMemory, Kind: KindGRPC},
+		{UseCase: "ListUsers", Entity: "User", Driver: DriverMemory, Kind: KindHTTP},
+	})
+
+	for _, want := range []string{
+		`"google.golang.org/grpc"`,
+		`grpchandlers "example.com/shop/internal/handlers/grpc"`,
+		"CreateUserHandler := grpchandlers.NewCreateUserHandler(CreateUserUC)",
+		"grpcServer := grpc.NewServer()",
+		"CreateUserHandler.Register(grpcServer)",
+		"ListUsersHandler.Register(router)",
+		"GRPCServer: ",
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("Container is missing %q:\n%s", want, src)
+		}
+	}
+
+	merged, err := MergeDI(src, "example.com/shop", []Wiring{
+		{UseCase: "GetUser", Entity: "User", Driver: DriverMemory, Kind: KindGRPC},
+	})
+	if err != nil {
+		t.Fatalf("MergeDI() failed: %v", err)
+	}
+	for once, want := range map[string]int{
+		"grpc.NewServer()":                    1,
+		"GetUserHandler.Register(grpcServer)": 1,
+		"*grpchandlers.GetUserHandler":        1,
+		"GRPCServer:":                         1,
+	} {
+		if n := strings.Count(string(merged), once); n != want {
+			t.Errorf("%q appears %d times, want %d:\n%s", once, n, want, merged)
+		}
+	}
+}
+
+func TestTakesUnitOfWork(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "transfer_usecase.go")
+	os.WriteFile(path, []byte(`package usecases
+
+func NewTransferUseCase(repo repository.AccountRepository, uow repository.UnitOfWork) *TransferUseCase {
+	return &TransferUseCase{Repo: repo, UoW: uow}
+}
+
+func NewOtherUseCase(repo repository.AccountRepository) *OtherUseCase {
+	return &OtherUseCase{Repo: repo}
+}
+`), 0644)
+
+	tests := []struct {
+		useCase  string
+		expected bool
+	}{
+		{"Transfer", true},
+		{"Other", false},
+		{"Missing", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.useCase, func(t *testing.T) {
+			tx, err := TakesUnitOfWork(path, tt.useCase)
+			if err != nil {
+				t.Fatalf("TakesUnitOfWork() failed: %v", err)
+			}
+			if tx != tt.expected {
+				t.Errorf("TakesUnitOfWork() = %v, expected %v", tx, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConnectionField(t *testing.T) {
+	src := renderDI(t, DriverMySQL, []Wiring{
+		{UseCase: "CreateUser", Entity: "User", Driver: DriverMySQL},
+		{UseCase: "CreateOrder", Entity: "Order", Driver: DriverPostgres},
+	})
+
+	tests := []struct {
+		conn     string
+		expected string
+	}{
+		{"db", "DB"},
+		{"postgresDB", "PostgresDB"},
+		{"sqliteDB", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.conn, func(t *testing.T) {
+			field, err := ConnectionField(src, tt.conn)
+			if err != nil {
+				t.Fatalf("ConnectionField() failed: %v", err)
+			}
+			if field != tt.expected {
+				t.Errorf("ConnectionField() = %q, expected %q", field, tt.expected)
+			}
+		})
+	}
+}
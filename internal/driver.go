@@ -0,0 +1,122 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Database drivers repositories can be generated for
+const (
+	DriverMySQL    = "mysql"
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+	DriverMemory   = "memory"
+)
+
+// Driver describes how the repositories of a database are generated and
+// how the DI container connects to it
+type Driver struct {
+	Name       string   // value of --driver and database.driver, e.g. postgres
+	Package    string   // package under infrastructure/database
+	Type       string   // infix of the repository type, e.g. UserPostgresRepo
+	Template   string   // repository implementation template
+	UnitOfWork string   // unit of work implementation template
+	Dialect    Dialect  // SQL flavour the queries are written in, if any
+	Module     string   // Go module providing the database/sql driver
+	SQLDriver  string   // package registering the database/sql driver
+	Imports    []string // standard library imports used by Setup
+	Conn       string   // variable holding the connection in NewContainer, if any
+	Setup      string   // statements declaring Conn in NewContainer
+}
+
+var drivers = []Driver{
+	{
+		Name:       DriverMySQL,
+		Package:    "mysql",
+		Type:       "MySQL",
+		Template:   "repository/repo_sql.go.tpl",
+		UnitOfWork: "repository/uow_sql.go.tpl",
+		Dialect:    DialectMySQL,
+		Module:     "github.com/go-sql-driver/mysql",
+		SQLDriver:  "github.com/go-sql-driver/mysql",
+		Imports:    []string{"fmt", "os"},
+		Conn:       "db",
+		Setup: `// Open the MySQL connection configured in MYSQL_DSN, e.g.
+// user:password@tcp(localhost:3306)/app?parseTime=true
+db, err := sql.Open("mysql", os.Getenv("MYSQL_DSN"))
+if err != nil {
+	return nil, fmt.Errorf("failed to open database: %w", err)
+}`,
+	},
+	{
+		Name:       DriverPostgres,
+		Package:    "postgres",
+		Type:       "Postgres",
+		Template:   "repository/repo_sql.go.tpl",
+		UnitOfWork: "repository/uow_sql.go.tpl",
+		Dialect:    DialectPostgres,
+		Module:     "github.com/jackc/pgx/v5",
+		SQLDriver:  "github.com/jackc/pgx/v5/stdlib",
+		Imports:    []string{"fmt", "os"},
+		Conn:       "postgresDB",
+		Setup: `// Open the PostgreSQL connection configured in DATABASE_URL
+postgresDB, err := sql.Open("pgx", os.Getenv("DATABASE_URL"))
+if err != nil {
+	return nil, fmt.Errorf("failed to open database: %w", err)
+}`,
+	},
+	{
+		Name:       DriverSQLite,
+		Package:    "sqlite",
+		Type:       "SQLite",
+		Template:   "repository/repo_sql.go.tpl",
+		UnitOfWork: "repository/uow_sql.go.tpl",
+		Dialect:    DialectSQLite,
+		Module:     "modernc.org/sqlite",
+		SQLDriver:  "modernc.org/sqlite",
+		Imports:    []string{"fmt", "os"},
+		Conn:       "sqliteDB",
+		Setup: `// Open the SQLite file configured in SQLITE_PATH, app.db by default
+sqlitePath := os.Getenv("SQLITE_PATH")
+if sqlitePath == "" {
+	sqlitePath = "app.db"
+}
+sqliteDB, err := sql.Open("sqlite", sqlitePath)
+if err != nil {
+	return nil, fmt.Errorf("failed to open database: %w", err)
+}
+// SQLite allows a single writer at a time
+sqliteDB.SetMaxOpenConns(1)`,
+	},
+	{
+		Name:       DriverMemory,
+		Package:    "memory",
+		Type:       "Memory",
+		Template:   "repository/repo_memory.go.tpl",
+		UnitOfWork: "repository/uow_memory.go.tpl",
+	},
+}
+
+// LookupDriver returns the driver called name
+func LookupDriver(name string) (Driver, error) {
+	for _, d := range drivers {
+		if d.Name == name {
+			return d, nil
+		}
+	}
+	return Driver{}, fmt.Errorf("unknown driver %q, expected one of %s", name, strings.Join(DriverNames(), ", "))
+}
+
+// DriverNames lists the supported drivers
+func DriverNames() []string {
+	names := make([]string, len(drivers))
+	for i, d := range drivers {
+		names[i] = d.Name
+	}
+	return names
+}
+
+// RepoType returns the name of the repository implementation of entity
+func (d Driver) RepoType(entity string) string {
+	return ToPascalCase(entity) + d.Type + "Repo"
+}
@@ -0,0 +1,319 @@
+package internal
+
+import (
+	"fmt"
+	"go/ast"
+	"strings"
+)
+
+// MergeMain makes the main function in src run the handler of every use case
+// it does not run yet. The DI container is set up first when main does not
+// create one. With withContext the handlers get the ctx variable of main,
+// declared as context.Background() when missing. main then serves the
+// handlers of the kinds in serve it does not serve yet (see mainServers): the
+// first server runs until main ends, the ones added next to it in the
+// background. The rest of main, and of the file, is left untouched.
+func MergeMain(src []byte, module, projectName string, useCases []string, withContext bool, serve []string) ([]byte, error) {
+	p, err := NewPatcher("main.go", src)
+	if err != nil {
+		return nil, err
+	}
+
+	fn := p.Func("main")
+	if fn == nil || fn.Body == nil {
+		return nil, fmt.Errorf("no main function to add the handlers to")
+	}
+
+	container, anchor := containerVar(fn.Body)
+	running := map[string]bool{}
+	if container != "" {
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			if sel, ok := n.(*ast.SelectorExpr); ok {
+				if ident, ok := sel.X.(*ast.Ident); ok && ident.Name == container {
+					running[sel.Sel.Name] = true
+				}
+			}
+			return true
+		})
+	}
+
+	// The container is created when anything is added to main
+	missing := container == ""
+	if missing {
+		container = "container"
+	}
+
+	var code strings.Builder
+
+	declared := DeclaredNames(fn.Body)
+	for _, uc := range useCases {
+		handler := ToPascalCase(uc) + "Handler"
+		if running[handler] {
+			continue
+		}
+		args := ""
+		if withContext {
+			if !declared["ctx"] {
+				code.WriteString("\nctx := context.Background()\n")
+				p.AddImport("context")
+				declared["ctx"] = true
+			}
+			args = "ctx"
+		}
+		fmt.Fprintf(&code, `
+if err := %s.%s.Run(%s); err != nil {
+	log.Fatalf("Failed to execute handler: %%v", err)
+}
+`, container, handler, args)
+		p.AddImport("log")
+		running[handler] = true
+	}
+
+	// Servers started next to the one main ends with, and waited for after it
+	foreground := servingStmt(fn.Body)
+	var start, fore, wait strings.Builder
+	for _, kind := range serve {
+		srv, ok := mainServers[kind]
+		if !ok || Uses(fn.Body, srv.Func) || kind == KindHTTP && Uses(fn.Body, "ListenAndServe") {
+			continue
+		}
+		if foreground == nil && fore.Len() == 0 {
+			fmt.Fprintf(&fore, serveCode, container, srv.Func, srv.Field, srv.Foreground, srv.Failure)
+		} else {
+			fmt.Fprintf(&start, serveBackgroundCode, container, srv.Func, srv.Field, srv.Background, srv.Done)
+			fmt.Fprintf(&wait, waitCode, container, srv.Done, srv.Failure)
+		}
+		p.AddImport("log")
+	}
+	if foreground != nil {
+		if start.Len() > 0 {
+			p.InsertAbove(foreground, strings.TrimPrefix(start.String(), "\n")+"\n")
+			p.InsertAfter(foreground, wait.String())
+		}
+	} else if fore.Len() > 0 {
+		p.Insert(fn.Body.Rbrace, start.String()+fore.String()+wait.String())
+	}
+
+	if code.Len() == 0 && !p.Changed() {
+		return src, nil
+	}
+
+	if missing {
+		init := `// Initialize dependencies
+container, err := di.NewContainer()
+if err != nil {
+	log.Fatalf("Failed to initialize dependencies: %v", err)
+}
+defer container.Close()
+`
+		rest := code.String()
+		code.Reset()
+		code.WriteString(init + rest)
+		p.AddImport(module + "/cmd/" + projectName + "/di")
+		p.AddImport("log")
+	}
+
+	if code.Len() > 0 {
+		if anchor == nil {
+			p.Insert(fn.Body.Lbrace+1, "\n"+code.String())
+		} else {
+			p.InsertAfter(anchor, code.String())
+		}
+	}
+
+	return p.Bytes()
+}
+
+// mainServer is a server main runs for the handlers of a kind, with a
+// function generated next to main.go
+type mainServer struct {
+	Func       string // function serving the field until SIGINT or SIGTERM
+	Field      string // field of the DI container the handlers are registered on
+	Foreground string // comment of the code running the function until main ends
+	Background string // comment of the code running it next to another server
+	Done       string // channel the function returns to in the background
+	Failure    string // message main exits with when the function fails
+}
+
+// mainServers are the servers of the handler kinds main serves. The CLI is
+// not a server, but it runs like one until its command ends.
+var mainServers = map[string]mainServer{
+	KindHTTP: {
+		"serve", "Router",
+		"Serve the routes of the HTTP handlers until SIGINT or SIGTERM, see\n// server.go",
+		"Serve the routes of the HTTP handlers in the background too, see server.go",
+		"httpDone", "Server failed",
+	},
+	KindGRPC: {
+		"serveGRPC", "GRPCServer",
+		"Serve the services of the gRPC handlers until SIGINT or SIGTERM, see\n// grpc_server.go",
+		"Serve the services of the gRPC handlers in the background too, see\n// grpc_server.go",
+		"grpcDone", "gRPC server failed",
+	},
+	KindCLI: {
+		"execute", "CLI",
+		"Execute the command of the CLI handlers given in the arguments, see\n// cli.go",
+		"Execute the command of the CLI handlers in the background, see cli.go",
+		"cliDone", "Command failed",
+	},
+	KindConsumer: {
+		"consume", "Broker",
+		"Consume the messages of the consumer handlers until SIGINT or SIGTERM,\n// see consumer.go",
+		"Consume the messages of the consumer handlers in the background too, see\n// consumer.go",
+		"consumerDone", "Consumer failed",
+	},
+}
+
+// serveCode runs %[2]s on the field %[3]s of the DI container %[1]s until
+// main ends
+const serveCode = `
+// %[4]s
+if err := %[2]s(%[1]s.%[3]s); err != nil {
+	%[1]s.Close()
+	log.Fatalf("%[5]s: %%v", err)
+}
+`
+
+// serveBackgroundCode runs %[2]s like serveCode, next to another server
+const serveBackgroundCode = `
+// %[4]s
+%[5]s := make(chan error, 1)
+go func() { %[5]s <- %[2]s(%[1]s.%[3]s) }()
+`
+
+// waitCode waits for a server started with serveBackgroundCode
+const waitCode = `
+if err := <-%[2]s; err != nil {
+	%[1]s.Close()
+	log.Fatalf("%[3]s: %%v", err)
+}
+`
+
+// startsServing reports whether stmt is part of the code of main serving
+// handlers, which runs last
+func startsServing(stmt ast.Stmt) bool {
+	if servingStmt(&ast.BlockStmt{List: []ast.Stmt{stmt}}) != nil || Uses(stmt, "http") {
+		return true
+	}
+	for _, srv := range mainServers {
+		if Uses(stmt, srv.Done) {
+			return true
+		}
+	}
+	return false
+}
+
+// servingStmt returns the statement of main running one of the servers of
+// mainServers until main ends, or nil
+func servingStmt(body *ast.BlockStmt) ast.Stmt {
+	for _, stmt := range body.List {
+		check, ok := stmt.(*ast.IfStmt)
+		if !ok || check.Init == nil {
+			continue
+		}
+		for _, srv := range mainServers {
+			if Uses(check.Init, srv.Func) {
+				return stmt
+			}
+		}
+	}
+	return nil
+}
+
+// MergeMigrations makes the main function in src apply the pending database
+// migrations right after creating the DI container, on the connection held
+// by its field conn. main is left as it is when it applies them already.
+func MergeMigrations(src []byte, module, conn string) ([]byte, error) {
+	p, err := NewPatcher("main.go", src)
+	if err != nil {
+		return nil, err
+	}
+
+	fn := p.Func("main")
+	if fn == nil || fn.Body == nil {
+		return nil, fmt.Errorf("no main function to apply the migrations in")
+	}
+	if Uses(fn.Body, "migrate") {
+		return src, nil
+	}
+
+	// After the container is created, checked and its Close deferred
+	var container string
+	var anchor ast.Stmt
+	for _, stmt := range fn.Body.List {
+		if container == "" {
+			if assign, ok := stmt.(*ast.AssignStmt); ok && isNewContainer(assign) {
+				container = assign.Lhs[0].(*ast.Ident).Name
+				anchor = stmt
+			}
+			continue
+		}
+		if check, ok := stmt.(*ast.IfStmt); ok && check.Init == nil && Uses(check.Cond, "err") {
+			anchor = stmt
+			continue
+		}
+		if deferred, ok := stmt.(*ast.DeferStmt); ok && Uses(deferred, container) {
+			anchor = stmt
+			continue
+		}
+		break
+	}
+	if container == "" {
+		return nil, fmt.Errorf("main does not create the DI container")
+	}
+
+	p.InsertAfter(anchor, fmt.Sprintf(`
+// Apply the pending database migrations
+if err := migrate.Up(context.Background(), %s.%s, migrations.FS); err != nil {
+	log.Fatalf("Failed to apply migrations: %%v", err)
+}
+`, container, conn))
+	p.AddImport("context")
+	p.AddImport("log")
+	p.AddImport(module + "/infrastructure/database/migrate")
+	p.AddImport(module + "/migrations")
+
+	return p.Bytes()
+}
+
+// containerVar returns the variable main stores the DI container in and the
+// last statement using it, where new handlers are run. Handlers run before
+// main starts serving HTTP.
+func containerVar(body *ast.BlockStmt) (string, ast.Stmt) {
+	var name string
+	var last ast.Stmt
+	for _, stmt := range body.List {
+		if name == "" {
+			if assign, ok := stmt.(*ast.AssignStmt); ok && isNewContainer(assign) {
+				name = assign.Lhs[0].(*ast.Ident).Name
+			}
+		}
+		if name != "" && startsServing(stmt) {
+			break
+		}
+		if name != "" && Uses(stmt, name) {
+			last = stmt
+		}
+	}
+	return name, last
+}
+
+// isNewContainer matches `container, err := di.NewContainer()`
+func isNewContainer(assign *ast.AssignStmt) bool {
+	if len(assign.Rhs) != 1 || len(assign.Lhs) == 0 {
+		return false
+	}
+	if _, ok := assign.Lhs[0].(*ast.Ident); !ok {
+		return false
+	}
+	call, ok := assign.Rhs[0].(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "NewContainer" {
+		return false
+	}
+	pkg, ok := sel.X.(*ast.Ident)
+	return ok && pkg.Name == "di"
+}
@@ -0,0 +1,255 @@
+package internal
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestMergeMain(t *testing.T) {
+	src := []byte(`package main
+
+import (
+	"log"
+
+	"example.com/shop/cmd/shop/di"
+)
+
+func main() {
+	app, err := di.NewContainer()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer app.Close()
+
+	if err := app.CreateUserHandler.Run(); err != nil {
+		log.Fatal(err)
+	}
+
+	// Custom code stays where it is
+	log.Println("bye")
+}
+`)
+
+	merged, err := MergeMain(src, "example.com/shop", "shop", []string{"CreateUser", "CreateOrder"}, false, nil)
+	if err != nil {
+		t.Fatalf("MergeMain() failed: %v", err)
+	}
+
+	got := string(merged)
+	if strings.Count(got, "CreateUserHandler.Run()") != 1 {
+		t.Errorf("CreateUser handler should run once:\n%s", got)
+	}
+	run := strings.Index(got, "app.CreateOrderHandler.Run()")
+	if run < 0 || run < strings.Index(got, "app.CreateUserHandler") || run > strings.Index(got, "// Custom code") {
+		t.Errorf("CreateOrder handler should run after the existing handlers:\n%s", got)
+	}
+
+	again, err := MergeMain(merged, "example.com/shop", "shop", []string{"CreateOrder"}, false, nil)
+	if err != nil {
+		t.Fatalf("MergeMain() failed: %v", err)
+	}
+	if !bytes.Equal(again, merged) {
+		t.Errorf("Merging a running handler changed main.go:\n%s", again)
+	}
+}
+
+func TestMergeMain_SetsUpContainer(t *testing.T) {
+	src := []byte("package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"hi\")\n}\n")
+
+	merged, err := MergeMain(src, "example.com/shop", "shop", []string{"CreateUser"}, false, nil)
+	if err != nil {
+		t.Fatalf("MergeMain() failed: %v", err)
+	}
+
+	for _, want := range []string{
+		`"example.com/shop/cmd/shop/di"`,
+		`"log"`,
+		"container, err := di.NewContainer()",
+		"defer container.Close()",
+		"container.CreateUserHandler.Run()",
+		`fmt.Println("hi")`,
+	} {
+		if !strings.Contains(string(merged), want) {
+			t.Errorf("main.go is missing %q:\n%s", want, merged)
+		}
+	}
+
+	if _, err := MergeMain([]byte("package main\n"), "example.com/shop", "shop", []string{"CreateUser"}, false, nil); err == nil {
+		t.Error("Expected an error for a file without main")
+	}
+}
+
+func TestMergeMain_Context(t *testing.T) {
+	src := []byte("package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"hi\")\n}\n")
+
+	merged, err := MergeMain(src, "example.com/shop", "shop", []string{"CreateUser", "CreateOrder"}, true, nil)
+	if err != nil {
+		t.Fatalf("MergeMain() failed: %v", err)
+	}
+	for _, want := range []string{
+		`"context"`,
+		"ctx := context.Background()",
+		"container.CreateUserHandler.Run(ctx)",
+		"container.CreateOrderHandler.Run(ctx)",
+	} {
+		if !strings.Contains(string(merged), want) {
+			t.Errorf("main.go is missing %q:\n%s", want, merged)
+		}
+	}
+	if n := strings.Count(string(merged), "ctx :="); n != 1 {
+		t.Errorf("ctx is declared %d times, want 1:\n%s", n, merged)
+	}
+
+	// The ctx main already has is passed along
+	again, err := MergeMain(merged, "example.com/shop", "shop", []string{"CancelOrder"}, true, nil)
+	if err != nil {
+		t.Fatalf("MergeMain() failed: %v", err)
+	}
+	if n := strings.Count(string(again), "ctx :="); n != 1 || !strings.Contains(string(again), "container.CancelOrderHandler.Run(ctx)") {
+		t.Errorf("main.go does not reuse ctx:\n%s", again)
+	}
+}
+
+func TestMergeMain_Serve(t *testing.T) {
+	src := []byte(`package main
+
+import (
+	"log"
+
+	"example.com/shop/cmd/shop/di"
+)
+
+func main() {
+	app, err := di.NewContainer()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer app.Close()
+}
+`)
+
+	merged, err := MergeMain(src, "example.com/shop", "shop", nil, false, []string{KindHTTP})
+	if err != nil {
+		t.Fatalf("MergeMain() failed: %v", err)
+	}
+	for _, want := range []string{
+		"if err := serve(app.Router); err != nil {",
+		"app.Close()\n\t\tlog.Fatalf(\"Server failed: %v\", err)",
+	} {
+		if !strings.Contains(string(merged), want) {
+			t.Errorf("main.go is missing %q:\n%s", want, merged)
+		}
+	}
+
+	// Console handlers added later run before the server starts
+	again, err := MergeMain(merged, "example.com/shop", "shop", []string{"ImportUsers"}, false, []string{KindHTTP})
+	if err != nil {
+		t.Fatalf("MergeMain() failed: %v", err)
+	}
+	run := strings.Index(string(again), "app.ImportUsersHandler.Run()")
+	serve := strings.Index(string(again), "serve(app.Router)")
+	if run < 0 || run > serve {
+		t.Errorf("The handler does not run before the server starts:\n%s", again)
+	}
+	if n := strings.Count(string(again), "serve("); n != 1 {
+		t.Errorf("main.go serves %d times, want once:\n%s", n, again)
+	}
+}
+
+func TestMergeMain_ServeBoth(t *testing.T) {
+	src := []byte(`package main
+
+func main() {
+}
+`)
+
+	merged, err := MergeMain(src, "example.com/shop", "shop", nil, false, []string{KindHTTP, KindGRPC})
+	if err != nil {
+		t.Fatalf("MergeMain() failed: %v", err)
+	}
+	for _, want := range []string{
+		"container, err := di.NewContainer()",
+		"grpcDone := make(chan error, 1)",
+		"go func() { grpcDone <- serveGRPC(container.GRPCServer) }()",
+		"if err := serve(container.Router); err != nil {",
+		"if err := <-grpcDone; err != nil {",
+	} {
+		if !strings.Contains(string(merged), want) {
+			t.Errorf("main.go is missing %q:\n%s", want, merged)
+		}
+	}
+
+	// A server added later starts before the one main already serves, above
+	// its comment
+	httpOnly, err := MergeMain(src, "example.com/shop", "shop", nil, false, []string{KindHTTP})
+	if err != nil {
+		t.Fatalf("MergeMain() failed: %v", err)
+	}
+	again, err := MergeMain(httpOnly, "example.com/shop", "shop", nil, false, []string{KindGRPC, KindHTTP})
+	if err != nil {
+		t.Fatalf("MergeMain() failed: %v", err)
+	}
+	start := strings.Index(string(again), "go func() { grpcDone <- serveGRPC(container.GRPCServer) }()")
+	comment := strings.Index(string(again), "// Serve the routes of the HTTP handlers")
+	wait := strings.Index(string(again), "<-grpcDone")
+	if start < 0 || start > comment || wait < comment {
+		t.Errorf("The gRPC server is not started before the HTTP one and waited for after it:\n%s", again)
+	}
+	if n := strings.Count(string(again), "serve(container.Router)"); n != 1 {
+		t.Errorf("main.go serves HTTP %d times, want once:\n%s", n, again)
+	}
+}
+
+func TestMergeMigrations(t *testing.T) {
+	src := []byte(`package main
+
+import (
+	"log"
+
+	"example.com/shop/cmd/shop/di"
+)
+
+func main() {
+	app, err := di.NewContainer()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer app.Close()
+
+	if err := app.CreateUserHandler.Run(); err != nil {
+		log.Fatal(err)
+	}
+}
+`)
+
+	merged, err := MergeMigrations(src, "example.com/shop", "PostgresDB")
+	if err != nil {
+		t.Fatalf("MergeMigrations() failed: %v", err)
+	}
+	for _, want := range []string{
+		`"example.com/shop/infrastructure/database/migrate"`,
+		`"example.com/shop/migrations"`,
+		"defer app.Close()\n\n\t// Apply the pending database migrations\n\tif err := migrate.Up(context.Background(), app.PostgresDB, migrations.FS); err != nil {",
+	} {
+		if !strings.Contains(string(merged), want) {
+			t.Errorf("main.go is missing %q:\n%s", want, merged)
+		}
+	}
+	if strings.Index(string(merged), "migrate.Up") > strings.Index(string(merged), "CreateUserHandler") {
+		t.Errorf("Migrations must be applied before the handlers run:\n%s", merged)
+	}
+
+	// Applying the migrations once is enough
+	again, err := MergeMigrations(merged, "example.com/shop", "PostgresDB")
+	if err != nil {
+		t.Fatalf("MergeMigrations() failed: %v", err)
+	}
+	if !bytes.Equal(again, merged) {
+		t.Errorf("Merging twice changed main.go:\n%s", again)
+	}
+
+	if _, err := MergeMigrations([]byte("package main\n\nfunc main() {}\n"), "example.com/shop", "DB"); err == nil {
+		t.Error("MergeMigrations() should fail when main has no DI container")
+	}
+}
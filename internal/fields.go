@@ -0,0 +1,511 @@
+package internal
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Field describes a single entity field
+type Field struct {
+	Name     string   // Go identifier, e.g. CreatedAt
+	Column   string   // snake_case name, e.g. created_at
+	Type     string   // Go type expression, e.g. []string or *time.Time
+	Imports  []string // import paths the type needs
+	Optional bool     // declared with a trailing `?`
+	Tags     []Tag    // struct tags, in the order they are written
+}
+
+// Tag is a single struct tag such as json:"email,omitempty"
+type Tag struct {
+	Key   string
+	Value string
+}
+
+// Fields is the schema of an entity shared by every template
+type Fields []Field
+
+// knownImports maps package names that can be used in field types without
+// spelling out their import path
+var knownImports = map[string]string{
+	"big":     "math/big",
+	"decimal": "github.com/shopspring/decimal",
+	"json":    "encoding/json",
+	"netip":   "net/netip",
+	"sql":     "database/sql",
+	"time":    "time",
+	"url":     "net/url",
+	"uuid":    "github.com/google/uuid",
+}
+
+// commonInitialisms are kept upper case in Go identifiers (user_id -> UserID)
+var commonInitialisms = map[string]bool{
+	"API": true, "CPU": true, "CSS": true, "DB": true, "DNS": true, "HTML": true,
+	"HTTP": true, "HTTPS": true, "ID": true, "IP": true, "JSON": true, "SQL": true,
+	"TCP": true, "TLS": true, "UDP": true, "UI": true, "URI": true, "URL": true,
+	"UUID": true, "XML": true,
+}
+
+// qualifiedType matches a type spelled with its full import path, e.g.
+// github.com/google/uuid.UUID
+var qualifiedType = regexp.MustCompile(`((?:[\w.-]+/)+([\w-]+))\.(\w+)`)
+
+// DefaultFields is the schema used by `make entity` when no field is given
+func DefaultFields() Fields {
+	return Fields{
+		{Name: "ID", Column: "id", Type: "string"},
+		{Name: "Name", Column: "name", Type: "string"},
+	}
+}
+
+// ParseFields parses `name:type` definitions such as `price:float64`,
+// `tags:[]string`, `created_at:time.Time`, `nickname?:string` or
+// `email:string:json=email_address,omitempty`. An ID string field is added
+// first when the definitions do not declare one.
+func ParseFields(defs []string) (Fields, error) {
+	var fields Fields
+	for _, def := range defs {
+		field, err := ParseField(def)
+		if err != nil {
+			return nil, err
+		}
+		if fields.Get(field.Name) != nil {
+			return nil, fmt.Errorf("field %q is defined more than once", field.Name)
+		}
+		fields = append(fields, field)
+	}
+
+	if fields.Get("ID") == nil {
+		fields = append(Fields{{Name: "ID", Column: "id", Type: "string"}}, fields...)
+	}
+
+	return fields, nil
+}
+
+// ParseField parses a single `name:type[:tag=value...]` definition. A
+// trailing `?` on the name marks the field as optional, which makes scalar
+// types pointers. Every tag=value segment overrides a generated struct tag.
+func ParseField(def string) (Field, error) {
+	parts := strings.Split(def, ":")
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return Field{}, fmt.Errorf("invalid field %q, expected name:type", def)
+	}
+
+	name, optional := strings.CutSuffix(parts[0], "?")
+	words := splitWords(name)
+	if len(words) == 0 || !unicode.IsLetter(rune(words[0][0])) {
+		return Field{}, fmt.Errorf("invalid field name %q", parts[0])
+	}
+
+	typ, imports, err := parseFieldType(parts[1])
+	if err != nil {
+		return Field{}, fmt.Errorf("field %q: %w", name, err)
+	}
+
+	if optional && !isNillable(typ) {
+		typ = "*" + typ
+	}
+
+	var tags []Tag
+	for _, override := range parts[2:] {
+		key, value, ok := strings.Cut(override, "=")
+		if !ok || key == "" || strings.ContainsAny(key, " `\"") || strings.ContainsAny(value, "`\"") {
+			return Field{}, fmt.Errorf("field %q: invalid tag %q, expected key=value", name, override)
+		}
+		tags = append(tags, Tag{Key: key, Value: value})
+	}
+
+	field := Field{
+		Name:     goName(words),
+		Column:   strings.ToLower(strings.Join(words, "_")),
+		Type:     typ,
+		Imports:  imports,
+		Optional: optional,
+		Tags:     tags,
+	}
+	field.columnFromTag()
+
+	return field, nil
+}
+
+// parseFieldType validates a Go type expression and resolves the packages it
+// uses to import paths
+func parseFieldType(typ string) (string, []string, error) {
+	var imports []string
+	typ = qualifiedType.ReplaceAllStringFunc(typ, func(match string) string {
+		m := qualifiedType.FindStringSubmatch(match)
+		imports = append(imports, m[1])
+		return m[2] + "." + m[3]
+	})
+
+	expr, err := parser.ParseExpr(typ)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid type %q", typ)
+	}
+
+	var walk func(ast.Expr) error
+	walk = func(e ast.Expr) error {
+		switch t := e.(type) {
+		case *ast.Ident:
+			// Types of other packages are qualified, bare names are predeclared
+			if _, ok := types.Universe.Lookup(t.Name).(*types.TypeName); !ok || t.Name == "comparable" {
+				return fmt.Errorf("unknown type %q, use a predeclared type or a qualified one such as time.Time", t.Name)
+			}
+			return nil
+		case *ast.StarExpr:
+			return walk(t.X)
+		case *ast.ArrayType:
+			return walk(t.Elt)
+		case *ast.MapType:
+			if err := walk(t.Key); err != nil {
+				return err
+			}
+			return walk(t.Value)
+		case *ast.SelectorExpr:
+			pkg, ok := t.X.(*ast.Ident)
+			if !ok {
+				return fmt.Errorf("invalid type %q", typ)
+			}
+			if slices.ContainsFunc(imports, func(p string) bool { return path.Base(p) == pkg.Name }) {
+				return nil
+			}
+			importPath, ok := knownImports[pkg.Name]
+			if !ok {
+				return fmt.Errorf("unknown package %q, use its import path (e.g. example.com/%s.Type)", pkg.Name, pkg.Name)
+			}
+			imports = append(imports, importPath)
+			return nil
+		default:
+			return fmt.Errorf("unsupported type %q", typ)
+		}
+	}
+
+	if err := walk(expr); err != nil {
+		return "", nil, err
+	}
+
+	return types.ExprString(expr), imports, nil
+}
+
+// LoadEntityFields reads the fields of struct name declared in the Go file at
+// filePath. It returns nil fields when the struct is not declared there.
+func LoadEntityFields(filePath, name string) (Fields, error) {
+	return parseEntityFields(filePath, nil, name)
+}
+
+// ParseEntityFields is LoadEntityFields for the source of a Go file that is
+// not written yet
+func ParseEntityFields(src []byte, name string) (Fields, error) {
+	return parseEntityFields("", src, name)
+}
+
+// parseEntityFields reads the fields of struct name from src, or from the
+// file at filePath when src is nil
+func parseEntityFields(filePath string, src []byte, name string) (Fields, error) {
+	var source any
+	if src != nil {
+		source = src
+	}
+	file, err := parser.ParseFile(token.NewFileSet(), filePath, source, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// package name -> import path
+	imports := map[string]string{}
+	for _, spec := range file.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		pkg := path.Base(importPath)
+		if spec.Name != nil {
+			pkg = spec.Name.Name
+		}
+		imports[pkg] = importPath
+	}
+
+	var fields Fields
+	var found bool
+	ast.Inspect(file, func(n ast.Node) bool {
+		spec, ok := n.(*ast.TypeSpec)
+		if !ok || spec.Name.Name != name {
+			return !found
+		}
+		st, ok := spec.Type.(*ast.StructType)
+		if !ok {
+			return false
+		}
+		found = true
+
+		for _, f := range st.Fields.List {
+			typ := types.ExprString(f.Type)
+
+			var fieldImports []string
+			ast.Inspect(f.Type, func(n ast.Node) bool {
+				if sel, ok := n.(*ast.SelectorExpr); ok {
+					if pkg, ok := sel.X.(*ast.Ident); ok && imports[pkg.Name] != "" {
+						fieldImports = append(fieldImports, imports[pkg.Name])
+					}
+				}
+				return true
+			})
+
+			var tags []Tag
+			if f.Tag != nil {
+				raw, _ := strconv.Unquote(f.Tag.Value)
+				tags = parseStructTag(raw)
+			}
+
+			// Embedded fields are not part of the schema
+			for _, ident := range f.Names {
+				field := Field{
+					Name:     ident.Name,
+					Column:   strings.ToLower(strings.Join(splitWords(ident.Name), "_")),
+					Type:     typ,
+					Imports:  fieldImports,
+					Optional: strings.HasPrefix(typ, "*"),
+					Tags:     tags,
+				}
+				field.columnFromTag()
+				fields = append(fields, field)
+			}
+		}
+		return false
+	})
+
+	return fields, nil
+}
+
+// Get returns the field with the given Go name, or nil
+func (fs Fields) Get(name string) *Field {
+	for i := range fs {
+		if fs[i].Name == name {
+			return &fs[i]
+		}
+	}
+	return nil
+}
+
+// HasString reports whether the schema has a string field with the given name
+func (fs Fields) HasString(name string) bool {
+	f := fs.Get(name)
+	return f != nil && f.Type == "string"
+}
+
+// Imports returns the sorted import paths needed by all field types
+func (fs Fields) Imports() []string {
+	var imports []string
+	for _, f := range fs {
+		for _, imp := range f.Imports {
+			if !slices.Contains(imports, imp) {
+				imports = append(imports, imp)
+			}
+		}
+	}
+	slices.Sort(imports)
+	return imports
+}
+
+// Required reports whether the field must have a value. ID is excluded
+// because it is usually assigned by the use case or the database.
+func (f Field) Required() bool {
+	return !f.Optional && f.Name != "ID" && f.EmptyCheck("") != ""
+}
+
+// EmptyCheck returns a Go condition that is true when the field of recv has
+// no value, or an empty string for types where the zero value is valid
+func (f Field) EmptyCheck(recv string) string {
+	ref := recv + "." + f.Name
+	switch {
+	case f.Type == "string":
+		return ref + ` == ""`
+	case strings.HasPrefix(f.Type, "*"):
+		return ref + " == nil"
+	case strings.HasPrefix(f.Type, "[]"), strings.HasPrefix(f.Type, "map["):
+		return "len(" + ref + ") == 0"
+	case f.Type == "time.Time":
+		return ref + ".IsZero()"
+	default:
+		return ""
+	}
+}
+
+// Integer reports whether the field is a built-in integer, such as an auto
+// increment key
+func (f Field) Integer() bool {
+	switch f.Type {
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+		return true
+	default:
+		return false
+	}
+}
+
+// ApplyTags fills the struct tags configured for the project. Tags a field
+// already has (overrides or tags read back from the entity) are kept as they
+// are, and the configured keys come first.
+func (fs Fields) ApplyTags(cfg TagsConfig) {
+	for i := range fs {
+		f := &fs[i]
+
+		var tags []Tag
+		for _, key := range cfg.Keys {
+			if value, ok := f.TagValue(key); ok {
+				tags = append(tags, Tag{Key: key, Value: value})
+				continue
+			}
+			if value := f.defaultTag(key, cfg.Naming); value != "" {
+				tags = append(tags, Tag{Key: key, Value: value})
+			}
+		}
+		for _, tag := range f.Tags {
+			if !slices.Contains(cfg.Keys, tag.Key) {
+				tags = append(tags, tag)
+			}
+		}
+
+		f.Tags = tags
+	}
+}
+
+// TagValue returns the value of the struct tag key
+func (f Field) TagValue(key string) (string, bool) {
+	for _, tag := range f.Tags {
+		if tag.Key == key {
+			return tag.Value, true
+		}
+	}
+	return "", false
+}
+
+// JSONName returns the field name used in JSON payloads
+func (f Field) JSONName() string {
+	if value, ok := f.TagValue("json"); ok && value != "" {
+		return value
+	}
+	return f.Column
+}
+
+// StructTag renders the field tags, e.g. json:"id" db:"id"
+func (f Field) StructTag() string {
+	var parts []string
+	for _, tag := range f.Tags {
+		parts = append(parts, fmt.Sprintf("%s:%q", tag.Key, tag.Value))
+	}
+	return strings.Join(parts, " ")
+}
+
+// defaultTag returns the generated value of the struct tag key
+func (f Field) defaultTag(key, naming string) string {
+	// Built from the field name, a db override only renames the column
+	words := splitWords(f.Name)
+	for i := range words {
+		words[i] = strings.ToLower(words[i])
+		if naming == NamingCamelCase && i > 0 {
+			words[i] = ToPascalCase(words[i])
+		}
+	}
+	name := strings.Join(words, "_")
+	if naming == NamingCamelCase {
+		name = strings.Join(words, "")
+	}
+
+	switch key {
+	case "db":
+		return name
+	case "validate":
+		if f.Required() {
+			return "required"
+		}
+		return ""
+	default:
+		if f.Optional {
+			return name + ",omitempty"
+		}
+		return name
+	}
+}
+
+// columnFromTag uses the db tag, when there is one, as the column name
+func (f *Field) columnFromTag() {
+	if value, ok := f.TagValue("db"); ok {
+		if column, _, _ := strings.Cut(value, ","); column != "" && column != "-" {
+			f.Column = column
+		}
+	}
+}
+
+// parseStructTag splits a raw struct tag into its key:"value" pairs
+func parseStructTag(raw string) []Tag {
+	var tags []Tag
+	for raw != "" {
+		raw = strings.TrimLeft(raw, " ")
+		key, rest, ok := strings.Cut(raw, ":")
+		if !ok || !strings.HasPrefix(rest, `"`) {
+			break
+		}
+		value, err := strconv.QuotedPrefix(rest)
+		if err != nil {
+			break
+		}
+		unquoted, _ := strconv.Unquote(value)
+		tags = append(tags, Tag{Key: key, Value: unquoted})
+		raw = rest[len(value):]
+	}
+	return tags
+}
+
+func isNillable(typ string) bool {
+	return strings.HasPrefix(typ, "*") || strings.HasPrefix(typ, "[]") || strings.HasPrefix(typ, "map[")
+}
+
+// splitWords splits snake_case, kebab-case, camelCase and PascalCase names
+// into words, keeping runs of capitals together (UserID -> User, ID)
+func splitWords(name string) []string {
+	var words []string
+	runes := []rune(name)
+	start := 0
+	flush := func(end int) {
+		if end > start {
+			words = append(words, string(runes[start:end]))
+		}
+	}
+
+	for i, r := range runes {
+		switch {
+		case r == '_' || r == '-' || r == ' ':
+			flush(i)
+			start = i + 1
+		case i > start && unicode.IsUpper(r):
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				flush(i)
+				start = i
+			}
+		}
+	}
+	flush(len(runes))
+
+	return words
+}
+
+// goName joins words into an exported Go identifier
+func goName(words []string) string {
+	var sb strings.Builder
+	for _, w := range words {
+		upper := strings.ToUpper(w)
+		if commonInitialisms[upper] {
+			sb.WriteString(upper)
+			continue
+		}
+		sb.WriteString(ToPascalCase(strings.ToLower(w)))
+	}
+	return sb.String()
+}
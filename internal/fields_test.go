@@ -0,0 +1,302 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseField(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected Field
+	}{
+		{
+			name:     "Simple field",
+			input:    "name:string",
+			expected: Field{Name: "Name", Column: "name", Type: "string"},
+		},
+		{
+			name:     "Snake case name",
+			input:    "created_at:time.Time",
+			expected: Field{Name: "CreatedAt", Column: "created_at", Type: "time.Time", Imports: []string{"time"}},
+		},
+		{
+			name:     "Initialism",
+			input:    "user_id:string",
+			expected: Field{Name: "UserID", Column: "user_id", Type: "string"},
+		},
+		{
+			name:     "Camel case name",
+			input:    "avatarURL:string",
+			expected: Field{Name: "AvatarURL", Column: "avatar_url", Type: "string"},
+		},
+		{
+			name:     "Slice",
+			input:    "tags:[]string",
+			expected: Field{Name: "Tags", Column: "tags", Type: "[]string"},
+		},
+		{
+			name:     "Map with imported value",
+			input:    "meta:map[string]json.RawMessage",
+			expected: Field{Name: "Meta", Column: "meta", Type: "map[string]json.RawMessage", Imports: []string{"encoding/json"}},
+		},
+		{
+			name:     "Pointer",
+			input:    "deleted_at:*time.Time",
+			expected: Field{Name: "DeletedAt", Column: "deleted_at", Type: "*time.Time", Imports: []string{"time"}},
+		},
+		{
+			name:     "Full import path",
+			input:    "owner:github.com/google/uuid.UUID",
+			expected: Field{Name: "Owner", Column: "owner", Type: "uuid.UUID", Imports: []string{"github.com/google/uuid"}},
+		},
+		{
+			name:     "Optional scalar becomes pointer",
+			input:    "nickname?:string",
+			expected: Field{Name: "Nickname", Column: "nickname", Type: "*string", Optional: true},
+		},
+		{
+			name:     "Optional slice stays a slice",
+			input:    "aliases?:[]string",
+			expected: Field{Name: "Aliases", Column: "aliases", Type: "[]string", Optional: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseField(tt.input)
+			if err != nil {
+				t.Fatalf("ParseField(%q) failed: %v", tt.input, err)
+			}
+			if result.Name != tt.expected.Name || result.Column != tt.expected.Column ||
+				result.Type != tt.expected.Type || result.Optional != tt.expected.Optional ||
+				!slices.Equal(result.Imports, tt.expected.Imports) {
+				t.Errorf("ParseField(%q) = %+v, expected %+v", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseField_UnknownType(t *testing.T) {
+	tests := map[string]string{
+		"price:flaot64":       `field "price": unknown type "flaot64"`,
+		"tags:[]strng":        `field "tags": unknown type "strng"`,
+		"meta:map[string]Int": `field "meta": unknown type "Int"`,
+		"size:len":            `field "size": unknown type "len"`,
+		"owner:*comparable":   `field "owner": unknown type "comparable"`,
+	}
+
+	for input, want := range tests {
+		t.Run(input, func(t *testing.T) {
+			_, err := ParseField(input)
+			if err == nil || !strings.HasPrefix(err.Error(), want) {
+				t.Errorf("ParseField(%q) error = %v, want %s...", input, err, want)
+			}
+		})
+	}
+}
+
+func TestParseField_Invalid(t *testing.T) {
+	inputs := []string{
+		"name",
+		"name:",
+		":string",
+		"1name:string",
+		"name:foo.Bar",
+		"name:func()",
+		"name:chan int",
+	}
+
+	for _, input := range inputs {
+		t.Run(input, func(t *testing.T) {
+			if _, err := ParseField(input); err == nil {
+				t.Errorf("ParseField(%q) expected an error", input)
+			}
+		})
+	}
+}
+
+func TestParseFields(t *testing.T) {
+	fields, err := ParseFields([]string{"name:string", "created_at:time.Time"})
+	if err != nil {
+		t.Fatalf("ParseFields() failed: %v", err)
+	}
+
+	names := []string{}
+	for _, f := range fields {
+		names = append(names, f.Name)
+	}
+	if !slices.Equal(names, []string{"ID", "Name", "CreatedAt"}) {
+		t.Errorf("ParseFields() names = %v, expected ID to be added first", names)
+	}
+
+	if imports := fields.Imports(); !slices.Equal(imports, []string{"time"}) {
+		t.Errorf("Imports() = %v, expected [time]", imports)
+	}
+
+	if _, err := ParseFields([]string{"name:string", "name:int"}); err == nil {
+		t.Error("ParseFields() expected an error for duplicated fields")
+	}
+}
+
+func TestLoadEntityFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "product.go")
+	source := `package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Other struct {
+	Ignored string
+}
+
+type Product struct {
+	ID        uuid.UUID
+	Name      string
+	Tags      []string
+	Nickname  *string
+	CreatedAt time.Time
+}
+`
+	os.WriteFile(path, []byte(source), 0644)
+
+	fields, err := LoadEntityFields(path, "Product")
+	if err != nil {
+		t.Fatalf("LoadEntityFields() failed: %v", err)
+	}
+
+	if len(fields) != 5 {
+		t.Fatalf("LoadEntityFields() returned %d fields, expected 5", len(fields))
+	}
+	if f := fields.Get("ID"); f.Type != "uuid.UUID" || !slices.Equal(f.Imports, []string{"github.com/google/uuid"}) {
+		t.Errorf("ID field = %+v", f)
+	}
+	if f := fields.Get("CreatedAt"); f.Column != "created_at" || !slices.Equal(f.Imports, []string{"time"}) {
+		t.Errorf("CreatedAt field = %+v", f)
+	}
+	if f := fields.Get("Nickname"); !f.Optional {
+		t.Errorf("Nickname field = %+v, expected it to be optional", f)
+	}
+
+	missing, err := LoadEntityFields(path, "Missing")
+	if err != nil || missing != nil {
+		t.Errorf("LoadEntityFields() for a missing struct = %v, %v, expected nil, nil", missing, err)
+	}
+}
+
+func TestField_EmptyCheck(t *testing.T) {
+	tests := []struct {
+		field    Field
+		expected string
+		required bool
+	}{
+		{Field{Name: "Name", Type: "string"}, `e.Name == ""`, true},
+		{Field{Name: "Tags", Type: "[]string"}, "len(e.Tags) == 0", true},
+		{Field{Name: "At", Type: "time.Time"}, "e.At.IsZero()", true},
+		{Field{Name: "Nick", Type: "*string", Optional: true}, "e.Nick == nil", false},
+		{Field{Name: "Price", Type: "float64"}, "", false},
+		{Field{Name: "ID", Type: "string"}, `e.ID == ""`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field.Name, func(t *testing.T) {
+			if result := tt.field.EmptyCheck("e"); result != tt.expected {
+				t.Errorf("EmptyCheck() = %q, expected %q", result, tt.expected)
+			}
+			if result := tt.field.Required(); result != tt.required {
+				t.Errorf("Required() = %v, expected %v", result, tt.required)
+			}
+		})
+	}
+}
+
+func TestFields_ApplyTags(t *testing.T) {
+	fields, err := ParseFields([]string{
+		"created_at:time.Time",
+		"nickname?:string",
+		"email:string:json=email_address,omitempty:db=mail",
+	})
+	if err != nil {
+		t.Fatalf("ParseFields() failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		cfg      TagsConfig
+		expected map[string]string
+	}{
+		{
+			name: "Snake case",
+			cfg:  TagsConfig{Keys: []string{"json", "db"}, Naming: NamingSnakeCase},
+			expected: map[string]string{
+				"ID":        `json:"id" db:"id"`,
+				"CreatedAt": `json:"created_at" db:"created_at"`,
+				"Nickname":  `json:"nickname,omitempty" db:"nickname"`,
+				"Email":     `json:"email_address,omitempty" db:"mail"`,
+			},
+		},
+		{
+			name: "Camel case with validate and bson",
+			cfg:  TagsConfig{Keys: []string{"json", "bson", "validate"}, Naming: NamingCamelCase},
+			expected: map[string]string{
+				"ID":        `json:"id" bson:"id"`,
+				"CreatedAt": `json:"createdAt" bson:"createdAt" validate:"required"`,
+				"Nickname":  `json:"nickname,omitempty" bson:"nickname,omitempty"`,
+				"Email":     `json:"email_address,omitempty" bson:"email" validate:"required" db:"mail"`,
+			},
+		},
+		{
+			name: "No tags",
+			cfg:  TagsConfig{Naming: NamingSnakeCase},
+			expected: map[string]string{
+				"ID":    "",
+				"Email": `json:"email_address,omitempty" db:"mail"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := slices.Clone(fields)
+			fs.ApplyTags(tt.cfg)
+			for name, want := range tt.expected {
+				if got := fs.Get(name).StructTag(); got != want {
+					t.Errorf("%s StructTag() = %s, expected %s", name, got, want)
+				}
+			}
+		})
+	}
+
+	if email := fields.Get("Email"); email.Column != "mail" || email.JSONName() != "email_address,omitempty" {
+		t.Errorf("Email field = %+v, expected column from the db override", email)
+	}
+}
+
+func TestLoadEntityFields_Tags(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "customer.go")
+	source := "package entities\n\ntype Customer struct {\n" +
+		"\tID    string `json:\"id\" db:\"id\"`\n" +
+		"\tEmail string `json:\"email_address,omitempty\" db:\"mail\" custom:\"x y\"`\n" +
+		"}\n"
+	os.WriteFile(path, []byte(source), 0644)
+
+	fields, err := LoadEntityFields(path, "Customer")
+	if err != nil {
+		t.Fatalf("LoadEntityFields() failed: %v", err)
+	}
+
+	email := fields.Get("Email")
+	if got := email.StructTag(); got != `json:"email_address,omitempty" db:"mail" custom:"x y"` {
+		t.Errorf("StructTag() = %s", got)
+	}
+	if email.Column != "mail" {
+		t.Errorf("Column = %q, expected the db tag", email.Column)
+	}
+}
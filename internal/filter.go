@@ -0,0 +1,129 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Filterable reports whether List can select and sort entities by the field:
+// it must be a plain string, bool, number or time.Time
+func (f Field) Filterable() bool {
+	switch f.Type {
+	case "string", "bool", "float32", "float64", "time.Time":
+		return true
+	default:
+		return f.Integer()
+	}
+}
+
+// Ordered reports whether values of the field compare with <
+func (f Field) Ordered() bool {
+	switch f.Type {
+	case "string", "float32", "float64":
+		return true
+	default:
+		return f.Integer()
+	}
+}
+
+// Equal returns a Go condition that is true when a and b, values of the
+// field, are equal
+func (f Field) Equal(a, b string) string {
+	if f.Type == "time.Time" {
+		return fmt.Sprintf("%s.Equal(%s)", a, b)
+	}
+	return a + " == " + b
+}
+
+// Less returns a Go condition that is true when a, a value of the field,
+// sorts before b
+func (f Field) Less(a, b string) string {
+	switch f.Type {
+	case "time.Time":
+		return fmt.Sprintf("%s.Before(%s)", a, b)
+	case "bool":
+		return fmt.Sprintf("!%s && %s", a, b)
+	default:
+		return a + " < " + b
+	}
+}
+
+// ParamName returns the name of the field in query parameters, the one it has
+// in JSON payloads
+func (f Field) ParamName() string {
+	name, _, _ := strings.Cut(f.JSONName(), ",")
+	if name == "" || name == "-" {
+		return f.Column
+	}
+	return name
+}
+
+// Parse returns a Go call parsing the string s into a value of the field,
+// returning the value and an error, or "" for strings. Convert turns that
+// value into the type of the field.
+func (f Field) Parse(s string) string {
+	switch f.Type {
+	case "string":
+		return ""
+	case "bool":
+		return fmt.Sprintf("strconv.ParseBool(%s)", s)
+	case "int":
+		return fmt.Sprintf("strconv.Atoi(%s)", s)
+	case "float32", "float64":
+		return fmt.Sprintf("strconv.ParseFloat(%s, %s)", s, strings.TrimPrefix(f.Type, "float"))
+	case "time.Time":
+		return fmt.Sprintf("time.Parse(time.RFC3339, %s)", s)
+	}
+	if strings.HasPrefix(f.Type, "uint") {
+		return fmt.Sprintf("strconv.ParseUint(%s, 10, %s)", s, bitSize(strings.TrimPrefix(f.Type, "uint")))
+	}
+	return fmt.Sprintf("strconv.ParseInt(%s, 10, %s)", s, bitSize(strings.TrimPrefix(f.Type, "int")))
+}
+
+// Convert returns v, the value parsed by Parse, as the type of the field
+func (f Field) Convert(v string) string {
+	switch f.Type {
+	case "string", "bool", "int", "int64", "uint64", "float64", "time.Time":
+		return v
+	default:
+		return fmt.Sprintf("%s(%s)", f.Type, v)
+	}
+}
+
+func bitSize(bits string) string {
+	if bits == "" {
+		// int and uint
+		return "0"
+	}
+	return bits
+}
+
+// Filterable returns the fields besides ID that List can select entities by
+func (fs Fields) Filterable() Fields {
+	var fields Fields
+	for _, f := range fs {
+		if f.Name != "ID" && f.Filterable() {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// Sortable returns the fields List can sort by, ID first
+func (fs Fields) Sortable() Fields {
+	var fields Fields
+	if id := fs.Get("ID"); id != nil {
+		fields = append(fields, *id)
+	}
+	return append(fields, fs.Filterable()...)
+}
+
+// FilterImports returns the imports the filterable fields need
+func (fs Fields) FilterImports() []string {
+	for _, f := range fs.Filterable() {
+		if f.Type == "time.Time" {
+			return []string{"time"}
+		}
+	}
+	return nil
+}
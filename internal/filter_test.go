@@ -0,0 +1,80 @@
+package internal
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestField_Parse(t *testing.T) {
+	tests := []struct {
+		typ     string
+		parse   string
+		convert string
+	}{
+		{"string", "", "v"},
+		{"bool", "strconv.ParseBool(v)", "v"},
+		{"int", "strconv.Atoi(v)", "v"},
+		{"int32", "strconv.ParseInt(v, 10, 32)", "int32(v)"},
+		{"int64", "strconv.ParseInt(v, 10, 64)", "v"},
+		{"uint", "strconv.ParseUint(v, 10, 0)", "uint(v)"},
+		{"uint8", "strconv.ParseUint(v, 10, 8)", "uint8(v)"},
+		{"float32", "strconv.ParseFloat(v, 32)", "float32(v)"},
+		{"time.Time", "time.Parse(time.RFC3339, v)", "v"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.typ, func(t *testing.T) {
+			f := Field{Name: "X", Type: tt.typ}
+			if result := f.Parse("v"); result != tt.parse {
+				t.Errorf("Parse() = %q, expected %q", result, tt.parse)
+			}
+			if result := f.Convert("v"); result != tt.convert {
+				t.Errorf("Convert() = %q, expected %q", result, tt.convert)
+			}
+		})
+	}
+}
+
+func TestField_ParamName(t *testing.T) {
+	tests := []struct {
+		field    Field
+		expected string
+	}{
+		{Field{Name: "CreatedAt", Column: "created_at"}, "created_at"},
+		{Field{Name: "Email", Column: "email", Tags: []Tag{{Key: "json", Value: "mail,omitempty"}}}, "mail"},
+		{Field{Name: "Secret", Column: "secret", Tags: []Tag{{Key: "json", Value: "-"}}}, "secret"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field.Name, func(t *testing.T) {
+			if result := tt.field.ParamName(); result != tt.expected {
+				t.Errorf("ParamName() = %q, expected %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFields_Sortable(t *testing.T) {
+	fields := Fields{
+		{Name: "Name", Column: "name", Type: "string"},
+		{Name: "ID", Column: "id", Type: "int64"},
+		{Name: "Tags", Column: "tags", Type: "[]string"},
+		{Name: "Nick", Column: "nick", Type: "*string", Optional: true},
+		{Name: "CreatedAt", Column: "created_at", Type: "time.Time", Imports: []string{"time"}},
+	}
+
+	var names []string
+	for _, f := range fields.Sortable() {
+		names = append(names, f.Name)
+	}
+	if !slices.Equal(names, []string{"ID", "Name", "CreatedAt"}) {
+		t.Errorf("Sortable() = %v, expected [ID Name CreatedAt]", names)
+	}
+
+	if imports := fields.FilterImports(); len(imports) != 1 || imports[0] != "time" {
+		t.Errorf("FilterImports() = %v, expected [time]", imports)
+	}
+	if (Field{Type: "bool"}).Ordered() || !(Field{Type: "uint16"}).Ordered() {
+		t.Error("Ordered() should hold for numbers and strings only")
+	}
+}
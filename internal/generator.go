@@ -1,40 +1,71 @@
 package internal
 
 import (
+	"bytes"
 	"embed"
+	"errors"
+	"fmt"
+	"go/format"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"text/template"
 )
 
-func WriteTemplate(baseFS embed.FS, tplPath, outPath string, data any) error {
+// OverwriteMode decides what happens when a generated file already exists
+type OverwriteMode int
+
+const (
+	// OverwriteNever refuses to replace existing files (default)
+	OverwriteNever OverwriteMode = iota
+	// OverwriteForce replaces existing files without asking
+	OverwriteForce
+	// OverwriteSkip keeps existing files untouched
+	OverwriteSkip
+	// OverwritePrompt asks the user what to do with each existing file
+	OverwritePrompt
+)
+
+// ErrFileExists is returned when a target file exists and the mode is OverwriteNever
+var ErrFileExists = errors.New("file already exists")
+
+// WriteOptions controls how generated files are written to disk
+type WriteOptions struct {
+	Mode OverwriteMode
+	In   io.Reader // answers for OverwritePrompt
+	Out  io.Writer // prompts and diffs for OverwritePrompt
+}
+
+// RenderTemplate executes tplPath with data and returns the generated content.
+// Go templates (*.go.tpl) are gofmt-ed so conditional sections and field
+// lists always come out aligned.
+func RenderTemplate(baseFS embed.FS, tplPath string, data any) ([]byte, error) {
 	content, err := baseFS.ReadFile(tplPath)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	tpl, err := template.New(filepath.Base(tplPath)).Parse(string(content))
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		return nil, err
 	}
 
-	if err := os.MkdirAll(filepath.Dir(outPath), 0755); err != nil {
-		return err
+	if !strings.HasSuffix(tplPath, ".go.tpl") {
+		return buf.Bytes(), nil
 	}
 
-	f, err := os.Create(outPath)
+	formatted, err := format.Source(buf.Bytes())
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("%s generated invalid Go code: %w", tplPath, err)
 	}
-	defer func(f *os.File) {
-		err := f.Close()
-		if err != nil {
-			panic(err)
-		}
-	}(f)
 
-	return tpl.Execute(f, data)
+	return formatted, nil
 }
 
 func ToSnake(name string) string {
@@ -331,4 +331,3 @@ func BenchmarkToPascalCase(b *testing.B) {
 		ToPascalCase("createuserprofilehandler")
 	}
 }
-
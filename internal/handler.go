@@ -0,0 +1,176 @@
+package internal
+
+import (
+	"fmt"
+	"go/ast"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Kinds of handlers make handler generates
+const (
+	KindConsole  = "console"  // Run() executes the use case once and prints the result
+	KindHTTP     = "http"     // a net/http handler serving the use case as a REST route
+	KindGRPC     = "grpc"     // a gRPC service of its own, declared in a .proto file
+	KindCLI      = "cli"      // a cobra command whose flags fill the use case input
+	KindConsumer = "consumer" // a subscriber to the messages of a topic
+)
+
+// HandlerKinds lists the supported handler kinds
+var HandlerKinds = []string{KindConsole, KindHTTP, KindGRPC, KindCLI, KindConsumer}
+
+// LookupHandlerKind validates a handler kind, console when empty
+func LookupHandlerKind(kind string) (string, error) {
+	if kind == "" {
+		return KindConsole, nil
+	}
+	if !slices.Contains(HandlerKinds, kind) {
+		return "", fmt.Errorf("unknown handler kind %q, expected one of %s", kind, strings.Join(HandlerKinds, ", "))
+	}
+	return kind, nil
+}
+
+// Route is the HTTP method and path a handler serves
+type Route struct {
+	Method string
+	Path   string
+}
+
+// ParseRoute reads a ServeMux pattern such as "GET /users/{id}"
+func ParseRoute(pattern string) (Route, error) {
+	method, path, ok := strings.Cut(strings.TrimSpace(pattern), " ")
+	path = strings.TrimSpace(path)
+	if !ok || method == "" || !strings.HasPrefix(path, "/") {
+		return Route{}, fmt.Errorf("invalid route %q, expected a method and a path such as \"GET /users/{id}\"", pattern)
+	}
+	return Route{Method: strings.ToUpper(method), Path: path}, nil
+}
+
+// String returns the route as a ServeMux pattern
+func (r Route) String() string {
+	return r.Method + " " + r.Path
+}
+
+// HasID reports whether the path has an {id} wildcard
+func (r Route) HasID() bool {
+	return strings.Contains(r.Path, "{id}")
+}
+
+// List reports whether the route reads the collection, with the filter of
+// the query parameters
+func (r Route) List() bool {
+	return r.Method == "GET" && !r.HasID()
+}
+
+// Body reports whether requests to the route carry the input as JSON
+func (r Route) Body() bool {
+	return r.Method == "POST" || r.Method == "PUT" || r.Method == "PATCH"
+}
+
+// Status returns the Go constant of the status of successful responses
+func (r Route) Status() string {
+	switch r.Method {
+	case "POST":
+		return "http.StatusCreated"
+	case "DELETE":
+		return "http.StatusNoContent"
+	default:
+		return "http.StatusOK"
+	}
+}
+
+// Operations of the repository a use case performs
+const (
+	OpCreate = "Create"
+	OpGet    = "Get"
+	OpList   = "List"
+	OpUpdate = "Update"
+	OpDelete = "Delete"
+)
+
+// verbOperations maps the first word of use case names to their operation
+var verbOperations = map[string]string{
+	"Create":   OpCreate,
+	"Add":      OpCreate,
+	"Register": OpCreate,
+	"Get":      OpGet,
+	"Find":     OpGet,
+	"Show":     OpGet,
+	"Fetch":    OpGet,
+	"List":     OpList,
+	"Search":   OpList,
+	"Update":   OpUpdate,
+	"Edit":     OpUpdate,
+	"Delete":   OpDelete,
+	"Remove":   OpDelete,
+}
+
+// operationRoutes maps operations to the route serving them
+var operationRoutes = map[string]Route{
+	OpCreate: {"POST", ""},
+	OpGet:    {"GET", "/{id}"},
+	OpList:   {"GET", ""},
+	OpUpdate: {"PUT", "/{id}"},
+	OpDelete: {"DELETE", "/{id}"},
+}
+
+// UseCaseOperation returns the operation of useCase from the verb its name
+// starts with, CreateUser is OpCreate, or "" when it is another action
+func UseCaseOperation(useCase string) string {
+	return verbOperations[splitWords(ToPascalCase(useCase))[0]]
+}
+
+// RESTRoute returns the route of useCase on the collection of entity, from
+// its operation: CreateUser is POST /users and GetUser is GET /users/{id}.
+// Other use cases are actions on the collection, e.g. TransferFunds of
+// Account is POST /accounts/transfer-funds.
+func RESTRoute(useCase, entity string) Route {
+	collection := "/" + strings.ReplaceAll(TableName(entity), "_", "-")
+	if route, ok := operationRoutes[UseCaseOperation(useCase)]; ok {
+		return Route{Method: route.Method, Path: collection + route.Path}
+	}
+	words := splitWords(ToPascalCase(useCase))
+	return Route{Method: "POST", Path: collection + "/" + strings.ToLower(strings.Join(words, "-"))}
+}
+
+// UseCaseEntity returns the entity whose repository the use case declared
+// in the Go file at filePath works with, or "" when it has none
+func UseCaseEntity(filePath, useCase string) (string, error) {
+	p, err := parseFile(filePath)
+	if err != nil {
+		return "", err
+	}
+	st := p.Struct(ToPascalCase(useCase) + "UseCase")
+	if st == nil {
+		return "", nil
+	}
+	for _, field := range st.Fields.List {
+		if sel, ok := field.Type.(*ast.SelectorExpr); ok && strings.HasSuffix(sel.Sel.Name, "Repository") {
+			return strings.TrimSuffix(sel.Sel.Name, "Repository"), nil
+		}
+	}
+	return "", nil
+}
+
+// HandlerKind returns the kind of the handler type declared in the Go file
+// at filePath, from the methods it has
+func HandlerKind(filePath, handler string) (string, error) {
+	p, err := parseFile(filePath)
+	if err != nil {
+		return "", err
+	}
+	if p.Method(handler, "ServeHTTP") != nil || p.Method(handler, "Register") != nil {
+		return KindHTTP, nil
+	}
+	return KindConsole, nil
+}
+
+// parseFile reads the Go file at filePath into a Patcher, to look it up
+func parseFile(filePath string) (*Patcher, error) {
+	src, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return NewPatcher(filePath, src)
+}
@@ -0,0 +1,126 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRESTRoute(t *testing.T) {
+	tests := []struct {
+		useCase  string
+		entity   string
+		expected string
+	}{
+		{"CreateUser", "User", "POST /users"},
+		{"GetUser", "User", "GET /users/{id}"},
+		{"ListUsers", "User", "GET /users"},
+		{"UpdateUser", "User", "PUT /users/{id}"},
+		{"DeleteOrderItem", "OrderItem", "DELETE /order-items/{id}"},
+		{"TransferFunds", "Account", "POST /accounts/transfer-funds"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.useCase, func(t *testing.T) {
+			if route := RESTRoute(tt.useCase, tt.entity).String(); route != tt.expected {
+				t.Errorf("RESTRoute(%q, %q) = %q, expected %q", tt.useCase, tt.entity, route, tt.expected)
+			}
+		})
+	}
+}
+
+func TestUseCaseOperation(t *testing.T) {
+	tests := map[string]string{
+		"AddUser":       OpCreate,
+		"FindUser":      OpGet,
+		"SearchUsers":   OpList,
+		"EditUser":      OpUpdate,
+		"RemoveUser":    OpDelete,
+		"TransferFunds": "",
+	}
+
+	for useCase, expected := range tests {
+		if op := UseCaseOperation(useCase); op != expected {
+			t.Errorf("UseCaseOperation(%q) = %q, expected %q", useCase, op, expected)
+		}
+	}
+}
+
+func TestParseRoute(t *testing.T) {
+	route, err := ParseRoute("post  /users/{id}/activate")
+	if err != nil {
+		t.Fatalf("ParseRoute() failed: %v", err)
+	}
+	if route.String() != "POST /users/{id}/activate" || !route.HasID() || !route.Body() || route.Status() != "http.StatusCreated" {
+		t.Errorf("ParseRoute() = %+v", route)
+	}
+
+	for _, invalid := range []string{"", "/users", "GET users"} {
+		if _, err := ParseRoute(invalid); err == nil {
+			t.Errorf("ParseRoute(%q) succeeded, expected an error", invalid)
+		}
+	}
+}
+
+func TestHandlerKind(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "get_user_handler.go")
+	src := `package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GetUserHandler struct{}
+
+func (h *GetUserHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {}
+
+type ListUsersHandler struct{}
+
+func (h *ListUsersHandler) Register(router gin.IRouter) {}
+
+func (h *ListUsersHandler) Handle(c *gin.Context) {}
+
+type ImportUsersHandler struct{}
+
+func (h *ImportUsersHandler) Run() error { return nil }
+`
+	if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for handler, expected := range map[string]string{"GetUserHandler": KindHTTP, "ListUsersHandler": KindHTTP, "ImportUsersHandler": KindConsole} {
+		kind, err := HandlerKind(path, handler)
+		if err != nil {
+			t.Fatalf("HandlerKind() failed: %v", err)
+		}
+		if kind != expected {
+			t.Errorf("HandlerKind(%s) = %q, expected %q", handler, kind, expected)
+		}
+	}
+}
+
+func TestUseCaseEntity(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "get_person_usecase.go")
+	src := `package usecases
+
+import "example.com/shop/internal/domain"
+
+type GetPersonUseCase struct {
+	Repo domain.PersonRepository
+}
+`
+	if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	entity, err := UseCaseEntity(path, "GetPerson")
+	if err != nil {
+		t.Fatalf("UseCaseEntity() failed: %v", err)
+	}
+	if entity != "Person" {
+		t.Errorf("UseCaseEntity() = %q, expected Person", entity)
+	}
+}
@@ -0,0 +1,146 @@
+package internal
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// MigrationsDir is where the SQL migrations of a project are generated
+const MigrationsDir = "migrations"
+
+// StoredAsJSON reports whether the field is stored as JSON: slices, maps
+// and the other types that are not a number, a string, a bool, a time or
+// bytes. Repositories encode and decode them with encoding/json.
+func (f Field) StoredAsJSON() bool {
+	switch typ := strings.TrimPrefix(f.Type, "*"); typ {
+	case "string", "bool", "float32", "float64", "time.Time", "[]byte":
+		return false
+	default:
+		return !(Field{Type: typ}).Integer()
+	}
+}
+
+// ColumnType returns the SQL type of the column the field is stored in.
+// Slices, maps and other composite types are stored as JSON.
+func (d Dialect) ColumnType(f Field) string {
+	typ := strings.TrimPrefix(f.Type, "*")
+	switch typ {
+	case "string":
+		if d == DialectMySQL {
+			return "VARCHAR(255)"
+		}
+		return "TEXT"
+	case "bool":
+		return "BOOLEAN"
+	case "float32":
+		if d == DialectMySQL {
+			return "FLOAT"
+		}
+		return "REAL"
+	case "float64":
+		switch d {
+		case DialectMySQL:
+			return "DOUBLE"
+		case DialectPostgres:
+			return "DOUBLE PRECISION"
+		}
+		return "REAL"
+	case "time.Time":
+		switch d {
+		case DialectMySQL:
+			return "DATETIME(6)"
+		case DialectPostgres:
+			return "TIMESTAMPTZ"
+		}
+		return "DATETIME"
+	case "[]byte":
+		if d == DialectPostgres {
+			return "BYTEA"
+		}
+		return "BLOB"
+	}
+
+	if (Field{Type: typ}).Integer() {
+		if d == DialectSQLite {
+			return "INTEGER"
+		}
+		sqlType := "BIGINT"
+		switch strings.TrimPrefix(typ, "u") {
+		case "int8", "int16":
+			sqlType = "SMALLINT"
+		case "int32":
+			sqlType = "INTEGER"
+		}
+		if d == DialectMySQL && strings.HasPrefix(typ, "u") {
+			sqlType += " UNSIGNED"
+		}
+		return sqlType
+	}
+
+	switch d {
+	case DialectMySQL:
+		return "JSON"
+	case DialectPostgres:
+		return "JSONB"
+	}
+	return "TEXT"
+}
+
+// ColumnDefinition returns the definition of the column the field is stored
+// in, e.g. `name` VARCHAR(255) NOT NULL. The key of the table is its primary
+// key; MySQL generates integer keys, see the Save of the repositories.
+func (t *SQLTable) ColumnDefinition(f Field) string {
+	def := t.Quote(f.Column) + " " + t.Dialect.ColumnType(f)
+	key := f.Name == t.Key().Name
+	if key && t.Dialect == DialectSQLite && f.Integer() {
+		// An alias of the rowid, the only column SQLite generates
+		return def + " PRIMARY KEY AUTOINCREMENT"
+	}
+	if !f.Optional && !strings.HasPrefix(f.Type, "*") {
+		def += " NOT NULL"
+	}
+	if key {
+		switch {
+		case t.Dialect == DialectMySQL && f.Integer():
+			def += " AUTO_INCREMENT"
+		case t.Dialect == DialectPostgres && f.Integer():
+			def += " GENERATED BY DEFAULT AS IDENTITY"
+		}
+		def += " PRIMARY KEY"
+	}
+	return def
+}
+
+// CreateTable returns the statement creating the table, one column per line
+func (t *SQLTable) CreateTable() string {
+	columns := make([]string, len(t.Fields))
+	for i, f := range t.Fields {
+		columns[i] = "    " + t.ColumnDefinition(f)
+	}
+	return fmt.Sprintf("CREATE TABLE %s (\n%s\n);", t.table(), strings.Join(columns, ",\n"))
+}
+
+// DropTable returns the statement dropping the table
+func (t *SQLTable) DropTable() string {
+	return fmt.Sprintf("DROP TABLE %s;", t.table())
+}
+
+// MigrationPaths returns the up and down files of the migration called name
+// created at the given time, e.g.
+// migrations/20261018120000_create_users.up.sql
+func MigrationPaths(name string, at time.Time) (string, string) {
+	base := filepath.Join(MigrationsDir, at.UTC().Format("20060102150405")+"_"+name)
+	return base + ".up.sql", base + ".down.sql"
+}
+
+// FindMigration returns the up file of the migration called name, or an
+// empty string when there is none
+func FindMigration(name string) (string, error) {
+	matches, err := filepath.Glob(filepath.Join(MigrationsDir, "*_"+name+".up.sql"))
+	if err != nil || len(matches) == 0 {
+		return "", err
+	}
+	return matches[0], nil
+}
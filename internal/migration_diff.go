@@ -0,0 +1,371 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// TableColumn is a column of a table as the migrations left it
+type TableColumn struct {
+	Name       string
+	Type       string // SQL type, e.g. VARCHAR(255)
+	NotNull    bool
+	Definition string // everything after the name, e.g. TEXT NOT NULL PRIMARY KEY
+}
+
+// constraintWords end the type of a column definition
+var constraintWords = []string{"NOT", "NULL", "PRIMARY", "AUTO_INCREMENT", "AUTOINCREMENT", "DEFAULT", "UNIQUE",
+	"REFERENCES", "CHECK", "CONSTRAINT", "COLLATE", "GENERATED", "COMMENT", "ON"}
+
+// ParseColumn reads a column definition such as name VARCHAR(255) NOT NULL,
+// whose name may be quoted
+func ParseColumn(def string) TableColumn {
+	words := strings.Fields(def)
+	col := TableColumn{Name: unquoteIdentifier(words[0]), Definition: strings.Join(words[1:], " ")}
+	var typ []string
+	for _, word := range words[1:] {
+		if slices.Contains(constraintWords, strings.ToUpper(word)) {
+			break
+		}
+		typ = append(typ, word)
+	}
+	col.Type = strings.Join(typ, " ")
+	// Primary keys are never null, even SQLite's INTEGER PRIMARY KEY
+	upper := strings.ToUpper(col.Definition)
+	col.NotNull = strings.Contains(upper, "NOT NULL") || strings.Contains(upper, "PRIMARY KEY")
+	return col
+}
+
+// Same reports whether both columns have the same type and nullability
+func (c TableColumn) Same(other TableColumn) bool {
+	return strings.EqualFold(c.Type, other.Type) && c.NotNull == other.NotNull
+}
+
+// identifierRe matches a name, quoted or not
+const identifierRe = "([\\w\"`]+)"
+
+var (
+	createTableRe = regexp.MustCompile(`(?is)^CREATE TABLE (?:IF NOT EXISTS )?` + identifierRe + `\s*\((.*)\)$`)
+	alterTableRe  = regexp.MustCompile(`(?is)^ALTER TABLE ` + identifierRe + ` (.*)$`)
+	dropTableRe   = regexp.MustCompile(`(?is)^DROP TABLE (?:IF EXISTS )?` + identifierRe + `$`)
+	alterColumnRe = regexp.MustCompile(`(?is)^ALTER COLUMN ` + identifierRe + ` (TYPE (.+)|SET NOT NULL|DROP NOT NULL)$`)
+	defaultRe     = regexp.MustCompile(`(?i)\s+DEFAULT\s+('[^']*'|\S+)`)
+)
+
+// MigratedTables replays the up migrations of dir, in version order, and
+// returns the columns of the tables they leave. It understands the
+// statements make migration writes: CREATE TABLE with a column per line,
+// DROP TABLE and ALTER TABLE adding, dropping, modifying or renaming.
+func MigratedTables(dir string) (map[string][]TableColumn, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.up.sql"))
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(files)
+
+	tables := map[string][]TableColumn{}
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, stmt := range SQLStatements(string(content)) {
+			if err := replay(tables, strings.TrimSuffix(stmt, ";")); err != nil {
+				return nil, fmt.Errorf("%s: %w", file, err)
+			}
+		}
+	}
+	return tables, nil
+}
+
+// replay applies a statement to the columns of tables. Statements it does
+// not know, such as INSERT, leave them as they are.
+func replay(tables map[string][]TableColumn, stmt string) error {
+	if m := createTableRe.FindStringSubmatch(stmt); m != nil {
+		var columns []TableColumn
+		for _, line := range strings.Split(m[2], "\n") {
+			def := strings.TrimSuffix(strings.TrimSpace(line), ",")
+			if def == "" || strings.HasPrefix(strings.ToUpper(def), "PRIMARY KEY") {
+				continue
+			}
+			columns = append(columns, ParseColumn(def))
+		}
+		tables[unquoteIdentifier(m[1])] = columns
+		return nil
+	}
+	if m := dropTableRe.FindStringSubmatch(stmt); m != nil {
+		delete(tables, unquoteIdentifier(m[1]))
+		return nil
+	}
+
+	m := alterTableRe.FindStringSubmatch(stmt)
+	if m == nil {
+		return nil
+	}
+	table, action := unquoteIdentifier(m[1]), strings.TrimSpace(m[2])
+	columns, ok := tables[table]
+	if !ok {
+		return fmt.Errorf("%s alters the unknown table %s", stmt, table)
+	}
+	index := func(name string) int {
+		return slices.IndexFunc(columns, func(c TableColumn) bool { return c.Name == name })
+	}
+
+	upper := strings.ToUpper(action)
+	switch {
+	case strings.HasPrefix(upper, "ADD COLUMN "):
+		columns = append(columns, ParseColumn(defaultRe.ReplaceAllString(action[len("ADD COLUMN "):], "")))
+	case strings.HasPrefix(upper, "DROP COLUMN "):
+		if i := index(unquoteIdentifier(strings.TrimSpace(action[len("DROP COLUMN "):]))); i >= 0 {
+			columns = slices.Delete(columns, i, i+1)
+		}
+	case strings.HasPrefix(upper, "MODIFY COLUMN "):
+		col := ParseColumn(defaultRe.ReplaceAllString(action[len("MODIFY COLUMN "):], ""))
+		if i := index(col.Name); i >= 0 {
+			columns[i] = col
+		}
+	case strings.HasPrefix(upper, "ALTER COLUMN "):
+		sub := alterColumnRe.FindStringSubmatch(action)
+		if sub == nil {
+			return nil
+		}
+		i := index(unquoteIdentifier(sub[1]))
+		if i < 0 {
+			return nil
+		}
+		col := &columns[i]
+		switch strings.ToUpper(sub[2]) {
+		case "SET NOT NULL":
+			col.NotNull = true
+			col.Definition = strings.Replace(col.Definition, col.Type, col.Type+" NOT NULL", 1)
+		case "DROP NOT NULL":
+			col.NotNull = false
+			col.Definition = strings.Replace(col.Definition, " NOT NULL", "", 1)
+		default:
+			typ := strings.TrimSpace(sub[3])
+			col.Definition = strings.Replace(col.Definition, col.Type, typ, 1)
+			col.Type = typ
+		}
+	case strings.HasPrefix(upper, "RENAME TO "):
+		delete(tables, table)
+		table = unquoteIdentifier(strings.TrimSpace(action[len("RENAME TO "):]))
+	}
+	tables[table] = columns
+	return nil
+}
+
+// SQLStatements splits SQL into its statements, which end with a ; at the
+// end of a line. Lines starting with -- are comments.
+func SQLStatements(content string) []string {
+	var stmts []string
+	var stmt strings.Builder
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		stmt.WriteString(line + "\n")
+		if strings.HasSuffix(trimmed, ";") {
+			stmts = append(stmts, strings.TrimSpace(stmt.String()))
+			stmt.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(stmt.String()); rest != "" {
+		stmts = append(stmts, rest)
+	}
+	return stmts
+}
+
+// AlterTable returns the statements turning the table the migrations left
+// with columns into the one storing the fields of t, and the statements
+// reverting them. Both are empty when the table is up to date.
+func (t *SQLTable) AlterTable(columns []TableColumn) ([]string, []string) {
+	wanted := make([]TableColumn, len(t.Fields))
+	for i, f := range t.Fields {
+		wanted[i] = ParseColumn(t.ColumnDefinition(f))
+	}
+	find := func(cols []TableColumn, name string) *TableColumn {
+		if i := slices.IndexFunc(cols, func(c TableColumn) bool { return c.Name == name }); i >= 0 {
+			return &cols[i]
+		}
+		return nil
+	}
+
+	var added, dropped []TableColumn
+	var changed [][2]TableColumn // old, new
+	for _, col := range wanted {
+		if old := find(columns, col.Name); old == nil {
+			added = append(added, col)
+		} else if !old.Same(col) {
+			changed = append(changed, [2]TableColumn{*old, col})
+		}
+	}
+	for _, col := range columns {
+		if find(wanted, col.Name) == nil {
+			dropped = append(dropped, col)
+		}
+	}
+
+	if len(changed) > 0 && t.Dialect == DialectSQLite {
+		// SQLite cannot change columns, the table is rebuilt instead
+		return t.rebuild(columns, wanted), t.rebuild(wanted, columns)
+	}
+
+	// The changes are reverted in reverse order, each with its statements in
+	// order
+	var up []string
+	var down [][]string
+	for _, col := range added {
+		up = append(up, t.addColumn(col)...)
+		down = append(down, []string{t.dropColumn(col)})
+	}
+	for _, col := range dropped {
+		up = append(up, t.dropColumn(col))
+		down = append(down, t.addColumn(col))
+	}
+	for _, change := range changed {
+		up = append(up, t.modifyColumn(change[0], change[1])...)
+		down = append(down, t.modifyColumn(change[1], change[0]))
+	}
+	slices.Reverse(down)
+	return up, slices.Concat(down...)
+}
+
+// addColumn adds col. Existing rows get the zero value of NOT NULL columns,
+// when the dialect has a literal for it: as a default or, for the MySQL
+// types that cannot have one, by adding the column as nullable and filling
+// it before it becomes NOT NULL.
+func (t *SQLTable) addColumn(col TableColumn) []string {
+	if col.NotNull && t.Dialect == DialectMySQL && !hasLiteralDefault(col.Type) && t.Dialect.zero(col.Type) != "" {
+		nullable := col
+		nullable.NotNull = false
+		nullable.Definition = strings.Replace(col.Definition, " NOT NULL", "", 1)
+		add := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s;", t.table(), t.Quote(col.Name), nullable.Definition)
+		return append([]string{add}, t.modifyColumn(nullable, col)...)
+	}
+
+	def, ok := t.withDefault(col)
+	stmt := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s;", t.table(), t.Quote(col.Name), def)
+	if !ok {
+		return []string{fmt.Sprintf("-- %s has no default value: existing rows need one before it is NOT NULL\n%s", col.Name, stmt)}
+	}
+	return []string{stmt}
+}
+
+// hasLiteralDefault reports whether a MySQL column of the SQL type typ can
+// have a literal default. TEXT, BLOB and JSON columns cannot.
+func hasLiteralDefault(typ string) bool {
+	upper := strings.ToUpper(typ)
+	return !strings.Contains(upper, "TEXT") && !strings.Contains(upper, "BLOB") && upper != "JSON"
+}
+
+// withDefault returns the definition of col, defaulting to the zero value
+// when it is NOT NULL. It reports false when the zero value has no literal.
+func (t *SQLTable) withDefault(col TableColumn) (string, bool) {
+	if !col.NotNull || defaultRe.MatchString(" "+col.Definition) {
+		return col.Definition, true
+	}
+	zero := t.Dialect.zero(col.Type)
+	if zero == "" {
+		return col.Definition, false
+	}
+	return strings.Replace(col.Definition, col.Type, col.Type+" DEFAULT "+zero, 1), true
+}
+
+// dropColumn drops col
+func (t *SQLTable) dropColumn(col TableColumn) string {
+	return fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;", t.table(), t.Quote(col.Name))
+}
+
+// modifyColumn changes the type and nullability of from into the ones of
+// to. A column becoming NOT NULL gets the zero value in the rows it is NULL.
+func (t *SQLTable) modifyColumn(from, to TableColumn) []string {
+	var stmts []string
+	column := t.Quote(to.Name)
+	if to.NotNull && !from.NotNull {
+		if zero := t.Dialect.zero(from.Type); zero != "" {
+			stmts = append(stmts, fmt.Sprintf("UPDATE %s SET %s = %s WHERE %s IS NULL;", t.table(), column, zero, column))
+		}
+	}
+
+	if t.Dialect == DialectMySQL {
+		// The primary key stays, declaring it again is an error
+		def := strings.Replace(to.Definition, " PRIMARY KEY", "", 1)
+		return append(stmts, fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s %s;", t.table(), column, def))
+	}
+
+	if !strings.EqualFold(from.Type, to.Type) {
+		stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE %s;", t.table(), column, to.Type))
+	}
+	if from.NotNull != to.NotNull {
+		action := "DROP NOT NULL"
+		if to.NotNull {
+			action = "SET NOT NULL"
+		}
+		stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s %s;", t.table(), column, action))
+	}
+	return stmts
+}
+
+// rebuild recreates the table with the columns to, copying the values of
+// the columns it shares with from. New NOT NULL columns get the zero value,
+// and so do the NULLs of columns becoming NOT NULL.
+func (t *SQLTable) rebuild(from, to []TableColumn) []string {
+	defs := make([]string, len(to))
+	var columns, values []string
+	for i, col := range to {
+		name := t.Quote(col.Name)
+		old := slices.IndexFunc(from, func(c TableColumn) bool { return c.Name == col.Name })
+		if old < 0 {
+			def, _ := t.withDefault(col)
+			defs[i] = "    " + name + " " + def
+			continue
+		}
+		defs[i] = "    " + name + " " + col.Definition
+
+		value := name
+		if zero := t.Dialect.zero(col.Type); col.NotNull && !from[old].NotNull && zero != "" {
+			value = fmt.Sprintf("COALESCE(%s, %s)", name, zero)
+		}
+		columns = append(columns, name)
+		values = append(values, value)
+	}
+
+	tmp := t.Dialect.Quote(t.Name + "_new")
+	return []string{
+		fmt.Sprintf("CREATE TABLE %s (\n%s\n);", tmp, strings.Join(defs, ",\n")),
+		fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s;", tmp, strings.Join(columns, ", "), strings.Join(values, ", "), t.table()),
+		fmt.Sprintf("DROP TABLE %s;", t.table()),
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s;", tmp, t.table()),
+	}
+}
+
+// zero returns the literal of the zero value of a column of the SQL type
+// typ, or "" when there is none
+func (d Dialect) zero(typ string) string {
+	upper := strings.ToUpper(typ)
+	switch {
+	case strings.Contains(upper, "CHAR"), upper == "TEXT":
+		return "''"
+	case upper == "JSON", upper == "JSONB":
+		// What encoding/json writes for nil slices and maps
+		return "'null'"
+	case upper == "BYTEA", strings.Contains(upper, "BLOB"):
+		return "''"
+	case upper == "BOOLEAN":
+		return "FALSE"
+	case strings.Contains(upper, "INT"), upper == "REAL", upper == "FLOAT", strings.HasPrefix(upper, "DOUBLE"):
+		return "0"
+	case upper == "TIMESTAMPTZ":
+		return "'0001-01-01 00:00:00+00'"
+	case upper == "DATETIME":
+		// SQLite stores times as text
+		return "'0001-01-01 00:00:00+00:00'"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,170 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestParseColumn(t *testing.T) {
+	tests := []struct {
+		def      string
+		expected TableColumn
+	}{
+		{"name VARCHAR(255) NOT NULL", TableColumn{"name", "VARCHAR(255)", true, "VARCHAR(255) NOT NULL"}},
+		{"score DOUBLE PRECISION", TableColumn{"score", "DOUBLE PRECISION", false, "DOUBLE PRECISION"}},
+		{"id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY", TableColumn{"id", "BIGINT", true, "BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY"}},
+		{"age INTEGER DEFAULT 0 NOT NULL", TableColumn{"age", "INTEGER", true, "INTEGER DEFAULT 0 NOT NULL"}},
+		{"id INTEGER PRIMARY KEY AUTOINCREMENT", TableColumn{"id", "INTEGER", true, "INTEGER PRIMARY KEY AUTOINCREMENT"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.def, func(t *testing.T) {
+			if result := ParseColumn(tt.def); result != tt.expected {
+				t.Errorf("ParseColumn() = %+v, expected %+v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMigratedTables(t *testing.T) {
+	dir := t.TempDir()
+	migrations := map[string]string{
+		"20261018120000_create_users.up.sql":   "-- Create the users table\nCREATE TABLE users (\n    id TEXT NOT NULL PRIMARY KEY,\n    name TEXT NOT NULL,\n    email TEXT\n);\n",
+		"20261018120000_create_users.down.sql": "DROP TABLE users;\n",
+		"20261018130000_create_orders.up.sql":  "CREATE TABLE orders (\n    id BIGINT NOT NULL PRIMARY KEY\n);\n",
+		"20261019090000_alter_users.up.sql": "ALTER TABLE users ADD COLUMN age BIGINT DEFAULT 0 NOT NULL;\n" +
+			"ALTER TABLE users DROP COLUMN name;\n" +
+			"UPDATE users SET email = '' WHERE email IS NULL;\n" +
+			"ALTER TABLE users ALTER COLUMN email SET NOT NULL;\n" +
+			"ALTER TABLE users ALTER COLUMN age TYPE INTEGER;\n",
+		"20261020090000_drop_orders.up.sql": "DROP TABLE orders;\nALTER TABLE users RENAME TO people;\n",
+	}
+	for name, content := range migrations {
+		os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+	}
+
+	tables, err := MigratedTables(dir)
+	if err != nil {
+		t.Fatalf("MigratedTables() failed: %v", err)
+	}
+	if len(tables) != 1 {
+		t.Fatalf("MigratedTables() = %v, expected the people table only", tables)
+	}
+	expected := []TableColumn{
+		{"id", "TEXT", true, "TEXT NOT NULL PRIMARY KEY"},
+		{"email", "TEXT", true, "TEXT NOT NULL"},
+		{"age", "INTEGER", true, "INTEGER NOT NULL"},
+	}
+	if !slices.Equal(tables["people"], expected) {
+		t.Errorf("people = %+v, expected %+v", tables["people"], expected)
+	}
+
+	os.WriteFile(filepath.Join(dir, "20261021090000_alter_pets.up.sql"), []byte("ALTER TABLE pets DROP COLUMN name;\n"), 0644)
+	if _, err := MigratedTables(dir); err == nil {
+		t.Error("Altering an unknown table should fail")
+	}
+}
+
+func TestSQLTable_AlterTable(t *testing.T) {
+	fields := Fields{
+		{Name: "ID", Column: "id", Type: "int64"},
+		{Name: "Email", Column: "email", Type: "string"},
+		{Name: "Age", Column: "age", Type: "int"},
+		{Name: "Nickname", Column: "nickname", Type: "*string", Optional: true},
+	}
+	// email was optional, nickname required and tags is gone from the struct
+	columns := func(d Dialect) []TableColumn {
+		table := NewSQLTable(d, "User", Fields{
+			{Name: "ID", Column: "id", Type: "int64"},
+			{Name: "Email", Column: "email", Type: "*string"},
+			{Name: "Tags", Column: "tags", Type: "[]string"},
+			{Name: "Nickname", Column: "nickname", Type: "string"},
+		})
+		var cols []TableColumn
+		for _, f := range table.Fields {
+			cols = append(cols, ParseColumn(table.ColumnDefinition(f)))
+		}
+		return cols
+	}
+
+	tests := []struct {
+		dialect Dialect
+		up      []string
+		down    []string
+	}{
+		{
+			DialectMySQL,
+			[]string{
+				"ALTER TABLE `users` ADD COLUMN `age` BIGINT DEFAULT 0 NOT NULL;",
+				"ALTER TABLE `users` DROP COLUMN `tags`;",
+				"UPDATE `users` SET `email` = '' WHERE `email` IS NULL;",
+				"ALTER TABLE `users` MODIFY COLUMN `email` VARCHAR(255) NOT NULL;",
+				"ALTER TABLE `users` MODIFY COLUMN `nickname` VARCHAR(255);",
+			},
+			[]string{
+				"UPDATE `users` SET `nickname` = '' WHERE `nickname` IS NULL;",
+				"ALTER TABLE `users` MODIFY COLUMN `nickname` VARCHAR(255) NOT NULL;",
+				"ALTER TABLE `users` MODIFY COLUMN `email` VARCHAR(255);",
+				"ALTER TABLE `users` ADD COLUMN `tags` JSON;",
+				"UPDATE `users` SET `tags` = 'null' WHERE `tags` IS NULL;",
+				"ALTER TABLE `users` MODIFY COLUMN `tags` JSON NOT NULL;",
+				"ALTER TABLE `users` DROP COLUMN `age`;",
+			},
+		},
+		{
+			DialectPostgres,
+			[]string{
+				"ALTER TABLE \"users\" ADD COLUMN \"age\" BIGINT DEFAULT 0 NOT NULL;",
+				"ALTER TABLE \"users\" DROP COLUMN \"tags\";",
+				"UPDATE \"users\" SET \"email\" = '' WHERE \"email\" IS NULL;",
+				"ALTER TABLE \"users\" ALTER COLUMN \"email\" SET NOT NULL;",
+				"ALTER TABLE \"users\" ALTER COLUMN \"nickname\" DROP NOT NULL;",
+			},
+			[]string{
+				"UPDATE \"users\" SET \"nickname\" = '' WHERE \"nickname\" IS NULL;",
+				"ALTER TABLE \"users\" ALTER COLUMN \"nickname\" SET NOT NULL;",
+				"ALTER TABLE \"users\" ALTER COLUMN \"email\" DROP NOT NULL;",
+				"ALTER TABLE \"users\" ADD COLUMN \"tags\" JSONB DEFAULT 'null' NOT NULL;",
+				"ALTER TABLE \"users\" DROP COLUMN \"age\";",
+			},
+		},
+		{
+			DialectSQLite,
+			[]string{
+				"CREATE TABLE \"users_new\" (\n    \"id\" INTEGER PRIMARY KEY AUTOINCREMENT,\n    \"email\" TEXT NOT NULL,\n    \"age\" INTEGER DEFAULT 0 NOT NULL,\n    \"nickname\" TEXT\n);",
+				"INSERT INTO \"users_new\" (\"id\", \"email\", \"nickname\") SELECT \"id\", COALESCE(\"email\", ''), \"nickname\" FROM \"users\";",
+				"DROP TABLE \"users\";",
+				"ALTER TABLE \"users_new\" RENAME TO \"users\";",
+			},
+			[]string{
+				"CREATE TABLE \"users_new\" (\n    \"id\" INTEGER PRIMARY KEY AUTOINCREMENT,\n    \"email\" TEXT,\n    \"tags\" TEXT DEFAULT '' NOT NULL,\n    \"nickname\" TEXT NOT NULL\n);",
+				"INSERT INTO \"users_new\" (\"id\", \"email\", \"nickname\") SELECT \"id\", \"email\", COALESCE(\"nickname\", '') FROM \"users\";",
+				"DROP TABLE \"users\";",
+				"ALTER TABLE \"users_new\" RENAME TO \"users\";",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.dialect), func(t *testing.T) {
+			table := NewSQLTable(tt.dialect, "User", fields)
+			up, down := table.AlterTable(columns(tt.dialect))
+			if !slices.Equal(up, tt.up) {
+				t.Errorf("up = %q, expected %q", up, tt.up)
+			}
+			if !slices.Equal(down, tt.down) {
+				t.Errorf("down = %q, expected %q", down, tt.down)
+			}
+
+			var current []TableColumn
+			for _, f := range table.Fields {
+				current = append(current, ParseColumn(table.ColumnDefinition(f)))
+			}
+			if up, down := table.AlterTable(current); up != nil || down != nil {
+				t.Errorf("An up to date table should need no statements, got %q and %q", up, down)
+			}
+		})
+	}
+}
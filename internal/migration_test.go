@@ -0,0 +1,79 @@
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDialect_ColumnType(t *testing.T) {
+	tests := []struct {
+		typ      string
+		mysql    string
+		postgres string
+		sqlite   string
+	}{
+		{"string", "VARCHAR(255)", "TEXT", "TEXT"},
+		{"*string", "VARCHAR(255)", "TEXT", "TEXT"},
+		{"bool", "BOOLEAN", "BOOLEAN", "BOOLEAN"},
+		{"int", "BIGINT", "BIGINT", "INTEGER"},
+		{"int16", "SMALLINT", "SMALLINT", "INTEGER"},
+		{"uint32", "INTEGER UNSIGNED", "INTEGER", "INTEGER"},
+		{"float64", "DOUBLE", "DOUBLE PRECISION", "REAL"},
+		{"time.Time", "DATETIME(6)", "TIMESTAMPTZ", "DATETIME"},
+		{"[]byte", "BLOB", "BYTEA", "BLOB"},
+		{"[]string", "JSON", "JSONB", "TEXT"},
+		{"map[string]int", "JSON", "JSONB", "TEXT"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.typ, func(t *testing.T) {
+			f := Field{Name: "X", Type: tt.typ}
+			for dialect, expected := range map[Dialect]string{
+				DialectMySQL:    tt.mysql,
+				DialectPostgres: tt.postgres,
+				DialectSQLite:   tt.sqlite,
+			} {
+				if result := dialect.ColumnType(f); result != expected {
+					t.Errorf("%s ColumnType() = %q, expected %q", dialect, result, expected)
+				}
+			}
+		})
+	}
+}
+
+func TestSQLTable_CreateTable(t *testing.T) {
+	fields := Fields{
+		{Name: "ID", Column: "id", Type: "int64"},
+		{Name: "Name", Column: "name", Type: "string"},
+		{Name: "Nickname", Column: "nickname", Type: "*string", Optional: true},
+	}
+
+	tests := []struct {
+		dialect  Dialect
+		expected string
+	}{
+		{DialectMySQL, "CREATE TABLE `users` (\n    `id` BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,\n    `name` VARCHAR(255) NOT NULL,\n    `nickname` VARCHAR(255)\n);"},
+		{DialectPostgres, "CREATE TABLE \"users\" (\n    \"id\" BIGINT NOT NULL GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,\n    \"name\" TEXT NOT NULL,\n    \"nickname\" TEXT\n);"},
+		{DialectSQLite, "CREATE TABLE \"users\" (\n    \"id\" INTEGER PRIMARY KEY AUTOINCREMENT,\n    \"name\" TEXT NOT NULL,\n    \"nickname\" TEXT\n);"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.dialect), func(t *testing.T) {
+			table := NewSQLTable(tt.dialect, "User", fields)
+			if result := table.CreateTable(); result != tt.expected {
+				t.Errorf("CreateTable() =\n%s\nexpected\n%s", result, tt.expected)
+			}
+			if result := table.DropTable(); result != "DROP TABLE "+tt.dialect.Quote("users")+";" {
+				t.Errorf("DropTable() = %q", result)
+			}
+		})
+	}
+}
+
+func TestMigrationPaths(t *testing.T) {
+	at := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	up, down := MigrationPaths("create_users", at)
+	if up != "migrations/20261018120000_create_users.up.sql" || down != "migrations/20261018120000_create_users.down.sql" {
+		t.Errorf("MigrationPaths() = %q, %q", up, down)
+	}
+}
@@ -0,0 +1,744 @@
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// APIOperation is an HTTP handler as documented in the OpenAPI spec
+type APIOperation struct {
+	Handler string   // type of the handler, e.g. GetUserHandler
+	UseCase string   // use case it executes, e.g. GetUser
+	Route   Route    // route it registers, as a ServeMux pattern
+	Status  int      // status of successful responses
+	Input   Fields   // fields of the use case input
+	Output  string   // Go type of the result of the use case, "" when none
+	Query   []string // query parameters it reads, e.g. the filter of a List
+}
+
+// successStatuses maps the net/http constants of successful responses to
+// their code
+var successStatuses = map[string]int{
+	"StatusOK":        http.StatusOK,
+	"StatusCreated":   http.StatusCreated,
+	"StatusAccepted":  http.StatusAccepted,
+	"StatusNoContent": http.StatusNoContent,
+}
+
+// APIOperations reads the HTTP handlers declared in handlersDir, the types
+// with a Register method, and the input and result of the use cases they
+// execute from useCasesDir. Handlers whose route cannot be read are left out.
+func APIOperations(handlersDir, useCasesDir string) ([]APIOperation, error) {
+	files, err := parseDir(handlersDir)
+	if err != nil {
+		return nil, err
+	}
+	useCases, err := parseDir(useCasesDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	inputs, err := LoadStructs(useCasesDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var ops []APIOperation
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv == nil || fn.Name.Name != "Register" || fn.Body == nil {
+				continue
+			}
+			route, ok := registeredRoute(fn.Body)
+			if !ok {
+				continue
+			}
+			op := APIOperation{Handler: receiverType(fn), Route: route}
+			op.UseCase = handlerUseCase(file, op.Handler)
+			op.Status = handlerStatus(file, op.Handler, route)
+			op.Query = queryParams(files, op.Handler)
+			if op.UseCase != "" {
+				op.Input, op.Output = inputs[op.UseCase+"Input"], useCaseOutput(useCases, op.UseCase)
+			}
+			ops = append(ops, op)
+		}
+	}
+
+	sort.SliceStable(ops, func(i, j int) bool {
+		if ops[i].Route.Path != ops[j].Route.Path {
+			return ops[i].Route.Path < ops[j].Route.Path
+		}
+		return ops[i].Route.Method < ops[j].Route.Method
+	})
+	return ops, nil
+}
+
+// LoadStructs reads the fields of every struct declared in the Go files of
+// dir, by name
+func LoadStructs(dir string) (map[string]Fields, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	structs := map[string]Fields{}
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range structNames(file) {
+			fields, err := LoadEntityFields(path, name)
+			if err != nil {
+				return nil, err
+			}
+			structs[name] = fields
+		}
+	}
+	return structs, nil
+}
+
+// parseDir parses the Go files of dir, leaving tests out
+func parseDir(dir string) ([]*ast.File, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return nil, err
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	var files []*ast.File
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// structNames returns the structs declared at the top level of file
+func structNames(file *ast.File) []string {
+	var names []string
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			if ts, ok := spec.(*ast.TypeSpec); ok {
+				if _, ok := ts.Type.(*ast.StructType); ok {
+					names = append(names, ts.Name.Name)
+				}
+			}
+		}
+	}
+	return names
+}
+
+// receiverType returns the name of the type fn is a method of
+func receiverType(fn *ast.FuncDecl) string {
+	typ := fn.Recv.List[0].Type
+	if star, ok := typ.(*ast.StarExpr); ok {
+		typ = star.X
+	}
+	if ident, ok := typ.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+// routerParam matches the path parameters of gin and echo, :id or *path
+var routerParam = regexp.MustCompile(`[:*]\w*`)
+
+// registeredRoute reads the route of the first call of body with string
+// arguments: a ServeMux pattern such as "GET /users/{id}", or a method and a
+// path written for any router, such as "GET", "/users/:id"
+func registeredRoute(body *ast.BlockStmt) (Route, bool) {
+	var route Route
+	var found bool
+	ast.Inspect(body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || found {
+			return !found
+		}
+		var literals []string
+		for _, arg := range call.Args {
+			lit, ok := arg.(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				break
+			}
+			value, _ := strconv.Unquote(lit.Value)
+			literals = append(literals, value)
+		}
+		switch len(literals) {
+		case 0:
+			return true
+		case 1:
+			r, err := ParseRoute(literals[0])
+			if err != nil {
+				return true
+			}
+			route = r
+		default:
+			path := routerParam.ReplaceAllStringFunc(literals[1], func(m string) string {
+				if m == "*" {
+					// The unnamed wildcard of echo
+					return "{path...}"
+				}
+				if m[0] == '*' {
+					return "{" + m[1:] + "...}"
+				}
+				return "{" + m[1:] + "}"
+			})
+			route = Route{Method: strings.ToUpper(literals[0]), Path: path}
+		}
+		found = true
+		return false
+	})
+	return route, found
+}
+
+// handlerUseCase returns the use case of the UC field of handler, the
+// <UseCase>UseCase it executes
+func handlerUseCase(file *ast.File, handler string) string {
+	var useCase string
+	ast.Inspect(file, func(n ast.Node) bool {
+		spec, ok := n.(*ast.TypeSpec)
+		if !ok || spec.Name.Name != handler {
+			return useCase == ""
+		}
+		if st, ok := spec.Type.(*ast.StructType); ok {
+			for _, field := range st.Fields.List {
+				typ := strings.TrimPrefix(types.ExprString(field.Type), "*")
+				if _, name, ok := strings.Cut(typ, "."); ok && strings.HasSuffix(name, "UseCase") {
+					useCase = strings.TrimSuffix(name, "UseCase")
+					break
+				}
+			}
+		}
+		return false
+	})
+	return useCase
+}
+
+// handlerStatus returns the status handler writes on success, read from
+// its writeJSON and WriteHeader calls, or the one of route when there are
+// none
+func handlerStatus(file *ast.File, handler string, route Route) int {
+	status := 0
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv == nil || fn.Body == nil || receiverType(fn) != handler {
+			continue
+		}
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || status != 0 {
+				return status == 0
+			}
+			for _, arg := range call.Args {
+				if sel, ok := arg.(*ast.SelectorExpr); ok {
+					if code, ok := successStatuses[sel.Sel.Name]; ok {
+						status = code
+						return false
+					}
+				}
+			}
+			return true
+		})
+	}
+	if status == 0 {
+		status = successStatuses[strings.TrimPrefix(route.Status(), "http.")]
+	}
+	return status
+}
+
+// queryParams returns the query parameters the methods of handler read,
+// with r.URL.Query().Get or through a function of the package taking the
+// url.Values, such as the Parse<Entity>Filter of CRUD entities
+func queryParams(files []*ast.File, handler string) []string {
+	funcs := map[string]*ast.FuncDecl{}
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Body != nil {
+				funcs[fn.Name.Name] = fn
+			}
+		}
+	}
+
+	var params []string
+	add := func(names ...string) {
+		for _, name := range names {
+			if !slices.Contains(params, name) {
+				params = append(params, name)
+			}
+		}
+	}
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv == nil || fn.Body == nil || receiverType(fn) != handler {
+				continue
+			}
+			ast.Inspect(fn.Body, func(n ast.Node) bool {
+				call, ok := n.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				if name, ok := valuesGet(call, func(x ast.Expr) bool {
+					query, ok := x.(*ast.CallExpr)
+					if !ok {
+						return false
+					}
+					sel, ok := query.Fun.(*ast.SelectorExpr)
+					return ok && sel.Sel.Name == "Query"
+				}); ok {
+					add(name)
+				}
+				if ident, ok := call.Fun.(*ast.Ident); ok && funcs[ident.Name] != nil {
+					add(valuesParams(funcs[ident.Name])...)
+				}
+				return true
+			})
+		}
+	}
+	return params
+}
+
+// valuesParams returns the parameters fn reads from its url.Values
+// argument: the ones it gets and the keys of the maps it ranges over to get
+// them, such as the limit and offset of filters
+func valuesParams(fn *ast.FuncDecl) []string {
+	var values []string
+	for _, field := range fn.Type.Params.List {
+		if types.ExprString(field.Type) == "url.Values" {
+			for _, name := range field.Names {
+				values = append(values, name.Name)
+			}
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	var params []string
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.CallExpr:
+			if name, ok := valuesGet(n, func(x ast.Expr) bool {
+				ident, ok := x.(*ast.Ident)
+				return ok && slices.Contains(values, ident.Name)
+			}); ok {
+				params = append(params, name)
+			}
+		case *ast.RangeStmt:
+			if lit, ok := n.X.(*ast.CompositeLit); ok {
+				if m, ok := lit.Type.(*ast.MapType); ok && types.ExprString(m.Key) == "string" {
+					for _, elt := range lit.Elts {
+						if kv, ok := elt.(*ast.KeyValueExpr); ok {
+							if key, ok := kv.Key.(*ast.BasicLit); ok && key.Kind == token.STRING {
+								name, _ := strconv.Unquote(key.Value)
+								params = append(params, name)
+							}
+						}
+					}
+				}
+			}
+		}
+		return true
+	})
+	return params
+}
+
+// valuesGet returns the name call gets when it is a Get call with a string
+// literal on a receiver matching values
+func valuesGet(call *ast.CallExpr, values func(ast.Expr) bool) (string, bool) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Get" || len(call.Args) != 1 || !values(sel.X) {
+		return "", false
+	}
+	lit, ok := call.Args[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	name, err := strconv.Unquote(lit.Value)
+	return name, err == nil
+}
+
+// useCaseOutput returns the type of the result of the Execute method of
+// useCase, "" when it only returns an error
+func useCaseOutput(files []*ast.File, useCase string) string {
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv == nil || fn.Name.Name != "Execute" || receiverType(fn) != useCase+"UseCase" || fn.Type.Results == nil {
+				continue
+			}
+			for _, result := range fn.Type.Results.List {
+				if typ := types.ExprString(result.Type); typ != "error" {
+					return typ
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// openAPISchema is a Schema Object of an OpenAPI 3.0 document
+type openAPISchema struct {
+	Ref                  string             `yaml:"$ref,omitempty"`
+	Type                 string             `yaml:"type,omitempty"`
+	Format               string             `yaml:"format,omitempty"`
+	Nullable             bool               `yaml:"nullable,omitempty"`
+	Items                *openAPISchema     `yaml:"items,omitempty"`
+	AdditionalProperties *openAPISchema     `yaml:"additionalProperties,omitempty"`
+	Properties           *openAPIProperties `yaml:"properties,omitempty"`
+	Required             []string           `yaml:"required,omitempty"`
+	GoType               string             `yaml:"x-go-type,omitempty"`
+}
+
+// openAPIProperties are the properties of an object schema, written in the
+// order of the fields of its struct
+type openAPIProperties struct {
+	names   []string
+	schemas []*openAPISchema
+}
+
+func (p *openAPIProperties) add(name string, schema *openAPISchema) {
+	p.names = append(p.names, name)
+	p.schemas = append(p.schemas, schema)
+}
+
+func (p *openAPIProperties) MarshalYAML() (any, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for i, name := range p.names {
+		var value yaml.Node
+		if err := value.Encode(p.schemas[i]); err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: name}, &value)
+	}
+	return node, nil
+}
+
+// openAPIFormats maps Go types to the type and format of their JSON values
+var openAPIFormats = map[string][2]string{
+	"string":          {"string", ""},
+	"bool":            {"boolean", ""},
+	"int":             {"integer", "int64"},
+	"int8":            {"integer", "int32"},
+	"int16":           {"integer", "int32"},
+	"int32":           {"integer", "int32"},
+	"int64":           {"integer", "int64"},
+	"uint":            {"integer", "int64"},
+	"uint8":           {"integer", "int32"},
+	"uint16":          {"integer", "int32"},
+	"uint32":          {"integer", "int64"},
+	"uint64":          {"integer", "int64"},
+	"float32":         {"number", "float"},
+	"float64":         {"number", "double"},
+	"[]byte":          {"string", "byte"},
+	"time.Time":       {"string", "date-time"},
+	"time.Duration":   {"integer", "int64"},
+	"uuid.UUID":       {"string", "uuid"},
+	"decimal.Decimal": {"string", ""},
+	"url.URL":         {"string", "uri"},
+	"netip.Addr":      {"string", ""},
+}
+
+// newOpenAPISchema returns the schema of the JSON values of the Go type typ.
+// Types of schemas are referenced, types it does not know are described by
+// their x-go-type only.
+func newOpenAPISchema(typ string, schemas map[string]Fields) *openAPISchema {
+	if rest, ok := strings.CutPrefix(typ, "*"); ok {
+		schema := newOpenAPISchema(rest, schemas)
+		if schema.Ref == "" {
+			schema.Nullable = true
+		}
+		return schema
+	}
+	// encoding/json writes nil slices, bytes included, and maps as null
+	if format, ok := openAPIFormats[typ]; ok {
+		return &openAPISchema{Type: format[0], Format: format[1], Nullable: strings.HasPrefix(typ, "[]")}
+	}
+	if typ == "json.RawMessage" || typ == "any" || typ == "interface{}" {
+		return &openAPISchema{}
+	}
+	if rest, ok := strings.CutPrefix(typ, "[]"); ok {
+		return &openAPISchema{Type: "array", Items: newOpenAPISchema(rest, schemas), Nullable: true}
+	}
+	if rest, ok := strings.CutPrefix(typ, "map[string]"); ok {
+		return &openAPISchema{Type: "object", AdditionalProperties: newOpenAPISchema(rest, schemas), Nullable: true}
+	}
+
+	name := typ
+	if i := strings.LastIndex(typ, "."); i >= 0 {
+		name = typ[i+1:]
+	}
+	if _, ok := schemas[name]; ok {
+		return &openAPISchema{Ref: "#/components/schemas/" + name}
+	}
+	return &openAPISchema{GoType: typ}
+}
+
+// jsonProperty returns the name encoding/json gives the field, and whether
+// it is left out of the JSON values when empty. It returns "" for fields
+// encoding/json skips.
+func jsonProperty(f Field) (string, bool) {
+	if !ast.IsExported(f.Name) {
+		return "", false
+	}
+	name := f.Name
+	value, _ := f.TagValue("json")
+	tagName, opts, _ := strings.Cut(value, ",")
+	if tagName == "-" && opts == "" {
+		return "", false
+	}
+	if tagName != "" {
+		name = tagName
+	}
+	return name, slices.Contains(strings.Split(opts, ","), "omitempty")
+}
+
+// objectSchema returns the schema of a struct with fields. With required,
+// the properties always written are required.
+func objectSchema(fields Fields, schemas map[string]Fields, required bool) *openAPISchema {
+	schema := &openAPISchema{Type: "object", Properties: &openAPIProperties{}}
+	for _, f := range fields {
+		name, omitEmpty := jsonProperty(f)
+		if name == "" {
+			continue
+		}
+		property := newOpenAPISchema(f.Type, schemas)
+		if value, _ := f.TagValue("json"); strings.Contains(value, ",string") {
+			property = &openAPISchema{Type: "string", Nullable: property.Nullable}
+		}
+		schema.Properties.add(name, property)
+		if required && !omitEmpty && !f.Optional {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	return schema
+}
+
+// openAPIOperation is an Operation Object of an OpenAPI 3.0 document
+type openAPIOperation struct {
+	Tags        []string           `yaml:"tags,omitempty"`
+	Summary     string             `yaml:"summary"`
+	OperationID string             `yaml:"operationId"`
+	Parameters  []openAPIParameter `yaml:"parameters,omitempty"`
+	RequestBody *openAPIBody       `yaml:"requestBody,omitempty"`
+	Responses   map[string]any     `yaml:"responses"`
+}
+
+// openAPIParameter is a Parameter Object of an OpenAPI 3.0 document
+type openAPIParameter struct {
+	Name        string         `yaml:"name"`
+	In          string         `yaml:"in"`
+	Description string         `yaml:"description,omitempty"`
+	Required    bool           `yaml:"required"`
+	Schema      *openAPISchema `yaml:"schema"`
+}
+
+// filterParams describes the paging and sorting query parameters of the
+// filters of CRUD entities
+var filterParams = map[string]string{
+	"sort":   "Field to sort by, descending when prefixed with -",
+	"limit":  "Maximum number of results",
+	"offset": "Number of results to skip",
+	"after":  "ID the results start after",
+}
+
+// queryParameter returns the query parameter name of an operation whose
+// result holds entity values: a filter parameter, or the condition on the
+// entity field it is named after. Other parameters are strings.
+func queryParameter(name string, entity Fields, known map[string]Fields) openAPIParameter {
+	param := openAPIParameter{Name: name, In: "query", Description: filterParams[name], Schema: &openAPISchema{Type: "string"}}
+	switch name {
+	case "sort":
+		return param
+	case "limit", "offset":
+		param.Schema = newOpenAPISchema("int", known)
+		return param
+	}
+	for _, f := range entity {
+		if (name == "after" && f.Name == "ID") || (name != "after" && f.ParamName() == name) {
+			param.Schema = newOpenAPISchema(strings.TrimPrefix(f.Type, "*"), known)
+			if name != "after" {
+				param.Description = "Only results whose " + name + " equals it"
+			}
+		}
+	}
+	return param
+}
+
+// outputEntity returns the entity the Go type of a use case result holds,
+// e.g. User for []*entities.User
+func outputEntity(typ string) string {
+	typ = strings.TrimLeft(strings.TrimPrefix(typ, "[]"), "*")
+	if i := strings.LastIndex(typ, "."); i >= 0 {
+		typ = typ[i+1:]
+	}
+	return typ
+}
+
+// openAPIBody is a Request Body or a Response Object of an OpenAPI 3.0
+// document
+type openAPIBody struct {
+	Description string         `yaml:"description,omitempty"`
+	Required    bool           `yaml:"required,omitempty"`
+	Content     map[string]any `yaml:"content,omitempty"`
+}
+
+// jsonContent returns the content of a JSON body with schema
+func jsonContent(schema *openAPISchema) map[string]any {
+	return map[string]any{"application/json": map[string]any{"schema": schema}}
+}
+
+// openAPIErrors are the responses writeError gives to the errors of use
+// cases, by status
+var openAPIErrors = map[int]string{
+	http.StatusBadRequest:          "BadRequest",
+	http.StatusNotFound:            "NotFound",
+	http.StatusConflict:            "Conflict",
+	http.StatusInternalServerError: "InternalError",
+}
+
+// errorStatuses returns the error statuses route can answer with: invalid
+// input or filters and internal errors always, missing entities when the
+// path has parameters and conflicts for the routes writing them
+func errorStatuses(route Route) []int {
+	statuses := []int{http.StatusBadRequest}
+	if strings.Contains(route.Path, "{") {
+		statuses = append(statuses, http.StatusNotFound)
+	}
+	if route.Body() {
+		statuses = append(statuses, http.StatusConflict)
+	}
+	return append(statuses, http.StatusInternalServerError)
+}
+
+// OpenAPISpec returns an OpenAPI 3.0 document in YAML describing the
+// operations, with a schema per entity and per use case input
+func OpenAPISpec(title, version string, entities map[string]Fields, ops []APIOperation) ([]byte, error) {
+	schemas := map[string]*openAPISchema{
+		"Error": {
+			Type:       "object",
+			Properties: &openAPIProperties{names: []string{"error"}, schemas: []*openAPISchema{{Type: "string"}}},
+			Required:   []string{"error"},
+		},
+	}
+	known := map[string]Fields{"Error": nil}
+	for name, fields := range entities {
+		known[name] = fields
+	}
+	for _, op := range ops {
+		if op.Route.Body() && op.UseCase != "" {
+			known[op.UseCase+"Input"] = op.Input
+		}
+	}
+	for name, fields := range entities {
+		schemas[name] = objectSchema(fields, known, true)
+	}
+
+	paths := map[string]map[string]*openAPIOperation{}
+	responses := map[string]any{}
+	for _, op := range ops {
+		id := op.UseCase
+		if id == "" {
+			id = strings.TrimSuffix(op.Handler, "Handler")
+		}
+		words := splitWords(id)
+		operation := &openAPIOperation{
+			Summary:     words[0] + " " + strings.ToLower(strings.Join(words[1:], " ")),
+			OperationID: id,
+			Responses:   map[string]any{},
+		}
+		if segment := strings.Split(strings.TrimPrefix(op.Route.Path, "/"), "/")[0]; segment != "" {
+			operation.Tags = []string{segment}
+		}
+
+		for _, m := range wildcard.FindAllString(op.Route.Path, -1) {
+			name := strings.TrimSuffix(strings.Trim(m, "{}"), "...")
+			schema := &openAPISchema{Type: "string"}
+			for _, f := range op.Input {
+				if strings.EqualFold(f.Name, strings.ReplaceAll(name, "_", "")) {
+					schema = newOpenAPISchema(f.Type, known)
+				}
+			}
+			operation.Parameters = append(operation.Parameters, openAPIParameter{Name: name, In: "path", Required: true, Schema: schema})
+		}
+		for _, name := range op.Query {
+			operation.Parameters = append(operation.Parameters, queryParameter(name, entities[outputEntity(op.Output)], known))
+		}
+
+		if op.Route.Body() && op.UseCase != "" {
+			input := op.UseCase + "Input"
+			schemas[input] = objectSchema(op.Input, known, false)
+			operation.RequestBody = &openAPIBody{Required: true, Content: jsonContent(&openAPISchema{Ref: "#/components/schemas/" + input})}
+		}
+
+		success := &openAPIBody{Description: http.StatusText(op.Status)}
+		if op.Output != "" && op.Status != http.StatusNoContent {
+			success.Content = jsonContent(newOpenAPISchema(op.Output, known))
+		}
+		operation.Responses[strconv.Itoa(op.Status)] = success
+		for _, status := range errorStatuses(op.Route) {
+			name := openAPIErrors[status]
+			operation.Responses[strconv.Itoa(status)] = map[string]string{"$ref": "#/components/responses/" + name}
+			responses[name] = &openAPIBody{
+				Description: http.StatusText(status),
+				Content:     jsonContent(&openAPISchema{Ref: "#/components/schemas/Error"}),
+			}
+		}
+
+		if paths[op.Route.Path] == nil {
+			paths[op.Route.Path] = map[string]*openAPIOperation{}
+		}
+		paths[op.Route.Path][strings.ToLower(op.Route.Method)] = operation
+	}
+
+	doc := struct {
+		OpenAPI    string                                  `yaml:"openapi"`
+		Info       map[string]string                       `yaml:"info"`
+		Paths      map[string]map[string]*openAPIOperation `yaml:"paths"`
+		Components map[string]any                          `yaml:"components"`
+	}{
+		OpenAPI:    "3.0.3",
+		Info:       map[string]string{"title": title, "version": version},
+		Paths:      paths,
+		Components: map[string]any{"schemas": schemas},
+	}
+	if len(responses) > 0 {
+		doc.Components["responses"] = responses
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return nil, fmt.Errorf("openapi: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,171 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAPIOperations(t *testing.T) {
+	dir := t.TempDir()
+	handlers, useCases := filepath.Join(dir, "handlers"), filepath.Join(dir, "usecases")
+	os.MkdirAll(handlers, 0755)
+	os.MkdirAll(useCases, 0755)
+
+	os.WriteFile(filepath.Join(handlers, "get_user_handler.go"), []byte(`package handlers
+
+type GetUserHandler struct {
+	UC *usecases.GetUserUseCase
+}
+
+func (h *GetUserHandler) Register(mux *http.ServeMux) {
+	mux.Handle("GET /users/{id}", h)
+}
+
+func (h *GetUserHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, nil)
+}
+`), 0644)
+	os.WriteFile(filepath.Join(handlers, "upload_file_handler.go"), []byte(`package handlers
+
+type UploadFileHandler struct {
+	UC *usecases.UploadFileUseCase
+}
+
+func (h *UploadFileHandler) Register(router gin.IRouter) {
+	router.Handle("PUT", "/users/:user_id/files/*path", h.Handle)
+}
+
+func (h *UploadFileHandler) Handle(c *gin.Context) {
+	c.Writer.WriteHeader(http.StatusAccepted)
+}
+`), 0644)
+	os.WriteFile(filepath.Join(handlers, "list_users_handler.go"), []byte(`package handlers
+
+type ListUsersHandler struct {
+	UC *usecases.ListUsersUseCase
+}
+
+func (h *ListUsersHandler) Register(mux *http.ServeMux) {
+	mux.Handle("GET /users", h)
+}
+
+func (h *ListUsersHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	filter, err := ParseUserFilter(r.URL.Query())
+	fields := r.URL.Query().Get("fields")
+	token := r.Header.Get("Authorization")
+	writeJSON(w, http.StatusOK, nil)
+}
+
+func ParseUserFilter(query url.Values) (repository.UserFilter, error) {
+	filter := repository.UserFilter{Sort: query.Get("sort")}
+	if v := query.Get("email"); v != "" {
+		filter.Email = &v
+	}
+	for param, dest := range map[string]*int{"limit": &filter.Limit, "offset": &filter.Offset} {
+		*dest, _ = strconv.Atoi(query.Get(param))
+	}
+	return filter, nil
+}
+`), 0644)
+	os.WriteFile(filepath.Join(handlers, "import_users_handler.go"), []byte(`package handlers
+
+type ImportUsersHandler struct{}
+
+func (h *ImportUsersHandler) Run() error { return nil }
+`), 0644)
+	os.WriteFile(filepath.Join(useCases, "upload_file_usecase.go"), []byte(`package usecases
+
+type UploadFileUseCase struct{}
+
+func (uc *UploadFileUseCase) Execute(input UploadFileInput) error { return nil }
+
+type UploadFileInput struct {
+	UserID  int64
+	Content []byte `+"`json:\"content\"`"+`
+}
+`), 0644)
+
+	ops, err := APIOperations(handlers, useCases)
+	if err != nil {
+		t.Fatalf("APIOperations() failed: %v", err)
+	}
+	expected := []APIOperation{
+		{Handler: "ListUsersHandler", UseCase: "ListUsers", Route: Route{"GET", "/users"}, Status: 200, Query: []string{"sort", "email", "limit", "offset", "fields"}},
+		{Handler: "GetUserHandler", UseCase: "GetUser", Route: Route{"GET", "/users/{id}"}, Status: 200},
+		{
+			Handler: "UploadFileHandler", UseCase: "UploadFile", Route: Route{"PUT", "/users/{user_id}/files/{path...}"}, Status: 202,
+			Input: Fields{
+				{Name: "UserID", Column: "user_id", Type: "int64"},
+				{Name: "Content", Column: "content", Type: "[]byte", Tags: []Tag{{Key: "json", Value: "content"}}},
+			},
+		},
+	}
+	if !reflect.DeepEqual(ops, expected) {
+		t.Errorf("APIOperations() = %+v, expected %+v", ops, expected)
+	}
+}
+
+func TestOpenAPISpec(t *testing.T) {
+	entities := map[string]Fields{
+		"User": {
+			{Name: "ID", Type: "int64", Tags: []Tag{{Key: "json", Value: "id"}}},
+			{Name: "Email", Type: "string", Tags: []Tag{{Key: "json", Value: "email"}}},
+			{Name: "Nickname", Type: "*string", Optional: true, Tags: []Tag{{Key: "json", Value: "nickname,omitempty"}}},
+			{Name: "CreatedAt", Type: "time.Time", Tags: []Tag{{Key: "json", Value: "created_at"}}},
+			{Name: "Roles", Type: "map[string]bool", Tags: []Tag{{Key: "json", Value: "roles"}}},
+			{Name: "Secret", Type: "string", Tags: []Tag{{Key: "json", Value: "-"}}},
+		},
+	}
+	ops := []APIOperation{
+		{
+			Handler: "CreateUserHandler", UseCase: "CreateUser", Route: Route{"POST", "/users"}, Status: 201,
+			Input:  Fields{{Name: "Email", Type: "string"}, {Name: "Tags", Type: "[]string"}},
+			Output: "*entities.User",
+		},
+		{
+			Handler: "ListUsersHandler", UseCase: "ListUsers", Route: Route{"GET", "/users"}, Status: 200, Output: "[]*entities.User",
+			Query: []string{"sort", "email", "after", "limit", "fields"},
+		},
+		{Handler: "DeleteUserHandler", UseCase: "DeleteUser", Route: Route{"DELETE", "/users/{id}"}, Status: 204, Input: Fields{{Name: "ID", Type: "int64"}}, Output: "*entities.User"},
+	}
+
+	spec, err := OpenAPISpec("shop", "1.0.0", entities, ops)
+	if err != nil {
+		t.Fatalf("OpenAPISpec() failed: %v", err)
+	}
+	for _, want := range []string{
+		"openapi: 3.0.3\ninfo:\n  title: shop\n  version: 1.0.0\n",
+		"    post:\n      tags:\n        - users\n      summary: Create user\n      operationId: CreateUser\n",
+		"              $ref: '#/components/schemas/CreateUserInput'\n",
+		"        \"201\":\n          description: Created\n          content:\n            application/json:\n              schema:\n                $ref: '#/components/schemas/User'\n",
+		"        \"409\":\n          $ref: '#/components/responses/Conflict'\n",
+		"                type: array\n                nullable: true\n                items:\n                  $ref: '#/components/schemas/User'\n",
+		"        - name: id\n          in: path\n          required: true\n          schema:\n            type: integer\n            format: int64\n",
+		"        \"204\":\n          description: No Content\n        \"400\":\n",
+		"    CreateUserInput:\n      type: object\n      properties:\n        Email:\n          type: string\n        Tags:\n          type: array\n          nullable: true\n          items:\n            type: string\n",
+		"        nickname:\n          type: string\n          nullable: true\n        created_at:\n          type: string\n          format: date-time\n        roles:\n          type: object\n          nullable: true\n          additionalProperties:\n            type: boolean\n      required:\n        - id\n        - email\n        - created_at\n        - roles\n",
+		"    NotFound:\n      description: Not Found\n",
+		"        - name: sort\n          in: query\n          description: Field to sort by, descending when prefixed with -\n          required: false\n          schema:\n            type: string\n",
+		"        - name: email\n          in: query\n          description: Only results whose email equals it\n          required: false\n          schema:\n            type: string\n",
+		"        - name: after\n          in: query\n          description: ID the results start after\n          required: false\n          schema:\n            type: integer\n            format: int64\n",
+		"        - name: limit\n          in: query\n          description: Maximum number of results\n          required: false\n          schema:\n            type: integer\n            format: int64\n",
+		"        - name: fields\n          in: query\n          required: false\n          schema:\n            type: string\n",
+	} {
+		if !strings.Contains(string(spec), want) {
+			t.Errorf("Spec is missing %q:\n%s", want, spec)
+		}
+	}
+	if strings.Contains(string(spec), "Secret") {
+		t.Errorf("Spec documents a field encoding/json skips:\n%s", spec)
+	}
+	_, list, _ := strings.Cut(string(spec), "operationId: ListUsers\n")
+	if list, _, _ = strings.Cut(list, "operationId:"); strings.Contains(list, "\"404\"") {
+		t.Errorf("Route without parameters documents 404:\n%s", spec)
+	}
+	if !strings.Contains(list, "\"400\":\n          $ref: '#/components/responses/BadRequest'") {
+		t.Errorf("A List with a filter should document the 400 of invalid filters:\n%s", spec)
+	}
+}
@@ -0,0 +1,357 @@
+package internal
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Patcher adds code to an existing Go file. The places to patch are found
+// with go/ast and the code is inserted as text, so everything else in the
+// file, comments and hand written code included, is kept byte for byte
+// before the result is gofmt-ed.
+type Patcher struct {
+	Fset *token.FileSet
+	File *ast.File
+
+	src     []byte
+	edits   []sourceEdit
+	imports []importSpec
+}
+
+type importSpec struct {
+	name string
+	path string
+}
+
+type sourceEdit struct {
+	offset int
+	end    int // the source up to end is replaced, when after offset
+	text   string
+}
+
+// NewPatcher parses src, named filename in error messages
+func NewPatcher(filename string, src []byte) (*Patcher, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Patcher{Fset: fset, File: file, src: src}, nil
+}
+
+// Func returns the top level function called name, or nil
+func (p *Patcher) Func(name string) *ast.FuncDecl {
+	for _, decl := range p.File.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == name {
+			return fn
+		}
+	}
+	return nil
+}
+
+// Method returns the method name of the type recv, or nil
+func (p *Patcher) Method(recv, name string) *ast.FuncDecl {
+	for _, decl := range p.File.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv == nil || fn.Name.Name != name {
+			continue
+		}
+		typ := fn.Recv.List[0].Type
+		if star, ok := typ.(*ast.StarExpr); ok {
+			typ = star.X
+		}
+		if ident, ok := typ.(*ast.Ident); ok && ident.Name == recv {
+			return fn
+		}
+	}
+	return nil
+}
+
+// Struct returns the struct type declared as name, or nil
+func (p *Patcher) Struct(name string) *ast.StructType {
+	for _, decl := range p.File.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			if ts := spec.(*ast.TypeSpec); ts.Name.Name == name {
+				st, _ := ts.Type.(*ast.StructType)
+				return st
+			}
+		}
+	}
+	return nil
+}
+
+// Insert adds code at pos. Insertions at the same position keep the order
+// they were made in.
+func (p *Patcher) Insert(pos token.Pos, code string) {
+	p.edits = append(p.edits, sourceEdit{offset: p.Fset.Position(pos).Offset, text: code})
+}
+
+// InsertBefore adds code on its own lines before node
+func (p *Patcher) InsertBefore(node ast.Node, code string) {
+	p.Insert(node.Pos(), strings.TrimSuffix(code, "\n")+"\n")
+}
+
+// InsertAbove adds code on its own lines before node and the comment right
+// above it
+func (p *Patcher) InsertAbove(node ast.Node, code string) {
+	p.Insert(p.commentedPos(node), strings.TrimSuffix(code, "\n")+"\n")
+}
+
+// commentedPos returns where node starts, including the comment on the
+// lines right above it, indented like node
+func (p *Patcher) commentedPos(node ast.Node) token.Pos {
+	pos := node.Pos()
+	column := p.Fset.Position(pos).Column
+	for i := len(p.File.Comments) - 1; i >= 0; i-- {
+		group := p.File.Comments[i]
+		if group.End() > pos {
+			continue
+		}
+		start := p.Fset.Position(group.Pos())
+		if p.Fset.Position(group.End()).Line+1 != p.Fset.Position(pos).Line || start.Column != column {
+			break
+		}
+		pos = group.Pos()
+	}
+	return pos
+}
+
+// InsertAfter adds code on its own lines after node
+func (p *Patcher) InsertAfter(node ast.Node, code string) {
+	p.Insert(node.End(), "\n"+strings.TrimSuffix(code, "\n"))
+}
+
+// Replace swaps node and the comment right above it for code
+func (p *Patcher) Replace(node ast.Node, code string) {
+	p.edits = append(p.edits, sourceEdit{
+		offset: p.Fset.Position(p.commentedPos(node)).Offset,
+		end:    p.Fset.Position(node.End()).Offset,
+		text:   strings.TrimSuffix(code, "\n"),
+	})
+}
+
+// AddField appends field declarations to st
+func (p *Patcher) AddField(st *ast.StructType, code string) {
+	p.Insert(st.Fields.Closing, p.lineStart(st.Fields.Closing)+strings.TrimSuffix(code, "\n")+"\n")
+}
+
+// AddElements appends elements (`key: value`) to a composite literal, one
+// per line
+func (p *Patcher) AddElements(lit *ast.CompositeLit, elems ...string) {
+	if len(elems) == 0 {
+		return
+	}
+
+	prefix := p.lineStart(lit.Rbrace)
+	if n := len(lit.Elts); n > 0 {
+		if prefix != "" {
+			// Break a single line literal so every element gets its own line
+			p.Insert(lit.Lbrace+1, "\n")
+		}
+		between := p.src[p.offset(lit.Elts[n-1].End()):p.offset(lit.Rbrace)]
+		if !bytes.Contains(between, []byte(",")) {
+			prefix = "," + prefix
+		}
+	}
+	p.Insert(lit.Rbrace, prefix+strings.Join(elems, ",\n")+",\n")
+}
+
+// AddImport imports path unless the file already does
+func (p *Patcher) AddImport(path string) {
+	p.AddNamedImport("", path)
+}
+
+// AddNamedImport imports path as name, e.g. _ for a blank import, unless
+// the file already imports path
+func (p *Patcher) AddNamedImport(name, path string) {
+	for _, spec := range p.File.Imports {
+		if imported, _ := strconv.Unquote(spec.Path.Value); imported == path {
+			return
+		}
+	}
+	for _, pending := range p.imports {
+		if pending.path == path {
+			return
+		}
+	}
+	p.imports = append(p.imports, importSpec{name: name, path: path})
+}
+
+// Changed reports whether anything was added
+func (p *Patcher) Changed() bool {
+	return len(p.edits) > 0 || len(p.imports) > 0
+}
+
+// Bytes returns the patched source, gofmt-ed. Without changes the original
+// source is returned untouched.
+func (p *Patcher) Bytes() ([]byte, error) {
+	if !p.Changed() {
+		return p.src, nil
+	}
+
+	p.insertImports()
+
+	edits := append([]sourceEdit(nil), p.edits...)
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].offset < edits[j].offset })
+
+	var out bytes.Buffer
+	last := 0
+	for _, edit := range edits {
+		out.Write(p.src[last:edit.offset])
+		out.WriteString(edit.text)
+		last = max(edit.offset, edit.end)
+	}
+	out.Write(p.src[last:])
+
+	return format.Source(out.Bytes())
+}
+
+// insertImports turns the pending imports into edits. Standard library
+// packages join the standard library group and the rest go to a group of
+// their own, like goimports does.
+func (p *Patcher) insertImports() {
+	if len(p.imports) == 0 {
+		return
+	}
+
+	var std, others []string
+	for _, spec := range p.imports {
+		line := strings.TrimSpace(spec.name + " " + strconv.Quote(spec.path))
+		if isStdImport(spec.path) {
+			std = append(std, line)
+		} else {
+			others = append(others, line)
+		}
+	}
+	p.imports = nil
+
+	var decl *ast.GenDecl
+	for _, d := range p.File.Decls {
+		if gen, ok := d.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			decl = gen
+			break
+		}
+	}
+	if decl == nil {
+		groups := strings.Join(std, "\n")
+		if len(std) > 0 && len(others) > 0 {
+			groups += "\n\n"
+		}
+		groups += strings.Join(others, "\n")
+		p.Insert(p.File.Name.End(), "\n\nimport (\n"+groups+"\n)")
+		return
+	}
+
+	var lastStd ast.Spec
+	hasOthers := false
+	for _, spec := range decl.Specs {
+		path, _ := strconv.Unquote(spec.(*ast.ImportSpec).Path.Value)
+		if isStdImport(path) {
+			lastStd = spec
+		} else {
+			hasOthers = true
+		}
+	}
+
+	closing := decl.Rparen
+	if !decl.Lparen.IsValid() {
+		p.Insert(decl.Specs[0].Pos(), "(\n")
+		closing = decl.End()
+	}
+
+	switch {
+	case len(std) == 0:
+	case lastStd != nil:
+		p.Insert(lastStd.End(), "\n"+strings.Join(std, "\n"))
+	case decl.Lparen.IsValid():
+		p.Insert(decl.Lparen+1, "\n"+strings.Join(std, "\n")+"\n")
+	default:
+		p.Insert(decl.Specs[0].Pos(), strings.Join(std, "\n")+"\n\n")
+	}
+
+	if len(others) > 0 {
+		prefix := p.lineStart(closing)
+		if !hasOthers {
+			prefix += "\n"
+		}
+		if !decl.Lparen.IsValid() {
+			prefix = "\n\n"
+		}
+		p.Insert(closing, prefix+strings.Join(others, "\n")+"\n")
+	}
+
+	if !decl.Lparen.IsValid() {
+		p.Insert(closing, "\n)")
+	}
+}
+
+// isStdImport reports whether path belongs to the standard library
+func isStdImport(path string) bool {
+	first, _, _ := strings.Cut(path, "/")
+	return !strings.Contains(first, ".")
+}
+
+func (p *Patcher) offset(pos token.Pos) int {
+	return p.Fset.Position(pos).Offset
+}
+
+// lineStart returns a newline unless pos already starts a line
+func (p *Patcher) lineStart(pos token.Pos) string {
+	offset := p.offset(pos)
+	if i := bytes.LastIndexByte(p.src[:offset], '\n'); i >= 0 && strings.TrimSpace(string(p.src[i:offset])) == "" {
+		return ""
+	}
+	return "\n"
+}
+
+// DeclaredNames returns the variables declared directly in body
+func DeclaredNames(body *ast.BlockStmt) map[string]bool {
+	names := map[string]bool{}
+	for _, stmt := range body.List {
+		switch s := stmt.(type) {
+		case *ast.AssignStmt:
+			if s.Tok != token.DEFINE {
+				continue
+			}
+			for _, lhs := range s.Lhs {
+				if ident, ok := lhs.(*ast.Ident); ok {
+					names[ident.Name] = true
+				}
+			}
+		case *ast.DeclStmt:
+			gen, ok := s.Decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				for _, name := range spec.(*ast.ValueSpec).Names {
+					names[name.Name] = true
+				}
+			}
+		}
+	}
+	return names
+}
+
+// Uses reports whether node refers to the identifier name
+func Uses(node ast.Node, name string) bool {
+	found := false
+	ast.Inspect(node, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Ident); ok && ident.Name == name {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
@@ -0,0 +1,135 @@
+package internal
+
+import (
+	"bytes"
+	"go/ast"
+	"strings"
+	"testing"
+)
+
+func TestPatcher(t *testing.T) {
+	tests := []struct {
+		name  string
+		src   string
+		patch func(p *Patcher)
+		want  []string
+	}{
+		{
+			name: "Import into a group",
+			src:  "package a\n\nimport (\n\t\"fmt\"\n)\n\nvar _ = fmt.Sprint\n",
+			patch: func(p *Patcher) {
+				p.AddImport("os")
+				p.AddImport("os")
+				p.AddImport("fmt")
+			},
+			want: []string{"import (\n\t\"fmt\"\n\t\"os\"\n)"},
+		},
+		{
+			name:  "Single import",
+			src:   "package a\n\nimport \"fmt\"\n",
+			patch: func(p *Patcher) { p.AddImport("example.com/x") },
+			want:  []string{"import (\n\t\"fmt\"\n\n\t\"example.com/x\"\n)"},
+		},
+		{
+			name: "Standard library and module groups",
+			src:  "package a\n\nimport (\n\t\"fmt\"\n)\n",
+			patch: func(p *Patcher) {
+				p.AddImport("example.com/x")
+				p.AddNamedImport("_", "example.com/driver")
+				p.AddImport("os")
+			},
+			want: []string{"import (\n\t\"fmt\"\n\t\"os\"\n\n\t_ \"example.com/driver\"\n\t\"example.com/x\"\n)"},
+		},
+		{
+			name:  "Standard library before a single module import",
+			src:   "package a\n\nimport \"example.com/x\"\n",
+			patch: func(p *Patcher) { p.AddImport("os") },
+			want:  []string{"import (\n\t\"os\"\n\n\t\"example.com/x\"\n)"},
+		},
+		{
+			name: "Replace a statement and its comment",
+			src:  "package a\n\nfunc f() int {\n\t// zero for now\n\tx := 0\n\treturn x\n}\n",
+			patch: func(p *Patcher) {
+				p.Replace(p.Func("f").Body.List[0], "// one\nx := 1")
+			},
+			want: []string{"func f() int {\n\t// one\n\tx := 1\n\treturn x\n}"},
+		},
+		{
+			name:  "No imports",
+			src:   "package a\n",
+			patch: func(p *Patcher) { p.AddImport("os") },
+			want:  []string{"package a\n\nimport (\n\t\"os\"\n)"},
+		},
+		{
+			name: "Struct fields and literal elements",
+			src:  "package a\n\ntype T struct{ A int }\n\nvar v = T{A: 1}\n",
+			patch: func(p *Patcher) {
+				p.AddField(p.Struct("T"), "B int\nC int")
+				lit := p.File.Decls[1].(*ast.GenDecl).Specs[0].(*ast.ValueSpec).Values[0].(*ast.CompositeLit)
+				p.AddElements(lit, "B: 2", "C: 3")
+			},
+			want: []string{"type T struct {\n\tA int\n\tB int\n\tC int\n}", "T{\n\tA: 1,\n\tB: 2,\n\tC: 3,\n}"},
+		},
+		{
+			name: "Statements around a node",
+			src:  "package a\n\nfunc f() {\n\t// keep me\n\tx := 1\n\t_ = x\n}\n",
+			patch: func(p *Patcher) {
+				body := p.Func("f").Body
+				p.InsertBefore(body.List[0], "y := 2")
+				p.InsertAfter(body.List[1], "_ = y")
+			},
+			want: []string{"\t// keep me\n\ty := 2\n\tx := 1\n\t_ = x\n\t_ = y\n}"},
+		},
+		{
+			name: "Statements above a commented node",
+			src:  "package a\n\nfunc f() {\n\tx := 1 // not above\n\t// Use x\n\t_ = x\n}\n",
+			patch: func(p *Patcher) {
+				body := p.Func("f").Body
+				p.InsertAbove(body.List[0], "y := 2")
+				p.InsertAbove(body.List[1], "_ = y")
+			},
+			want: []string{"\ty := 2\n\tx := 1 // not above\n\t_ = y\n\t// Use x\n\t_ = x\n}"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewPatcher("a.go", []byte(tt.src))
+			if err != nil {
+				t.Fatalf("NewPatcher() failed: %v", err)
+			}
+			tt.patch(p)
+
+			got, err := p.Bytes()
+			if err != nil {
+				t.Fatalf("Bytes() failed: %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(got), want) {
+					t.Errorf("Patched source is missing %q:\n%s", want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestPatcher_Unchanged(t *testing.T) {
+	// Not gofmt-ed on purpose: without changes the source is returned as is
+	src := []byte("package a\nvar  x = 1\n")
+
+	p, err := NewPatcher("a.go", src)
+	if err != nil {
+		t.Fatalf("NewPatcher() failed: %v", err)
+	}
+
+	if p.Changed() {
+		t.Error("Changed() = true for a patcher without edits")
+	}
+	if got, _ := p.Bytes(); !bytes.Equal(got, src) {
+		t.Errorf("Bytes() = %q, want the original source", got)
+	}
+
+	if _, err := NewPatcher("a.go", []byte("package")); err == nil {
+		t.Error("Expected an error for invalid Go source")
+	}
+}
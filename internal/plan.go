@@ -0,0 +1,241 @@
+package internal
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileChange is a file a command is about to create or modify
+type FileChange struct {
+	Path    string
+	Content []byte
+	Old     []byte // current content on disk
+	Exists  bool
+	Patch   bool // Content only adds to Old, see Plan.AddPatch
+	Written bool // set once Apply wrote the file
+}
+
+// Unchanged reports whether the file already has the generated content
+func (c *FileChange) Unchanged() bool {
+	return c.Exists && bytes.Equal(c.Old, c.Content)
+}
+
+// mode returns how an existing file is handled under mode. Patches keep
+// everything the file had, so they are applied unless the user asked to
+// decide file by file.
+func (c *FileChange) mode(mode OverwriteMode) OverwriteMode {
+	if c.Patch && mode != OverwritePrompt {
+		return OverwriteForce
+	}
+	return mode
+}
+
+// Plan collects everything a command would write so it can be reviewed
+// (dry-run) or committed in one go
+type Plan struct {
+	Dirs    []string
+	Changes []*FileChange
+}
+
+// AddDir records a directory that has to exist after the plan is applied
+func (p *Plan) AddDir(path string) {
+	p.Dirs = append(p.Dirs, path)
+}
+
+// AddFile records content for path, reading what is currently on disk
+func (p *Plan) AddFile(path string, content []byte) (*FileChange, error) {
+	change := &FileChange{Path: path, Content: content}
+
+	old, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		change.Old, change.Exists = old, true
+	case !os.IsNotExist(err):
+		return nil, err
+	}
+
+	p.Changes = append(p.Changes, change)
+	return change, nil
+}
+
+// AddPatch records content for an existing file that was built by adding code
+// to what is on disk. Unlike AddFile, it does not need --force to be applied.
+func (p *Plan) AddPatch(path string, content []byte) (*FileChange, error) {
+	change, err := p.AddFile(path, content)
+	if err != nil {
+		return nil, err
+	}
+	change.Patch = true
+	return change, nil
+}
+
+// Change returns the change recorded for path, or nil
+func (p *Plan) Change(path string) *FileChange {
+	for _, c := range p.Changes {
+		if c.Path == path {
+			return c
+		}
+	}
+	return nil
+}
+
+// AddTemplate renders tplPath with data and records the result for outPath
+func (p *Plan) AddTemplate(baseFS embed.FS, tplPath, outPath string, data any) (*FileChange, error) {
+	content, err := RenderTemplate(baseFS, tplPath, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.AddFile(outPath, content)
+}
+
+// Print describes the plan without touching the filesystem. New files are
+// shown in full and existing ones as a unified diff against the disk.
+func (p *Plan) Print(w io.Writer, mode OverwriteMode) {
+	for _, dir := range p.Dirs {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			continue
+		}
+		fmt.Fprintf(w, "+ mkdir %s\n", dir)
+	}
+
+	for _, c := range p.Changes {
+		switch mode := c.mode(mode); {
+		case c.Unchanged():
+			fmt.Fprintf(w, "= unchanged %s\n", c.Path)
+			continue
+		case !c.Exists:
+			fmt.Fprintf(w, "+ create %s\n", c.Path)
+			fmt.Fprint(w, UnifiedDiff("/dev/null", "b/"+c.Path, nil, c.Content))
+			continue
+		case mode == OverwriteSkip:
+			fmt.Fprintf(w, "= skip %s (already exists)\n", c.Path)
+			continue
+		case mode == OverwriteNever:
+			fmt.Fprintf(w, "! conflict %s (already exists, use --force to overwrite)\n", c.Path)
+		default:
+			fmt.Fprintf(w, "~ modify %s\n", c.Path)
+		}
+		fmt.Fprint(w, UnifiedDiff("a/"+c.Path, "b/"+c.Path, c.Old, c.Content))
+	}
+}
+
+// Apply writes the plan to disk. Conflicts are resolved for every file
+// before anything is written, so a refused file leaves the disk untouched.
+func (p *Plan) Apply(opts WriteOptions) error {
+	var pending []*FileChange
+	var conflicts []string
+
+	for _, c := range p.Changes {
+		if c.Unchanged() {
+			continue
+		}
+		if c.Exists {
+			opts := opts
+			opts.Mode = c.mode(opts.Mode)
+			overwrite, err := resolveConflict(c, opts)
+			if err != nil {
+				return err
+			}
+			if opts.Mode == OverwriteNever {
+				conflicts = append(conflicts, c.Path)
+				continue
+			}
+			if !overwrite {
+				continue
+			}
+		}
+		pending = append(pending, c)
+	}
+
+	if len(conflicts) > 0 {
+		return fmt.Errorf("%s: %w (use --force to overwrite or --skip-existing to keep it)",
+			strings.Join(conflicts, ", "), ErrFileExists)
+	}
+
+	for _, dir := range p.Dirs {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+
+	for _, c := range pending {
+		if err := os.MkdirAll(filepath.Dir(c.Path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(c.Path, c.Content, 0644); err != nil {
+			return err
+		}
+		c.Written = true
+	}
+
+	return nil
+}
+
+// resolveConflict decides whether an existing file may be replaced
+func resolveConflict(c *FileChange, opts WriteOptions) (bool, error) {
+	switch opts.Mode {
+	case OverwriteForce:
+		return true, nil
+	case OverwritePrompt:
+		return promptOverwrite(c, opts)
+	default:
+		return false, nil
+	}
+}
+
+func promptOverwrite(c *FileChange, opts WriteOptions) (bool, error) {
+	in, out := opts.In, opts.Out
+	if in == nil {
+		in = os.Stdin
+	}
+	if out == nil {
+		out = os.Stdout
+	}
+
+	for {
+		fmt.Fprintf(out, "%s already exists. [o]verwrite, [s]kip, [d]iff? ", c.Path)
+		answer, err := readLine(in)
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "o", "overwrite":
+			return true, nil
+		case "s", "skip":
+			return false, nil
+		case "d", "diff":
+			fmt.Fprint(out, UnifiedDiff("a/"+c.Path, "b/"+c.Path, c.Old, c.Content))
+			continue
+		}
+		if err == io.EOF {
+			// Nobody is there to answer, keep the file as it is
+			fmt.Fprintln(out)
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+}
+
+// readLine reads a single line byte by byte so that no input meant for a
+// later prompt gets buffered away
+func readLine(r io.Reader) (string, error) {
+	var line []byte
+	b := make([]byte, 1)
+	for {
+		n, err := r.Read(b)
+		if n > 0 {
+			if b[0] == '\n' {
+				return string(line), nil
+			}
+			line = append(line, b[0])
+		}
+		if err != nil {
+			return string(line), err
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package internal
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fsjorgeluis/sazerac/internal/templates"
+)
+
+func TestPlan_PrintDoesNotTouchDisk(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.go")
+	os.WriteFile(existing, []byte("package a\n"), 0644)
+
+	plan := &Plan{}
+	plan.AddDir(filepath.Join(dir, "empty"))
+	if _, err := plan.AddFile(filepath.Join(dir, "new.go"), []byte("package b\n")); err != nil {
+		t.Fatalf("AddFile() failed: %v", err)
+	}
+	if _, err := plan.AddFile(existing, []byte("package b\n")); err != nil {
+		t.Fatalf("AddFile() failed: %v", err)
+	}
+
+	var out bytes.Buffer
+	plan.Print(&out, OverwriteForce)
+
+	for _, want := range []string{"+ mkdir", "+ create", "+package b", "~ modify", "-package a"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Print() output is missing %q:\n%s", want, out.String())
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "new.go")); !os.IsNotExist(err) {
+		t.Error("Print() created a file")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "empty")); !os.IsNotExist(err) {
+		t.Error("Print() created a directory")
+	}
+	if content, _ := os.ReadFile(existing); string(content) != "package a\n" {
+		t.Error("Print() modified an existing file")
+	}
+}
+
+func TestPlan_PrintReportsConflicts(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.go")
+	os.WriteFile(existing, []byte("package a\n"), 0644)
+
+	tests := []struct {
+		mode OverwriteMode
+		want string
+	}{
+		{OverwriteNever, "! conflict"},
+		{OverwriteSkip, "= skip"},
+		{OverwriteForce, "~ modify"},
+	}
+
+	for _, tt := range tests {
+		plan := &Plan{}
+		plan.AddFile(existing, []byte("package b\n"))
+
+		var out bytes.Buffer
+		plan.Print(&out, tt.mode)
+		if !strings.HasPrefix(out.String(), tt.want) {
+			t.Errorf("Print() with mode %d = %q, expected prefix %q", tt.mode, out.String(), tt.want)
+		}
+	}
+}
+
+func TestPlan_ApplyChecksConflictsFirst(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.go")
+	created := filepath.Join(dir, "sub", "created.go")
+	os.WriteFile(existing, []byte("package a\n"), 0644)
+
+	plan := &Plan{}
+	plan.AddFile(created, []byte("package b\n"))
+	plan.AddFile(existing, []byte("package b\n"))
+
+	if err := plan.Apply(WriteOptions{}); !errors.Is(err, ErrFileExists) {
+		t.Fatalf("Apply() error = %v, expected ErrFileExists", err)
+	}
+	if _, err := os.Stat(created); !os.IsNotExist(err) {
+		t.Error("Apply() wrote files although another one was refused")
+	}
+
+	if err := plan.Apply(WriteOptions{Mode: OverwriteForce}); err != nil {
+		t.Fatalf("Apply() with OverwriteForce failed: %v", err)
+	}
+	for _, c := range plan.Changes {
+		if !c.Written {
+			t.Errorf("Apply() did not write %s", c.Path)
+		}
+	}
+}
+
+func TestPlan_ApplyPatchesWithoutForce(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.go")
+	os.WriteFile(existing, []byte("package a\n"), 0644)
+
+	plan := &Plan{}
+	change, err := plan.AddPatch(existing, []byte("package a\n\nvar b = 1\n"))
+	if err != nil {
+		t.Fatalf("AddPatch() failed: %v", err)
+	}
+
+	var out bytes.Buffer
+	plan.Print(&out, OverwriteNever)
+	if !strings.Contains(out.String(), "~ modify") {
+		t.Errorf("Print() should show the patch as a modification:\n%s", out.String())
+	}
+
+	if err := plan.Apply(WriteOptions{Mode: OverwriteNever}); err != nil {
+		t.Fatalf("Apply() failed: %v", err)
+	}
+	if !change.Written {
+		t.Error("Apply() did not write the patch")
+	}
+
+	// Interactive mode still asks
+	plan = &Plan{}
+	plan.AddPatch(existing, []byte("package a\n"))
+	err = plan.Apply(WriteOptions{Mode: OverwritePrompt, In: strings.NewReader("s\n"), Out: &out})
+	if err != nil {
+		t.Fatalf("Apply() failed: %v", err)
+	}
+	if content, _ := os.ReadFile(existing); string(content) != "package a\n\nvar b = 1\n" {
+		t.Error("Apply() ignored the answer to the prompt")
+	}
+}
+
+func TestPlan_ApplyOverwriteModes(t *testing.T) {
+	data := map[string]any{"Name": "User"}
+	original := []byte("package entities\n\n// hand written\n")
+
+	tests := []struct {
+		name        string
+		opts        WriteOptions
+		wantWritten bool
+		wantErr     error
+	}{
+		{
+			name:    "Refuses by default",
+			opts:    WriteOptions{},
+			wantErr: ErrFileExists,
+		},
+		{
+			name:        "Force overwrites",
+			opts:        WriteOptions{Mode: OverwriteForce},
+			wantWritten: true,
+		},
+		{
+			name: "Skip keeps the file",
+			opts: WriteOptions{Mode: OverwriteSkip},
+		},
+		{
+			name:        "Prompt overwrite after diff",
+			opts:        WriteOptions{Mode: OverwritePrompt, In: strings.NewReader("d\no\n"), Out: io.Discard},
+			wantWritten: true,
+		},
+		{
+			name: "Prompt skip",
+			opts: WriteOptions{Mode: OverwritePrompt, In: strings.NewReader("s\n"), Out: io.Discard},
+		},
+		{
+			name: "Prompt without answer skips",
+			opts: WriteOptions{Mode: OverwritePrompt, In: strings.NewReader(""), Out: io.Discard},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := filepath.Join(t.TempDir(), "user.go")
+			if err := os.WriteFile(out, original, 0644); err != nil {
+				t.Fatalf("Failed to write existing file: %v", err)
+			}
+
+			plan := &Plan{}
+			change, err := plan.AddTemplate(templates.FS, "entity/entity.go.tpl", out, data)
+			if err != nil {
+				t.Fatalf("AddTemplate() failed: %v", err)
+			}
+			if err := plan.Apply(tt.opts); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Apply() error = %v, expected %v", err, tt.wantErr)
+			}
+			if change.Written != tt.wantWritten {
+				t.Errorf("Apply() written = %v, expected %v", change.Written, tt.wantWritten)
+			}
+
+			content, _ := os.ReadFile(out)
+			if changed := string(content) != string(original); changed != tt.wantWritten {
+				t.Errorf("File changed = %v, expected %v", changed, tt.wantWritten)
+			}
+		})
+	}
+}
+
+func TestPlan_ApplyCreatesAndKeepsUnchanged(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "nested", "user.go")
+	data := map[string]any{"Name": "User"}
+
+	apply := func() (bool, error) {
+		plan := &Plan{}
+		change, err := plan.AddTemplate(templates.FS, "entity/entity.go.tpl", out, data)
+		if err != nil {
+			return false, err
+		}
+		err = plan.Apply(WriteOptions{})
+		return change.Written, err
+	}
+
+	written, err := apply()
+	if err != nil || !written {
+		t.Fatalf("Apply() = %v, %v, expected file to be created", written, err)
+	}
+
+	// Same content again must not be reported as a conflict
+	written, err = apply()
+	if err != nil {
+		t.Fatalf("Apply() with unchanged content failed: %v", err)
+	}
+	if written {
+		t.Error("Apply() rewrote a file whose content did not change")
+	}
+}
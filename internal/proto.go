@@ -0,0 +1,303 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ProtoPackage is the .proto file declaring the messages and services of an
+// entity, and the Go package protoc generates from it
+type ProtoPackage struct {
+	Path     string // proto/user/v1/user.proto
+	Package  string // user.v1
+	GoImport string // <module>/gen/user/v1
+	GoName   string // userv1
+}
+
+// EntityProto returns the .proto file of the services of entity, laid out as
+// buf expects: proto/<entity>/v1/<entity>.proto, generated into gen
+func EntityProto(module, entity string) ProtoPackage {
+	name := strings.ToLower(strings.Join(splitWords(entity), "_"))
+	return ProtoPackage{
+		Path:     "proto/" + name + "/v1/" + name + ".proto",
+		Package:  name + ".v1",
+		GoImport: module + "/gen/" + name + "/v1",
+		GoName:   strings.ReplaceAll(name, "_", "") + "v1",
+	}
+}
+
+// protoScalar is the protobuf type of a Go type and the Go type protoc-gen-go
+// generates for it
+type protoScalar struct {
+	Proto string
+	Go    string
+}
+
+// protoScalars maps the Go types of fields to protobuf types
+var protoScalars = map[string]protoScalar{
+	"string":          {"string", "string"},
+	"bool":            {"bool", "bool"},
+	"int":             {"int64", "int64"},
+	"int8":            {"int32", "int32"},
+	"int16":           {"int32", "int32"},
+	"int32":           {"int32", "int32"},
+	"int64":           {"int64", "int64"},
+	"uint":            {"uint64", "uint64"},
+	"uint8":           {"uint32", "uint32"},
+	"uint16":          {"uint32", "uint32"},
+	"uint32":          {"uint32", "uint32"},
+	"uint64":          {"uint64", "uint64"},
+	"float32":         {"float", "float32"},
+	"float64":         {"double", "float64"},
+	"[]byte":          {"bytes", "[]byte"},
+	"json.RawMessage": {"bytes", "[]byte"},
+	"time.Time":       {"google.protobuf.Timestamp", "*timestamppb.Timestamp"},
+}
+
+// timestampProto is the file declaring google.protobuf.Timestamp
+const timestampProto = "google/protobuf/timestamp.proto"
+
+// ProtoType returns the type of the field in a protobuf message, "" for Go
+// types protobuf has no counterpart for
+func (f Field) ProtoType() string {
+	if s, ok := protoScalars[f.Type]; ok {
+		return s.Proto
+	}
+	switch {
+	case strings.HasPrefix(f.Type, "*"):
+		s, ok := protoScalars[f.Type[1:]]
+		if !ok {
+			return ""
+		}
+		if f.Type == "*time.Time" {
+			// Messages can be missing already
+			return s.Proto
+		}
+		return "optional " + s.Proto
+	case strings.HasPrefix(f.Type, "[]"):
+		if s, ok := protoScalars[f.Type[2:]]; ok {
+			return "repeated " + s.Proto
+		}
+	case strings.HasPrefix(f.Type, "map["):
+		key, value, _ := strings.Cut(f.Type[4:], "]")
+		k, ok := protoScalars[key]
+		v, ok2 := protoScalars[value]
+		// Keys are integers, bool or string
+		if ok && ok2 && k.Proto != "float" && k.Proto != "double" && k.Proto != "bytes" && key != "time.Time" {
+			return fmt.Sprintf("map<%s, %s>", k.Proto, v.Proto)
+		}
+	}
+	return ""
+}
+
+// ProtoName returns the name of the field in protobuf messages, in
+// snake_case (UserID is user_id)
+func (f Field) ProtoName() string {
+	return strings.ToLower(strings.Join(splitWords(f.Name), "_"))
+}
+
+// ProtoGoName returns the name protoc-gen-go gives the field in the Go
+// struct of a message (user_id is UserId)
+func (f Field) ProtoGoName() string {
+	var sb strings.Builder
+	for _, word := range strings.Split(f.ProtoName(), "_") {
+		sb.WriteString(ToPascalCase(word))
+	}
+	return sb.String()
+}
+
+// ToProto returns the Go expression converting v, a value of the field, to
+// its value in a protobuf message. It is "" when the conversion takes more
+// than an expression.
+func (f Field) ToProto(v string) string {
+	if f.ProtoType() == "" {
+		return ""
+	}
+	if s, ok := protoScalars[f.Type]; ok {
+		switch {
+		case f.Type == "time.Time":
+			return fmt.Sprintf("timestamppb.New(%s)", v)
+		case s.Go == f.Type || f.Type == "json.RawMessage":
+			return v
+		default:
+			return fmt.Sprintf("%s(%s)", s.Go, v)
+		}
+	}
+	if f.sameProtoGoType() {
+		return v
+	}
+	return ""
+}
+
+// SetProto returns the Go statements setting dest, a field of a protobuf
+// message, to v converted: an assignment of ToProto, a nil check for
+// pointers and a loop for slices and maps whose elements are converted. It is
+// "" for fields without a protobuf type.
+func (f Field) SetProto(v, dest string) string {
+	if value := f.ToProto(v); value != "" {
+		return fmt.Sprintf("%s = %s", dest, value)
+	}
+	if f.ProtoType() == "" {
+		return ""
+	}
+	convert := func(typ, x string) string {
+		return Field{Type: typ}.ToProto(x)
+	}
+	switch {
+	case f.Type == "*time.Time":
+		return fmt.Sprintf("if %s != nil {\n%s = %s\n}", v, dest, convert("time.Time", "*"+v))
+	case strings.HasPrefix(f.Type, "*"):
+		return fmt.Sprintf("if %s != nil {\nx := %s\n%s = &x\n}", v, convert(f.Type[1:], "*"+v), dest)
+	case strings.HasPrefix(f.Type, "[]"):
+		elem := f.Type[2:]
+		return fmt.Sprintf("%s = make([]%s, len(%s))\nfor i, x := range %s {\n%s[i] = %s\n}",
+			dest, protoScalars[elem].Go, v, v, dest, convert(elem, "x"))
+	default:
+		key, value, _ := strings.Cut(f.Type[4:], "]")
+		return fmt.Sprintf("%s = make(map[%s]%s, len(%s))\nfor k, x := range %s {\n%s[%s] = %s\n}",
+			dest, protoScalars[key].Go, protoScalars[value].Go, v, v, dest, convert(key, "k"), convert(value, "x"))
+	}
+}
+
+// FromProto returns the Go expression converting v, the value of the field
+// in a protobuf message, to the type of the field. It is "" when the
+// conversion takes more than an expression.
+func (f Field) FromProto(v string) string {
+	if f.ProtoType() == "" {
+		return ""
+	}
+	if s, ok := protoScalars[f.Type]; ok {
+		switch {
+		case f.Type == "time.Time":
+			return v + ".AsTime()"
+		case s.Go == f.Type || f.Type == "json.RawMessage":
+			return v
+		default:
+			return fmt.Sprintf("%s(%s)", f.Type, v)
+		}
+	}
+	if f.sameProtoGoType() {
+		return v
+	}
+	return ""
+}
+
+// sameProtoGoType reports whether a pointer, slice or map field has the Go
+// type protoc-gen-go generates for it
+func (f Field) sameProtoGoType() bool {
+	same := func(typ string) bool {
+		s, ok := protoScalars[typ]
+		return ok && s.Go == typ && typ != "time.Time"
+	}
+	switch {
+	case strings.HasPrefix(f.Type, "*"):
+		return same(f.Type[1:])
+	case strings.HasPrefix(f.Type, "[]"):
+		return same(f.Type[2:])
+	case strings.HasPrefix(f.Type, "map["):
+		key, value, _ := strings.Cut(f.Type[4:], "]")
+		return same(key) && same(value)
+	}
+	return false
+}
+
+// ProtoImports returns the .proto files the types of the fields are
+// declared in
+func (fs Fields) ProtoImports() []string {
+	for _, f := range fs {
+		if strings.Contains(f.ProtoType(), "google.protobuf.Timestamp") {
+			return []string{timestampProto}
+		}
+	}
+	return nil
+}
+
+// ProtoMessage returns the protobuf message called name with a field per
+// field. Fields protobuf has no type for are left as TODO comments.
+func ProtoMessage(name string, fields Fields) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "message %s {\n", name)
+	n := 0
+	for _, f := range fields {
+		typ := f.ProtoType()
+		if typ == "" {
+			fmt.Fprintf(&sb, "  // TODO: %s %s has no protobuf type\n", f.Name, f.Type)
+			continue
+		}
+		n++
+		fmt.Fprintf(&sb, "  %s %s = %d;\n", typ, f.ProtoName(), n)
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// ProtoService returns the protobuf service of a use case, with a single rpc
+// taking request and returning response
+func ProtoService(useCase, request, response string) string {
+	return fmt.Sprintf("service %sService {\n  rpc %s(%s) returns (%s);\n}\n", useCase, useCase, request, response)
+}
+
+var (
+	protoDeclRe    = regexp.MustCompile(`(?m)^\s*(?:message|service|enum)\s+(\w+)`)
+	protoImportRe  = regexp.MustCompile(`(?m)^import\s+(?:public\s+|weak\s+)?"([^"]+)";[^\n]*\n`)
+	protoPackageRe = regexp.MustCompile(`(?m)^package\s+[\w.]+;[^\n]*\n`)
+)
+
+// MergeProto adds the imports and the declarations (messages and services)
+// the .proto file src lacks. Declarations are matched by name, the ones src
+// has already are kept as they are.
+func MergeProto(src []byte, imports []string, decls ...string) ([]byte, error) {
+	content := string(src)
+
+	declared := map[string]bool{}
+	for _, m := range protoDeclRe.FindAllStringSubmatch(content, -1) {
+		declared[m[1]] = true
+	}
+	var missing []string
+	for _, decl := range decls {
+		m := protoDeclRe.FindStringSubmatch(decl)
+		if m == nil {
+			return nil, fmt.Errorf("no message or service in %q", decl)
+		}
+		if !declared[m[1]] {
+			missing = append(missing, strings.TrimSpace(decl)+"\n")
+			declared[m[1]] = true
+		}
+	}
+
+	var imported []string
+	for _, m := range protoImportRe.FindAllStringSubmatch(content, -1) {
+		imported = append(imported, m[1])
+	}
+	var lines strings.Builder
+	for _, imp := range imports {
+		if !slices.Contains(imported, imp) {
+			fmt.Fprintf(&lines, "import %q;\n", imp)
+			imported = append(imported, imp)
+		}
+	}
+
+	if len(missing) == 0 && lines.Len() == 0 {
+		return src, nil
+	}
+
+	if lines.Len() > 0 {
+		// After the last import, or in a block of their own after package
+		if locs := protoImportRe.FindAllStringIndex(content, -1); len(locs) > 0 {
+			end := locs[len(locs)-1][1]
+			content = content[:end] + lines.String() + content[end:]
+		} else if loc := protoPackageRe.FindStringIndex(content); loc != nil {
+			content = content[:loc[1]] + "\n" + lines.String() + content[loc[1]:]
+		} else {
+			return nil, fmt.Errorf("no package statement to add the imports after")
+		}
+	}
+
+	content = strings.TrimRight(content, "\n") + "\n"
+	for _, decl := range missing {
+		content += "\n" + decl
+	}
+	return []byte(content), nil
+}
@@ -0,0 +1,126 @@
+package internal
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestField_ProtoType(t *testing.T) {
+	tests := []struct {
+		typ     string
+		proto   string
+		toProto string
+		fromGo  string
+		set     string
+	}{
+		{"string", "string", "v", "v", "m.V = v"},
+		{"int", "int64", "int64(v)", "int(v)", "m.V = int64(v)"},
+		{"uint8", "uint32", "uint32(v)", "uint8(v)", "m.V = uint32(v)"},
+		{"float32", "float", "v", "v", "m.V = v"},
+		{"time.Time", "google.protobuf.Timestamp", "timestamppb.New(v)", "v.AsTime()", "m.V = timestamppb.New(v)"},
+		{"json.RawMessage", "bytes", "v", "v", "m.V = v"},
+		{"*string", "optional string", "v", "v", "m.V = v"},
+		{"*int", "optional int64", "", "", "if v != nil {\nx := int64(*v)\nm.V = &x\n}"},
+		{"*time.Time", "google.protobuf.Timestamp", "", "", "if v != nil {\nm.V = timestamppb.New(*v)\n}"},
+		{"[]string", "repeated string", "v", "v", "m.V = v"},
+		{"[]int", "repeated int64", "", "", "m.V = make([]int64, len(v))\nfor i, x := range v {\nm.V[i] = int64(x)\n}"},
+		{"[]time.Time", "repeated google.protobuf.Timestamp", "", "", "m.V = make([]*timestamppb.Timestamp, len(v))\nfor i, x := range v {\nm.V[i] = timestamppb.New(x)\n}"},
+		{"map[string]int64", "map<string, int64>", "v", "v", "m.V = v"},
+		{"map[int]string", "map<int64, string>", "", "", "m.V = make(map[int64]string, len(v))\nfor k, x := range v {\nm.V[int64(k)] = x\n}"},
+		{"map[float64]string", "", "", "", ""},
+		{"decimal.Decimal", "", "", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.typ, func(t *testing.T) {
+			f := Field{Name: "Value", Type: tt.typ}
+			if got := f.ProtoType(); got != tt.proto {
+				t.Errorf("ProtoType() = %q, expected %q", got, tt.proto)
+			}
+			if got := f.ToProto("v"); got != tt.toProto {
+				t.Errorf("ToProto() = %q, expected %q", got, tt.toProto)
+			}
+			if got := f.FromProto("v"); got != tt.fromGo {
+				t.Errorf("FromProto() = %q, expected %q", got, tt.fromGo)
+			}
+			if got := f.SetProto("v", "m.V"); got != tt.set {
+				t.Errorf("SetProto() = %q, expected %q", got, tt.set)
+			}
+		})
+	}
+}
+
+func TestField_ProtoName(t *testing.T) {
+	for name, expected := range map[string][2]string{
+		"ID":        {"id", "Id"},
+		"UserID":    {"user_id", "UserId"},
+		"CreatedAt": {"created_at", "CreatedAt"},
+		"HTTPCode":  {"http_code", "HttpCode"},
+	} {
+		f := Field{Name: name}
+		if f.ProtoName() != expected[0] || f.ProtoGoName() != expected[1] {
+			t.Errorf("%s: ProtoName() = %q and ProtoGoName() = %q, expected %q", name, f.ProtoName(), f.ProtoGoName(), expected)
+		}
+	}
+}
+
+func TestEntityProto(t *testing.T) {
+	proto := EntityProto("example.com/shop", "OrderItem")
+	expected := ProtoPackage{
+		Path:     "proto/order_item/v1/order_item.proto",
+		Package:  "order_item.v1",
+		GoImport: "example.com/shop/gen/order_item/v1",
+		GoName:   "orderitemv1",
+	}
+	if proto != expected {
+		t.Errorf("EntityProto() = %+v, expected %+v", proto, expected)
+	}
+}
+
+func TestMergeProto(t *testing.T) {
+	src := []byte("syntax = \"proto3\";\n\npackage user.v1;\n\noption go_package = \"example.com/shop/gen/user/v1;userv1\";\n")
+	fields := Fields{
+		{Name: "ID", Type: "string"},
+		{Name: "Balance", Type: "decimal.Decimal"},
+		{Name: "CreatedAt", Type: "time.Time"},
+	}
+
+	merged, err := MergeProto(src, fields.ProtoImports(),
+		ProtoMessage("User", fields),
+		ProtoMessage("CreateUserRequest", Fields{{Name: "Name", Type: "string"}}),
+		ProtoService("CreateUser", "CreateUserRequest", "User"))
+	if err != nil {
+		t.Fatalf("MergeProto() failed: %v", err)
+	}
+	for _, want := range []string{
+		"package user.v1;\n\nimport \"google/protobuf/timestamp.proto\";\n",
+		"message User {\n  string id = 1;\n  // TODO: Balance decimal.Decimal has no protobuf type\n  google.protobuf.Timestamp created_at = 2;\n}\n",
+		"message CreateUserRequest {\n  string name = 1;\n}\n",
+		"service CreateUserService {\n  rpc CreateUser(CreateUserRequest) returns (User);\n}\n",
+	} {
+		if !strings.Contains(string(merged), want) {
+			t.Errorf("Merged file is missing %q:\n%s", want, merged)
+		}
+	}
+
+	// Declarations the file has are kept, even when they changed
+	again, err := MergeProto(merged, []string{timestampProto},
+		ProtoMessage("User", Fields{{Name: "ID", Type: "int64"}}),
+		ProtoMessage("GetUserRequest", Fields{{Name: "ID", Type: "string"}}),
+		ProtoService("GetUser", "GetUserRequest", "User"))
+	if err != nil {
+		t.Fatalf("MergeProto() failed: %v", err)
+	}
+	if !bytes.HasPrefix(again, merged) || !strings.Contains(string(again), "service GetUserService {") {
+		t.Errorf("MergeProto() did not only append the new declarations:\n%s", again)
+	}
+	if n := strings.Count(string(again), "message User {"); n != 1 {
+		t.Errorf("User is declared %d times, expected once:\n%s", n, again)
+	}
+
+	unchanged, err := MergeProto(again, nil, ProtoService("GetUser", "GetUserRequest", "User"))
+	if err != nil || !bytes.Equal(unchanged, again) {
+		t.Errorf("Merging existing declarations changed the file:\n%s", unchanged)
+	}
+}
@@ -0,0 +1,182 @@
+package internal
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+// DomainError is a sentinel error generated in internal/domain/errors.go for
+// repositories to return
+type DomainError struct {
+	Name    string
+	Message string
+	Doc     string
+}
+
+// DomainErrors are the errors every repository implementation agrees on
+var DomainErrors = []DomainError{
+	{
+		Name:    "ErrNotFound",
+		Message: "not found",
+		Doc:     "ErrNotFound is returned by repositories when no record matches the query",
+	},
+	{
+		Name:    "ErrAlreadyExists",
+		Message: "already exists",
+		Doc:     "ErrAlreadyExists is returned by repositories when creating a record whose ID is taken",
+	},
+	{
+		Name:    "ErrInvalidFilter",
+		Message: "invalid filter",
+		Doc:     "ErrInvalidFilter is returned by repositories for filters they cannot run",
+	},
+	{
+		Name:    "ErrInvalidInput",
+		Message: "invalid input",
+		Doc:     "ErrInvalidInput is returned by validators and use cases for input that breaks their rules",
+	},
+}
+
+// MergeDomainErrors adds the DomainErrors the errors file in src does not
+// declare yet, keeping everything else
+func MergeDomainErrors(src []byte) ([]byte, error) {
+	p, err := NewPatcher("errors.go", src)
+	if err != nil {
+		return nil, err
+	}
+
+	declared := map[string]bool{}
+	for _, decl := range p.File.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.VAR {
+			for _, spec := range gen.Specs {
+				for _, name := range spec.(*ast.ValueSpec).Names {
+					declared[name.Name] = true
+				}
+			}
+		}
+	}
+
+	for _, e := range DomainErrors {
+		if declared[e.Name] {
+			continue
+		}
+		p.Insert(token.Pos(p.File.FileEnd), fmt.Sprintf("\n// %s\nvar %s = errors.New(%s)\n", e.Doc, e.Name, strconv.Quote(e.Message)))
+		p.AddImport("errors")
+	}
+
+	return p.Bytes()
+}
+
+// RepositoryMethods returns the methods of interface name declared in the Go
+// file at filePath, or nil when it is not declared there
+func RepositoryMethods(filePath, name string) ([]string, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), filePath, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var methods []string
+	ast.Inspect(file, func(n ast.Node) bool {
+		spec, ok := n.(*ast.TypeSpec)
+		if !ok || spec.Name.Name != name {
+			return methods == nil
+		}
+		if iface, ok := spec.Type.(*ast.InterfaceType); ok {
+			for _, m := range iface.Methods.List {
+				for _, ident := range m.Names {
+					methods = append(methods, ident.Name)
+				}
+			}
+		}
+		return false
+	})
+
+	return methods, nil
+}
+
+// Shared returns the fields whose copies share memory with the original:
+// pointers, slices and maps. The memory repositories clone them.
+func (fs Fields) Shared() Fields {
+	var shared Fields
+	for _, f := range fs {
+		if isNillable(f.Type) {
+			shared = append(shared, f)
+		}
+	}
+	return shared
+}
+
+// Clones reports whether cloning the shared fields uses the package pkg,
+// slices or maps
+func (fs Fields) Clones(pkg string) bool {
+	for _, f := range fs.Shared() {
+		if strings.Contains(f.Clone("e"), pkg+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// Clone returns the statements replacing the field of recv with a copy that
+// shares no memory with it, e.g. e.Tags = slices.Clone(e.Tags). Pointers,
+// slices and maps are copied at every level ([]*T, map[string][]string);
+// values of other types, structs included, are copied as they are, so the
+// slices and maps inside them are still shared.
+func (f Field) Clone(recv string) string {
+	return cloneStmts(recv+"."+f.Name, f.Type, 0)
+}
+
+// cloneStmts returns the statements replacing v, a value of type typ, with
+// a deep copy, or "" when copying v is enough. depth numbers the variables
+// of nested copies.
+func cloneStmts(v, typ string, depth int) string {
+	n := ""
+	if depth > 0 {
+		n = strconv.Itoa(depth + 1)
+	}
+	switch {
+	case strings.HasPrefix(typ, "*"):
+		c := "v" + n
+		stmts := fmt.Sprintf("if %s != nil {\n%s := *%s\n", v, c, v)
+		if inner := cloneStmts(c, typ[1:], depth+1); inner != "" {
+			stmts += inner + "\n"
+		}
+		return stmts + fmt.Sprintf("%s = &%s\n}", v, c)
+	case strings.HasPrefix(typ, "[]"):
+		stmts := fmt.Sprintf("%s = slices.Clone(%s)", v, v)
+		i := "i" + n
+		if inner := cloneStmts(v+"["+i+"]", typ[2:], depth+1); inner != "" {
+			stmts += fmt.Sprintf("\nfor %s := range %s {\n%s\n}", i, v, inner)
+		}
+		return stmts
+	case strings.HasPrefix(typ, "map["):
+		stmts := fmt.Sprintf("%s = maps.Clone(%s)", v, v)
+		k, x := "k"+n, "x"+n
+		// Map values are not addressable, they are copied out and back
+		if inner := cloneStmts(x, mapElem(typ), depth+1); inner != "" {
+			stmts += fmt.Sprintf("\nfor %s, %s := range %s {\n%s\n%s[%s] = %s\n}", k, x, v, inner, v, k, x)
+		}
+		return stmts
+	}
+	return ""
+}
+
+// mapElem returns the element type of the map type typ
+func mapElem(typ string) string {
+	depth := 0
+	for i, r := range typ {
+		switch r {
+		case '[':
+			depth++
+		case ']':
+			if depth--; depth == 0 {
+				return typ[i+1:]
+			}
+		}
+	}
+	return ""
+}
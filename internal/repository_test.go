@@ -0,0 +1,114 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestMergeDomainErrors(t *testing.T) {
+	src := []byte(`package domain
+
+import "errors"
+
+// ErrNotFound is returned by repositories when no record matches the query
+var ErrNotFound = errors.New("not found")
+
+// ErrForbidden is written by hand
+var ErrForbidden = errors.New("forbidden")
+`)
+
+	merged, err := MergeDomainErrors(src)
+	if err != nil {
+		t.Fatalf("MergeDomainErrors() failed: %v", err)
+	}
+	if n := strings.Count(string(merged), "var ErrNotFound"); n != 1 {
+		t.Errorf("ErrNotFound declared %d times, want 1:\n%s", n, merged)
+	}
+	for _, want := range []string{
+		`var ErrForbidden = errors.New("forbidden")`,
+		`var ErrAlreadyExists = errors.New("already exists")`,
+	} {
+		if !strings.Contains(string(merged), want) {
+			t.Errorf("Errors file is missing %q:\n%s", want, merged)
+		}
+	}
+
+	again, err := MergeDomainErrors(merged)
+	if err != nil {
+		t.Fatalf("MergeDomainErrors() failed: %v", err)
+	}
+	if string(again) != string(merged) {
+		t.Errorf("Merging twice changed the file:\n%s", again)
+	}
+}
+
+func TestRepositoryMethods(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "user_repository.go")
+	os.WriteFile(path, []byte(`package repository
+
+type UserRepository interface {
+	Save(e *User) error
+	Create(e *User) error
+	FindByID(id string) (*User, error)
+}
+
+type User struct{}
+`), 0644)
+
+	methods, err := RepositoryMethods(path, "UserRepository")
+	if err != nil {
+		t.Fatalf("RepositoryMethods() failed: %v", err)
+	}
+	if want := []string{"Save", "Create", "FindByID"}; !slices.Equal(methods, want) {
+		t.Errorf("RepositoryMethods() = %v, want %v", methods, want)
+	}
+
+	if methods, _ := RepositoryMethods(path, "OrderRepository"); methods != nil {
+		t.Errorf("RepositoryMethods() of an undeclared interface = %v, want nil", methods)
+	}
+	if _, err := RepositoryMethods(filepath.Join(t.TempDir(), "missing.go"), "UserRepository"); !os.IsNotExist(err) {
+		t.Errorf("RepositoryMethods() of a missing file = %v, want a not exist error", err)
+	}
+}
+
+func TestFieldClone(t *testing.T) {
+	fields := Fields{
+		{Name: "Name", Type: "string"},
+		{Name: "Tags", Type: "[]string"},
+		{Name: "Meta", Type: "map[string]int"},
+		{Name: "Nick", Type: "*string", Optional: true},
+		{Name: "Labels", Type: "*[]string", Optional: true},
+		{Name: "Refs", Type: "[]*int64"},
+		{Name: "Index", Type: "map[string][]string"},
+		{Name: "Grid", Type: "[][]*string"},
+	}
+
+	tests := map[string]string{
+		"Tags":   "e.Tags = slices.Clone(e.Tags)",
+		"Meta":   "e.Meta = maps.Clone(e.Meta)",
+		"Nick":   "if e.Nick != nil {\nv := *e.Nick\ne.Nick = &v\n}",
+		"Labels": "if e.Labels != nil {\nv := *e.Labels\nv = slices.Clone(v)\ne.Labels = &v\n}",
+		"Refs":   "e.Refs = slices.Clone(e.Refs)\nfor i := range e.Refs {\nif e.Refs[i] != nil {\nv2 := *e.Refs[i]\ne.Refs[i] = &v2\n}\n}",
+		"Index":  "e.Index = maps.Clone(e.Index)\nfor k, x := range e.Index {\nx = slices.Clone(x)\ne.Index[k] = x\n}",
+		"Grid":   "e.Grid = slices.Clone(e.Grid)\nfor i := range e.Grid {\ne.Grid[i] = slices.Clone(e.Grid[i])\nfor i2 := range e.Grid[i] {\nif e.Grid[i][i2] != nil {\nv3 := *e.Grid[i][i2]\ne.Grid[i][i2] = &v3\n}\n}\n}",
+	}
+	shared := fields.Shared()
+	if len(shared) != len(tests) {
+		t.Fatalf("Shared() = %v, want the %d pointer, slice and map fields", shared, len(tests))
+	}
+	for _, f := range shared {
+		if got := f.Clone("e"); got != tests[f.Name] {
+			t.Errorf("Clone() of %s = %q, want %q", f.Name, got, tests[f.Name])
+		}
+	}
+
+	if !fields.Clones("slices") || !fields.Clones("maps") {
+		t.Error("Clones() = false for the packages cloning slices and maps")
+	}
+	if fields[:1].Clones("slices") || fields[3:4].Clones("maps") {
+		t.Error("Clones() = true without slices or maps")
+	}
+}
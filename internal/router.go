@@ -0,0 +1,165 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Routers HTTP handlers can be generated for
+const (
+	RouterStdlib = "stdlib"
+	RouterChi    = "chi"
+	RouterGin    = "gin"
+	RouterEcho   = "echo"
+)
+
+// Router describes how HTTP handlers are served by a router package and how
+// the DI container creates it
+type Router struct {
+	Name      string // value of --router and http.router, e.g. chi
+	Module    string // Go module of the router, empty for net/http
+	Import    string // package of the router
+	New       string // expression creating the router in NewContainer
+	Type      string // type of the Router field of the DI container
+	Param     string // type handlers register their route on
+	Var       string // name of the Param of Register
+	Handler   string // signature of the method of handlers serving a request
+	Writer    string // http.ResponseWriter in Handler, when it is not w
+	Request   string // *http.Request in Handler, when it is not r
+	PathValue string // format of the expression reading a path parameter
+	Returns   bool   // Handler returns an error
+	register  string // format of the call registering Handler, from the method and path
+	colons    bool   // path parameters are written :id instead of {id}
+}
+
+var routers = []Router{
+	{
+		Name:      RouterStdlib,
+		Var:       "mux",
+		Import:    "net/http",
+		New:       "http.NewServeMux()",
+		Type:      "*http.ServeMux",
+		Param:     "*http.ServeMux",
+		Handler:   "ServeHTTP(w http.ResponseWriter, r *http.Request)",
+		PathValue: "r.PathValue(%q)",
+		register:  `Handle("%s %s", h)`,
+	},
+	{
+		Name:      RouterChi,
+		Var:       "router",
+		Module:    "github.com/go-chi/chi/v5",
+		Import:    "github.com/go-chi/chi/v5",
+		New:       "chi.NewRouter()",
+		Type:      "*chi.Mux",
+		Param:     "chi.Router",
+		Handler:   "ServeHTTP(w http.ResponseWriter, r *http.Request)",
+		PathValue: "chi.URLParam(r, %q)",
+		register:  `Method("%s", "%s", h)`,
+	},
+	{
+		Name:      RouterGin,
+		Var:       "router",
+		Module:    "github.com/gin-gonic/gin",
+		Import:    "github.com/gin-gonic/gin",
+		New:       "gin.New()",
+		Type:      "*gin.Engine",
+		Param:     "gin.IRouter",
+		Handler:   "Handle(c *gin.Context)",
+		Writer:    "c.Writer",
+		Request:   "c.Request",
+		PathValue: "c.Param(%q)",
+		register:  `Handle("%s", "%s", h.Handle)`,
+		colons:    true,
+	},
+	{
+		Name:      RouterEcho,
+		Var:       "e",
+		Module:    "github.com/labstack/echo/v4",
+		Import:    "github.com/labstack/echo/v4",
+		New:       "echo.New()",
+		Type:      "*echo.Echo",
+		Param:     "*echo.Echo",
+		Handler:   "Handle(c echo.Context) error",
+		Writer:    "c.Response()",
+		Request:   "c.Request()",
+		PathValue: "c.Param(%q)",
+		Returns:   true,
+		register:  `Add("%s", "%s", h.Handle)`,
+		colons:    true,
+	},
+}
+
+// LookupRouter returns the router called name, the standard library
+// ServeMux when empty
+func LookupRouter(name string) (Router, error) {
+	if name == "" {
+		name = RouterStdlib
+	}
+	for _, r := range routers {
+		if r.Name == name {
+			return r, nil
+		}
+	}
+	return Router{}, fmt.Errorf("unknown router %q, expected one of %s", name, strings.Join(RouterNames(), ", "))
+}
+
+// RouterNames lists the supported routers
+func RouterNames() []string {
+	names := make([]string, len(routers))
+	for i, r := range routers {
+		names[i] = r.Name
+	}
+	return names
+}
+
+// Framework reports whether handlers get the request from a context of the
+// router instead of as an http.Handler
+func (r Router) Framework() bool {
+	return r.Writer != ""
+}
+
+// Method returns the name of the method of handlers serving a request
+func (r Router) Method() string {
+	name, _, _ := strings.Cut(r.Handler, "(")
+	return name
+}
+
+// Return returns the statement ending Handler early
+func (r Router) Return() string {
+	if r.Returns {
+		return "return nil"
+	}
+	return "return"
+}
+
+// Path returns the path of route written for the router
+func (r Router) Path(route Route) string {
+	if !r.colons {
+		return route.Path
+	}
+	return wildcard.ReplaceAllStringFunc(route.Path, func(m string) string {
+		name := strings.Trim(m, "{}")
+		if rest, ok := strings.CutSuffix(name, "..."); ok {
+			if r.Name == RouterEcho {
+				// Echo has a single, unnamed wildcard
+				return "*"
+			}
+			return "*" + rest
+		}
+		return ":" + name
+	})
+}
+
+// Register returns the method call registering the handler of route
+func (r Router) Register(route Route) string {
+	return fmt.Sprintf(r.register, route.Method, r.Path(route))
+}
+
+// Value returns the expression reading the path parameter name
+func (r Router) Value(name string) string {
+	return fmt.Sprintf(r.PathValue, name)
+}
+
+// wildcard matches the wildcards of a ServeMux pattern, {id} or {path...}
+var wildcard = regexp.MustCompile(`\{\w+(\.\.\.)?\}`)
@@ -0,0 +1,36 @@
+package internal
+
+import "testing"
+
+func TestRouter_Register(t *testing.T) {
+	route := Route{Method: "GET", Path: "/files/{id}/{path...}"}
+	tests := []struct {
+		router   string
+		register string
+		value    string
+	}{
+		{"", `Handle("GET /files/{id}/{path...}", h)`, `r.PathValue("id")`},
+		{RouterChi, `Method("GET", "/files/{id}/{path...}", h)`, `chi.URLParam(r, "id")`},
+		{RouterGin, `Handle("GET", "/files/:id/*path", h.Handle)`, `c.Param("id")`},
+		{RouterEcho, `Add("GET", "/files/:id/*", h.Handle)`, `c.Param("id")`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.router, func(t *testing.T) {
+			router, err := LookupRouter(tt.router)
+			if err != nil {
+				t.Fatalf("LookupRouter() failed: %v", err)
+			}
+			if got := router.Register(route); got != tt.register {
+				t.Errorf("Register() = %q, expected %q", got, tt.register)
+			}
+			if got := router.Value("id"); got != tt.value {
+				t.Errorf("Value() = %q, expected %q", got, tt.value)
+			}
+		})
+	}
+
+	if _, err := LookupRouter("gorilla"); err == nil {
+		t.Error("An unknown router should fail")
+	}
+}
@@ -0,0 +1,207 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Schema describes the domain of a project: its entities, their fields and
+// relations, and the use cases of every entity. It is read from YAML or JSON
+// (JSON documents are valid YAML).
+type Schema struct {
+	Entities []EntitySchema `yaml:"entities"`
+}
+
+// EntitySchema is a single entity of a Schema
+type EntitySchema struct {
+	Name      string      `yaml:"name"`
+	Fields    []FieldSpec `yaml:"fields"`
+	Relations []Relation  `yaml:"relations"`
+	UseCases  []string    `yaml:"usecases"`
+	CRUD      bool        `yaml:"crud"` // full CRUD repository, see make repo --crud
+}
+
+// FieldSpec is a field definition, either written as `name:type` (the same
+// syntax `make entity` accepts) or as a mapping:
+//
+//   - name: email
+//     type: string
+//     optional: true
+//     tags: {json: "email_address,omitempty"}
+type FieldSpec struct {
+	Definition string
+}
+
+// Relation links two entities through a foreign key field. belongs_to adds
+// <Target>ID to the entity itself, has_one and has_many add <Entity>ID to
+// the target entity.
+type Relation struct {
+	BelongsTo string `yaml:"belongs_to"`
+	HasOne    string `yaml:"has_one"`
+	HasMany   string `yaml:"has_many"`
+}
+
+// LoadSchema reads and validates the schema file at path
+func LoadSchema(path string) (*Schema, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var schema Schema
+	if err := yaml.Unmarshal(content, &schema); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if err := schema.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return &schema, nil
+}
+
+// Validate checks names, relations and use cases
+func (s *Schema) Validate() error {
+	if len(s.Entities) == 0 {
+		return fmt.Errorf("no entities defined")
+	}
+
+	var names, useCases []string
+	for _, e := range s.Entities {
+		if e.Name == "" {
+			return fmt.Errorf("entity without a name")
+		}
+		name := ToPascalCase(e.Name)
+		if slices.Contains(names, name) {
+			return fmt.Errorf("entity %q is defined more than once", name)
+		}
+		names = append(names, name)
+
+		for _, uc := range e.UseCases {
+			uc = ToPascalCase(uc)
+			if slices.Contains(useCases, uc) {
+				return fmt.Errorf("use case %q is defined more than once", uc)
+			}
+			useCases = append(useCases, uc)
+		}
+	}
+
+	for _, e := range s.Entities {
+		for _, r := range e.Relations {
+			kind, target, err := r.target()
+			if err != nil {
+				return fmt.Errorf("entity %q: %w", e.Name, err)
+			}
+			if !slices.Contains(names, ToPascalCase(target)) {
+				return fmt.Errorf("entity %q: %s relation to unknown entity %q", e.Name, kind, target)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Fields resolves the fields of every entity, keyed by PascalCase name,
+// including the foreign keys added by relations. Entities without fields get
+// DefaultFields, like make entity.
+func (s *Schema) Fields() (map[string]Fields, error) {
+	resolved := map[string]Fields{}
+	for _, e := range s.Entities {
+		defs := make([]string, len(e.Fields))
+		for i, f := range e.Fields {
+			defs[i] = f.Definition
+		}
+
+		fields, err := ParseFields(defs)
+		if err != nil {
+			return nil, fmt.Errorf("entity %q: %w", e.Name, err)
+		}
+		if len(defs) == 0 {
+			fields = DefaultFields()
+		}
+		resolved[ToPascalCase(e.Name)] = fields
+	}
+
+	for _, e := range s.Entities {
+		name := ToPascalCase(e.Name)
+		for _, r := range e.Relations {
+			kind, target, _ := r.target()
+			target = ToPascalCase(target)
+
+			owner, referenced := name, target
+			if kind != "belongs_to" {
+				owner, referenced = target, name
+			}
+
+			key := referenced + "ID"
+			if resolved[owner].Get(key) != nil {
+				continue
+			}
+			id := resolved[referenced].Get("ID")
+			resolved[owner] = append(resolved[owner], Field{
+				Name:    key,
+				Column:  ToSnake(referenced) + "_id",
+				Type:    id.Type,
+				Imports: id.Imports,
+			})
+		}
+	}
+
+	return resolved, nil
+}
+
+// target returns the kind of the relation and the entity it points to
+func (r Relation) target() (string, string, error) {
+	var kinds []string
+	var target string
+	for kind, value := range map[string]string{"belongs_to": r.BelongsTo, "has_one": r.HasOne, "has_many": r.HasMany} {
+		if value != "" {
+			kinds = append(kinds, kind)
+			target = value
+		}
+	}
+	if len(kinds) != 1 {
+		return "", "", fmt.Errorf("a relation needs exactly one of belongs_to, has_one or has_many")
+	}
+	return kinds[0], target, nil
+}
+
+// UnmarshalYAML accepts both `name:type` strings and field mappings
+func (f *FieldSpec) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		f.Definition = node.Value
+		return nil
+	}
+
+	var spec struct {
+		Name     string    `yaml:"name"`
+		Type     string    `yaml:"type"`
+		Optional bool      `yaml:"optional"`
+		Tags     yaml.Node `yaml:"tags"`
+	}
+	if err := node.Decode(&spec); err != nil {
+		return err
+	}
+	if spec.Name == "" || spec.Type == "" {
+		return fmt.Errorf("line %d: a field needs a name and a type", node.Line)
+	}
+
+	def := spec.Name
+	if spec.Optional {
+		def += "?"
+	}
+	def += ":" + spec.Type
+
+	// Walk the node so tags keep the order they were written in
+	if spec.Tags.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(spec.Tags.Content); i += 2 {
+			def += ":" + spec.Tags.Content[i].Value + "=" + spec.Tags.Content[i+1].Value
+		}
+	}
+
+	f.Definition = def
+	return nil
+}
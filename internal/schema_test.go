@@ -0,0 +1,154 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadSchema(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		wantErr string
+	}{
+		{
+			name: "YAML",
+			file: "schema.yaml",
+			content: `entities:
+  - name: User
+    fields: [name:string, email:string]
+    usecases: [CreateUser]
+  - name: Order
+    fields:
+      - total:float64
+      - name: note
+        type: string
+        optional: true
+        tags: {json: "comment,omitempty", db: comment}
+    relations:
+      - belongs_to: User
+    usecases: [CreateOrder]
+`,
+		},
+		{
+			name:    "JSON",
+			file:    "schema.json",
+			content: `{"entities": [{"name": "User", "fields": ["name:string"], "usecases": ["CreateUser"]}]}`,
+		},
+		{
+			name:    "No entities",
+			file:    "empty.yaml",
+			content: "entities: []\n",
+			wantErr: "no entities",
+		},
+		{
+			name:    "Duplicated entity",
+			file:    "dup.yaml",
+			content: "entities:\n  - name: User\n  - name: user\n",
+			wantErr: "more than once",
+		},
+		{
+			name:    "Duplicated use case",
+			file:    "dup_uc.yaml",
+			content: "entities:\n  - name: User\n    usecases: [CreateUser]\n  - name: Admin\n    usecases: [CreateUser]\n",
+			wantErr: "use case",
+		},
+		{
+			name:    "Unknown relation target",
+			file:    "rel.yaml",
+			content: "entities:\n  - name: Order\n    relations:\n      - belongs_to: User\n",
+			wantErr: "unknown entity",
+		},
+		{
+			name:    "Ambiguous relation",
+			file:    "ambiguous.yaml",
+			content: "entities:\n  - name: User\n    relations:\n      - {has_one: User, has_many: User}\n",
+			wantErr: "exactly one",
+		},
+		{
+			name:    "Field without type",
+			file:    "field.yaml",
+			content: "entities:\n  - name: User\n    fields:\n      - name: email\n",
+			wantErr: "name and a type",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(tmpDir, tt.file)
+			os.WriteFile(path, []byte(tt.content), 0644)
+
+			schema, err := LoadSchema(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadSchema() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadSchema() error = %v", err)
+			}
+			if schema.Entities[0].Name != "User" || schema.Entities[0].UseCases[0] != "CreateUser" {
+				t.Errorf("Unexpected schema: %+v", schema)
+			}
+		})
+	}
+}
+
+func TestSchemaFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schema.yaml")
+	os.WriteFile(path, []byte(`entities:
+  - name: user
+    fields: [id:uuid.UUID, name:string]
+    relations:
+      - has_many: Order
+      - has_one: Profile
+  - name: Order
+    fields:
+      - name: note
+        type: string
+        optional: true
+        tags: {json: "comment,omitempty", db: comment}
+  - name: Profile
+  - name: Invoice
+    relations:
+      - belongs_to: Order
+`), 0644)
+
+	schema, err := LoadSchema(path)
+	if err != nil {
+		t.Fatalf("LoadSchema() error = %v", err)
+	}
+
+	resolved, err := schema.Fields()
+	if err != nil {
+		t.Fatalf("Fields() error = %v", err)
+	}
+
+	note := resolved["Order"].Get("Note")
+	if note == nil || note.Type != "*string" || note.Column != "comment" {
+		t.Fatalf("Unexpected note field: %+v", note)
+	}
+	if value, _ := note.TagValue("json"); value != "comment,omitempty" {
+		t.Errorf("json tag = %q, want comment,omitempty", value)
+	}
+
+	fk := resolved["Order"].Get("UserID")
+	if fk == nil || fk.Type != "uuid.UUID" || fk.Column != "user_id" || len(fk.Imports) == 0 {
+		t.Errorf("Unexpected has_many foreign key: %+v", fk)
+	}
+	if resolved["Profile"].Get("UserID") == nil {
+		t.Error("has_one did not add UserID to Profile")
+	}
+	if resolved["Profile"].Get("Name") == nil {
+		t.Error("Entity without fields did not get the default fields")
+	}
+	if fk := resolved["Invoice"].Get("OrderID"); fk == nil || fk.Type != "string" {
+		t.Errorf("Unexpected belongs_to foreign key: %+v", fk)
+	}
+}
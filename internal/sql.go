@@ -0,0 +1,328 @@
+package internal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Dialect is the SQL flavour spoken by a database
+type Dialect string
+
+// Supported SQL dialects
+const (
+	DialectMySQL    Dialect = "mysql"
+	DialectPostgres Dialect = "postgres"
+	DialectSQLite   Dialect = "sqlite"
+)
+
+// Placeholder returns the n-th (1-based) query parameter
+func (d Dialect) Placeholder(n int) string {
+	if d == DialectPostgres {
+		return fmt.Sprintf("$%d", n)
+	}
+	return "?"
+}
+
+// Returning reports whether INSERT ... RETURNING is supported
+func (d Dialect) Returning() bool {
+	return d == DialectPostgres || d == DialectSQLite
+}
+
+// FoundRows reports whether UPDATE counts the rows it matched. MySQL only
+// counts the rows whose values changed.
+func (d Dialect) FoundRows() bool {
+	return d != DialectMySQL
+}
+
+// Quote quotes an identifier, so that names such as order are not read as
+// keywords: `order` in MySQL and "order" in PostgreSQL and SQLite. Each part
+// of a qualified name is quoted on its own.
+func (d Dialect) Quote(name string) string {
+	quote := `"`
+	if d == DialectMySQL {
+		quote = "`"
+	}
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = quote + strings.ReplaceAll(part, quote, quote+quote) + quote
+	}
+	return strings.Join(parts, ".")
+}
+
+// Literal returns s as a Go string literal, a raw one unless s has
+// backquotes such as the identifiers of MySQL
+func (d Dialect) Literal(s string) string {
+	if strings.Contains(s, "`") {
+		return strconv.Quote(s)
+	}
+	return "`" + s + "`"
+}
+
+// Bind returns a Go expression of clause followed by the placeholder of the
+// last value appended to the args slice of the generated code, e.g.
+// "`name` = ?" or fmt.Sprintf(`"name" = $%d`, len(args))
+func (d Dialect) Bind(clause string) string {
+	if d == DialectPostgres {
+		return fmt.Sprintf("fmt.Sprintf(%s, len(args))", d.Literal(clause+"$%d"))
+	}
+	return d.Literal(clause + "?")
+}
+
+// excluded sets column to the value a conflicting insert tried to write
+func (d Dialect) excluded(column string) string {
+	column = d.Quote(column)
+	if d == DialectMySQL {
+		return fmt.Sprintf("%s = VALUES(%s)", column, column)
+	}
+	return fmt.Sprintf("%s = EXCLUDED.%s", column, column)
+}
+
+// SQLTable writes the queries of a repository for the table an entity is
+// stored in. Every field is a column, and ID is the primary key.
+type SQLTable struct {
+	Name    string
+	Dialect Dialect
+	Fields  Fields
+}
+
+// NewSQLTable returns the table entity is stored in. It returns nil when the
+// fields have no ID to use as primary key.
+func NewSQLTable(dialect Dialect, entity string, fields Fields) *SQLTable {
+	if fields.Get("ID") == nil {
+		return nil
+	}
+	return &SQLTable{Name: TableName(entity), Dialect: dialect, Fields: fields}
+}
+
+// Key returns the primary key field
+func (t *SQLTable) Key() *Field {
+	return t.Fields.Get("ID")
+}
+
+// Quote quotes the name of a column, see Dialect.Quote
+func (t *SQLTable) Quote(column string) string {
+	return t.Dialect.Quote(column)
+}
+
+// ColumnLiteral returns the Go string literal of the quoted column
+func (t *SQLTable) ColumnLiteral(column string) string {
+	return t.Dialect.Literal(t.Quote(column))
+}
+
+// table returns the quoted name of the table
+func (t *SQLTable) table() string {
+	return t.Dialect.Quote(t.Name)
+}
+
+// key returns the quoted primary key column
+func (t *SQLTable) key() string {
+	return t.Quote(t.Key().Column)
+}
+
+// Columns returns the comma separated column list
+func (t *SQLTable) Columns() string {
+	return t.columns(t.Fields)
+}
+
+// columns returns the comma separated columns of fields
+func (t *SQLTable) columns(fields Fields) string {
+	columns := make([]string, len(fields))
+	for i, f := range fields {
+		columns[i] = t.Quote(f.Column)
+	}
+	return strings.Join(columns, ", ")
+}
+
+// Upsert inserts a row, or updates every other column when the key already
+// exists. Dialects supporting it return the key.
+func (t *SQLTable) Upsert() string {
+	var updates []string
+	for _, f := range t.values() {
+		updates = append(updates, t.Dialect.excluded(f.Column))
+	}
+	key := t.key()
+	if len(updates) == 0 {
+		// Rewrite the key so the row is returned on conflicts too
+		updates = append(updates, t.Dialect.excluded(t.Key().Column))
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", t.table(), t.Columns(), t.placeholders(1, len(t.Fields)))
+	if t.Dialect == DialectMySQL {
+		query += " ON DUPLICATE KEY UPDATE " + strings.Join(updates, ", ")
+	} else {
+		query += fmt.Sprintf(" ON CONFLICT (%s) DO UPDATE SET %s", key, strings.Join(updates, ", "))
+	}
+	if t.Dialect.Returning() {
+		query += " RETURNING " + key
+	}
+
+	return query
+}
+
+// Insert inserts a row unless the key already exists. Dialects supporting it
+// return the key, which yields no row on conflicts; MySQL affects no row.
+func (t *SQLTable) Insert() string {
+	key := t.key()
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", t.table(), t.Columns(), t.placeholders(1, len(t.Fields)))
+	if t.Dialect == DialectMySQL {
+		return query + fmt.Sprintf(" ON DUPLICATE KEY UPDATE %s = %s", key, key)
+	}
+	query += fmt.Sprintf(" ON CONFLICT (%s) DO NOTHING", key)
+	if t.Dialect.Returning() {
+		query += " RETURNING " + key
+	}
+	return query
+}
+
+// Generated reports whether the database generates integer keys that
+// InsertGenerated returns. MySQL assigns them to rows inserted with a zero
+// key, reported by LastInsertId.
+func (t *SQLTable) Generated() bool {
+	return t.Key().Integer() && t.Dialect.Returning()
+}
+
+// InsertGenerated inserts a row without the key, which the database
+// generates, and returns it. Its arguments are ValueArgs.
+func (t *SQLTable) InsertGenerated() string {
+	values := t.values()
+	if len(values) == 0 {
+		return fmt.Sprintf("INSERT INTO %s DEFAULT VALUES RETURNING %s", t.table(), t.key())
+	}
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) RETURNING %s", t.table(), t.columns(values), t.placeholders(1, len(values)), t.key())
+}
+
+// Update sets every other column of the row with the given key, see
+// UpdateArgs
+func (t *SQLTable) Update() string {
+	var sets []string
+	for _, f := range t.values() {
+		sets = append(sets, fmt.Sprintf("%s = %s", t.Quote(f.Column), t.Dialect.Placeholder(len(sets)+1)))
+	}
+	key := t.key()
+	if len(sets) == 0 {
+		sets = append(sets, key+" = "+key)
+	}
+	return fmt.Sprintf("UPDATE %s SET %s WHERE %s = %s", t.table(), strings.Join(sets, ", "), key, t.Dialect.Placeholder(len(t.values())+1))
+}
+
+// UpdateArgs returns the arguments of Update: the field values of recv
+// without the key, then the key
+func (t *SQLTable) UpdateArgs(recv string) string {
+	args := t.ValueArgs(recv)
+	if args != "" {
+		args += ", "
+	}
+	return args + recv + "." + t.Key().Name
+}
+
+// ValueArgs returns the field values of recv without the key
+func (t *SQLTable) ValueArgs(recv string) string {
+	var args []string
+	for _, f := range t.values() {
+		args = append(args, arg(recv, f))
+	}
+	return strings.Join(args, ", ")
+}
+
+// Delete deletes the row with the given key
+func (t *SQLTable) Delete() string {
+	return fmt.Sprintf("DELETE FROM %s WHERE %s = %s", t.table(), t.key(), t.Dialect.Placeholder(1))
+}
+
+// Exists selects 1 when a row has the given key
+func (t *SQLTable) Exists() string {
+	return fmt.Sprintf("SELECT 1 FROM %s WHERE %s = %s", t.table(), t.key(), t.Dialect.Placeholder(1))
+}
+
+// Select selects every row, for conditions to be appended
+func (t *SQLTable) Select() string {
+	return fmt.Sprintf("SELECT %s FROM %s", t.Columns(), t.table())
+}
+
+// Count counts the rows
+func (t *SQLTable) Count() string {
+	return "SELECT COUNT(*) FROM " + t.table()
+}
+
+// SelectByID selects the row with the given key
+func (t *SQLTable) SelectByID() string {
+	return fmt.Sprintf("SELECT %s FROM %s WHERE %s = %s", t.Columns(), t.table(), t.key(), t.Dialect.Placeholder(1))
+}
+
+// values returns the fields besides the key
+func (t *SQLTable) values() Fields {
+	var fields Fields
+	for _, f := range t.Fields {
+		if f.Name != "ID" {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// placeholders returns n comma separated placeholders starting at from
+func (t *SQLTable) placeholders(from, n int) string {
+	placeholders := make([]string, n)
+	for i := range placeholders {
+		placeholders[i] = t.Dialect.Placeholder(from + i)
+	}
+	return strings.Join(placeholders, ", ")
+}
+
+// Args returns the field values of recv in column order, e.g. e.ID, e.Name.
+// Fields stored as JSON are wrapped in the jsonColumn of the repositories.
+func (t *SQLTable) Args(recv string) string {
+	args := make([]string, len(t.Fields))
+	for i, f := range t.Fields {
+		args[i] = arg(recv, f)
+	}
+	return strings.Join(args, ", ")
+}
+
+// Dests returns pointers to the fields of recv in column order, to scan a
+// row. Fields stored as JSON are decoded by the jsonColumn of the
+// repositories.
+func (t *SQLTable) Dests(recv string) string {
+	dests := make([]string, len(t.Fields))
+	for i, f := range t.Fields {
+		dests[i] = "&" + recv + "." + f.Name
+		if f.StoredAsJSON() {
+			dests[i] = "jsonColumn{" + dests[i] + "}"
+		}
+	}
+	return strings.Join(dests, ", ")
+}
+
+// JSON reports whether the table has columns stored as JSON
+func (t *SQLTable) JSON() bool {
+	for _, f := range t.Fields {
+		if f.StoredAsJSON() {
+			return true
+		}
+	}
+	return false
+}
+
+// arg returns the query argument of the field of recv
+func arg(recv string, f Field) string {
+	if f.StoredAsJSON() {
+		return "jsonColumn{" + recv + "." + f.Name + "}"
+	}
+	return recv + "." + f.Name
+}
+
+// TableName returns the table an entity is stored in: the plural of its
+// snake_case name (OrderItem -> order_items, Category -> categories)
+func TableName(entity string) string {
+	name := ToSnake(ToPascalCase(entity))
+	switch {
+	case strings.HasSuffix(name, "y") && len(name) > 1 && !strings.ContainsRune("aeiou", rune(name[len(name)-2])):
+		return name[:len(name)-1] + "ies"
+	case strings.HasSuffix(name, "s"), strings.HasSuffix(name, "x"), strings.HasSuffix(name, "z"),
+		strings.HasSuffix(name, "ch"), strings.HasSuffix(name, "sh"):
+		return name + "es"
+	default:
+		return name + "s"
+	}
+}
@@ -0,0 +1,237 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ImportedTable is a table read from a CREATE TABLE statement
+type ImportedTable struct {
+	Name    string
+	Columns []TableColumn
+	Key     string // single column primary key, "" when there is none
+}
+
+var (
+	createTableStartRe = regexp.MustCompile(`(?i)\bCREATE\s+(?:(?:GLOBAL\s+|LOCAL\s+)?(?:TEMP|TEMPORARY|UNLOGGED)\s+)?TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?((?:[\w"` + "`" + `\[\]]+\.)?[\w"` + "`" + `\[\]]+)\s*\(`)
+	blockCommentRe     = regexp.MustCompile(`(?s)/\*.*?\*/`)
+	lineCommentRe      = regexp.MustCompile(`(?m)--.*$`)
+	keyColumnsRe       = regexp.MustCompile(`(?i)^(?:CONSTRAINT\s+\S+\s+)?PRIMARY\s+KEY\s*\((.*)\)`)
+	tableConstraintRe  = regexp.MustCompile(`(?i)^(CONSTRAINT|PRIMARY\s+KEY|UNIQUE|KEY|INDEX|FOREIGN\s+KEY|CHECK|FULLTEXT|SPATIAL|EXCLUDE)\b`)
+)
+
+// ParseDDL reads the tables created by the CREATE TABLE statements of a SQL
+// script, such as a schema dump. Other statements are ignored.
+func ParseDDL(ddl string) ([]ImportedTable, error) {
+	ddl = lineCommentRe.ReplaceAllString(blockCommentRe.ReplaceAllString(ddl, ""), "")
+
+	var tables []ImportedTable
+	for _, loc := range createTableStartRe.FindAllStringSubmatchIndex(ddl, -1) {
+		name := ddl[loc[2]:loc[3]]
+		if i := strings.LastIndex(name, "."); i >= 0 {
+			// Drop the schema, public.users is users
+			name = name[i+1:]
+		}
+		name = unquoteIdentifier(name)
+
+		body, ok := enclosed(ddl[loc[1]:])
+		if !ok {
+			return nil, fmt.Errorf("CREATE TABLE %s: unbalanced parentheses", name)
+		}
+
+		table := ImportedTable{Name: name}
+		var keys []string
+		for _, def := range splitTopLevel(body) {
+			if m := keyColumnsRe.FindStringSubmatch(def); m != nil {
+				for _, key := range strings.Split(m[1], ",") {
+					keys = append(keys, unquoteIdentifier(strings.Fields(key)[0]))
+				}
+				continue
+			}
+			if tableConstraintRe.MatchString(def) {
+				continue
+			}
+
+			col := ParseColumn(def)
+			if strings.Contains(strings.ToUpper(col.Definition), "PRIMARY KEY") {
+				keys = append(keys, col.Name)
+			}
+			table.Columns = append(table.Columns, col)
+		}
+		if len(keys) == 1 {
+			table.Key = keys[0]
+			// Keys are never NULL, whether the column says so or not
+			for i := range table.Columns {
+				if table.Columns[i].Name == table.Key {
+					table.Columns[i].NotNull = true
+				}
+			}
+		}
+		tables = append(tables, table)
+	}
+	return tables, nil
+}
+
+// enclosed returns what comes before the parenthesis closing the one s
+// starts after
+func enclosed(s string) (string, bool) {
+	depth := 1
+	var quote rune
+	for i, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+			if depth == 0 {
+				return s[:i], true
+			}
+		}
+	}
+	return "", false
+}
+
+// splitTopLevel splits the body of a CREATE TABLE at the commas outside
+// parentheses and quotes
+func splitTopLevel(body string) []string {
+	var parts []string
+	depth, start := 0, 0
+	var quote rune
+	for i, r := range body {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case r == ',' && depth == 0:
+			parts = append(parts, strings.TrimSpace(body[start:i]))
+			start = i + 1
+		}
+	}
+	parts = append(parts, strings.TrimSpace(body[start:]))
+	return slices.DeleteFunc(parts, func(p string) bool { return p == "" })
+}
+
+// unquoteIdentifier removes the quotes of "name", `name` or [name]
+func unquoteIdentifier(name string) string {
+	return strings.Trim(name, "\"`[]")
+}
+
+// EntityName returns the entity stored in table, the singular of its name
+// in PascalCase (order_items is OrderItem). See TableName.
+func EntityName(table string) string {
+	name := strings.ToLower(table)
+	switch {
+	case strings.HasSuffix(name, "ies") && len(name) > 3:
+		name = name[:len(name)-3] + "y"
+	case strings.HasSuffix(name, "sses"), strings.HasSuffix(name, "xes"), strings.HasSuffix(name, "zes"),
+		strings.HasSuffix(name, "ches"), strings.HasSuffix(name, "shes"):
+		name = name[:len(name)-2]
+	case strings.HasSuffix(name, "s") && !strings.HasSuffix(name, "ss"):
+		name = name[:len(name)-1]
+	}
+	return goName(splitWords(name))
+}
+
+// GoType returns the Go type of the values of a column of the SQL type typ.
+// Types it does not know are read as strings.
+func (d Dialect) GoType(typ string) string {
+	upper := strings.ToUpper(strings.TrimSpace(typ))
+	base, size, _ := strings.Cut(upper, "(")
+	base = strings.TrimSpace(base)
+	unsigned := strings.Contains(upper, "UNSIGNED")
+	base = strings.TrimSpace(strings.TrimSuffix(base, "UNSIGNED"))
+
+	integer := func(typ string) string {
+		if unsigned {
+			return "u" + typ
+		}
+		return typ
+	}
+
+	switch {
+	case strings.HasSuffix(upper, "[]"):
+		// Arrays have no portable Go type
+		return "string"
+	case base == "BOOL" || base == "BOOLEAN" || base == "TINYINT" && strings.HasPrefix(size, "1)"):
+		return "bool"
+	case base == "TINYINT" || base == "INT1":
+		return integer("int8")
+	case base == "SMALLINT" || base == "INT2" || base == "SMALLSERIAL":
+		return integer("int16")
+	case base == "BIGINT" || base == "INT8" || base == "BIGSERIAL":
+		return integer("int64")
+	case base == "INT" || base == "INTEGER" || base == "MEDIUMINT" || base == "INT4" || base == "SERIAL":
+		if d == DialectSQLite {
+			// SQLite integers have 64 bits
+			return integer("int64")
+		}
+		return integer("int32")
+	case base == "FLOAT4" || base == "FLOAT" && d == DialectMySQL || base == "REAL" && d != DialectSQLite:
+		return "float32"
+	case base == "REAL" || base == "FLOAT" || base == "FLOAT8" || strings.HasPrefix(base, "DOUBLE") ||
+		base == "DECIMAL" || base == "NUMERIC":
+		return "float64"
+	case base == "JSON" || base == "JSONB":
+		return "json.RawMessage"
+	case strings.HasPrefix(base, "DATE") || strings.HasPrefix(base, "TIME"):
+		return "time.Time"
+	case strings.Contains(base, "BLOB") || base == "BYTEA" || strings.Contains(base, "BINARY"):
+		return "[]byte"
+	default:
+		return "string"
+	}
+}
+
+// Fields returns the entity fields of the columns of the table, the key
+// being ID. Nullable columns are optional fields, and columns not named as
+// the fields would be keep their name with a db tag.
+func (t ImportedTable) Fields(dialect Dialect) (Fields, error) {
+	if t.Key == "" {
+		return nil, fmt.Errorf("table %s has no single column primary key to use as ID", t.Name)
+	}
+
+	var defs []string
+	for _, col := range t.Columns {
+		name := col.Name
+		if col.Name == t.Key {
+			name = "id"
+		} else if strings.EqualFold(col.Name, "id") {
+			// The ID field is the key, keep this column under another name
+			name = t.Name + "_id"
+		}
+		if !col.NotNull {
+			name += "?"
+		}
+
+		def := name + ":" + dialect.GoType(col.Type)
+		field, err := ParseField(def)
+		if err != nil {
+			return nil, fmt.Errorf("table %s: %w", t.Name, err)
+		}
+		if field.Column != col.Name {
+			def += ":db=" + col.Name
+		}
+		defs = append(defs, def)
+	}
+
+	fields, err := ParseFields(defs)
+	if err != nil {
+		return nil, fmt.Errorf("table %s: %w", t.Name, err)
+	}
+	return fields, nil
+}
@@ -0,0 +1,226 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseDDL(t *testing.T) {
+	ddl := "-- MySQL dump\n" +
+		"/*!40101 SET NAMES utf8mb4 */;\n" +
+		"CREATE TABLE IF NOT EXISTS `user_accounts` (\n" +
+		"  `account_id` bigint unsigned NOT NULL AUTO_INCREMENT,\n" +
+		"  `email` varchar(255) COLLATE utf8mb4_bin NOT NULL,\n" +
+		"  `balance` decimal(10,2) DEFAULT '0.00',\n" +
+		"  PRIMARY KEY (`account_id`),\n" +
+		"  UNIQUE KEY `email` (`email`)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;\n" +
+		"INSERT INTO user_accounts VALUES (1, 'a@b.c', 0);\n" +
+		"CREATE TABLE public.order_lines (\n" +
+		"    order_id integer NOT NULL,\n" +
+		"    line integer NOT NULL,\n" +
+		"    note text,\n" +
+		"    CONSTRAINT order_lines_pkey PRIMARY KEY (order_id, line)\n" +
+		");\n"
+
+	tables, err := ParseDDL(ddl)
+	if err != nil {
+		t.Fatalf("ParseDDL() failed: %v", err)
+	}
+	if len(tables) != 2 {
+		t.Fatalf("ParseDDL() = %+v, expected 2 tables", tables)
+	}
+
+	accounts := tables[0]
+	if accounts.Name != "user_accounts" || accounts.Key != "account_id" {
+		t.Errorf("table = %s with key %q, expected user_accounts with key account_id", accounts.Name, accounts.Key)
+	}
+	expected := []TableColumn{
+		{"account_id", "bigint unsigned", true, "bigint unsigned NOT NULL AUTO_INCREMENT"},
+		{"email", "varchar(255)", true, "varchar(255) COLLATE utf8mb4_bin NOT NULL"},
+		{"balance", "decimal(10,2)", false, "decimal(10,2) DEFAULT '0.00'"},
+	}
+	if !slices.Equal(accounts.Columns, expected) {
+		t.Errorf("columns = %+v, expected %+v", accounts.Columns, expected)
+	}
+
+	// A composite primary key is no ID
+	if lines := tables[1]; lines.Name != "order_lines" || lines.Key != "" || len(lines.Columns) != 3 {
+		t.Errorf("table = %+v, expected order_lines with 3 columns and no key", lines)
+	}
+}
+
+func TestDialect_GoType(t *testing.T) {
+	tests := []struct {
+		typ      string
+		dialect  Dialect
+		expected string
+	}{
+		{"VARCHAR(255)", DialectMySQL, "string"},
+		{"character varying", DialectPostgres, "string"},
+		{"uuid", DialectPostgres, "string"},
+		{"tinyint(1)", DialectMySQL, "bool"},
+		{"boolean", DialectPostgres, "bool"},
+		{"INTEGER", DialectSQLite, "int64"},
+		{"integer", DialectPostgres, "int32"},
+		{"int(10) unsigned", DialectMySQL, "uint32"},
+		{"bigserial", DialectPostgres, "int64"},
+		{"smallint", DialectMySQL, "int16"},
+		{"REAL", DialectSQLite, "float64"},
+		{"real", DialectPostgres, "float32"},
+		{"float", DialectMySQL, "float32"},
+		{"double precision", DialectPostgres, "float64"},
+		{"numeric(10,2)", DialectPostgres, "float64"},
+		{"timestamp with time zone", DialectPostgres, "time.Time"},
+		{"DATETIME(6)", DialectMySQL, "time.Time"},
+		{"bytea", DialectPostgres, "[]byte"},
+		{"LONGBLOB", DialectMySQL, "[]byte"},
+		{"jsonb", DialectPostgres, "json.RawMessage"},
+		{"text[]", DialectPostgres, "string"},
+		{"", DialectSQLite, "string"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.typ, func(t *testing.T) {
+			if result := tt.dialect.GoType(tt.typ); result != tt.expected {
+				t.Errorf("GoType(%q) = %q, expected %q", tt.typ, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEntityName(t *testing.T) {
+	tests := []struct {
+		table    string
+		expected string
+	}{
+		{"users", "User"},
+		{"order_items", "OrderItem"},
+		{"categories", "Category"},
+		{"addresses", "Address"},
+		{"boxes", "Box"},
+		{"person", "Person"},
+		{"Customers", "Customer"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.table, func(t *testing.T) {
+			if result := EntityName(tt.table); result != tt.expected {
+				t.Errorf("EntityName(%q) = %q, expected %q", tt.table, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestImportedTable_Fields(t *testing.T) {
+	table := ImportedTable{
+		Name: "accounts",
+		Key:  "account_id",
+		Columns: []TableColumn{
+			{Name: "account_id", Type: "INTEGER", NotNull: true},
+			{Name: "ownerName", Type: "TEXT", NotNull: true},
+			{Name: "closed_at", Type: "DATETIME"},
+			{Name: "settings", Type: "JSON"},
+		},
+	}
+
+	fields, err := table.Fields(DialectSQLite)
+	if err != nil {
+		t.Fatalf("Fields() failed: %v", err)
+	}
+
+	var result []string
+	for _, f := range fields {
+		result = append(result, f.Name+" "+f.Type+" "+f.Column)
+	}
+	expected := []string{
+		"ID int64 account_id",
+		"OwnerName string ownerName",
+		"ClosedAt *time.Time closed_at",
+		"Settings *json.RawMessage settings",
+	}
+	if !slices.Equal(result, expected) {
+		t.Errorf("Fields() = %q, expected %q", result, expected)
+	}
+	if imports := fields.Imports(); !slices.Equal(imports, []string{"encoding/json", "time"}) {
+		t.Errorf("Imports() = %v, expected [encoding/json time]", imports)
+	}
+
+	table.Key = ""
+	if _, err := table.Fields(DialectSQLite); err == nil {
+		t.Error("A table without a single column key should fail")
+	}
+}
+
+func TestImportedTable_ReservedWords(t *testing.T) {
+	tables, err := ParseDDL(`CREATE TABLE "order" ("id" BIGINT PRIMARY KEY, "select" TEXT NOT NULL, "group" INTEGER);`)
+	if err != nil || len(tables) != 1 {
+		t.Fatalf("ParseDDL() = %+v, %v, expected the order table", tables, err)
+	}
+	fields, err := tables[0].Fields(DialectPostgres)
+	if err != nil {
+		t.Fatalf("Fields() failed: %v", err)
+	}
+
+	table := NewSQLTable(DialectPostgres, "Order", fields)
+	table.Name = tables[0].Name
+	if got, want := table.SelectByID(), `SELECT "id", "select", "group" FROM "order" WHERE "id" = $1`; got != want {
+		t.Errorf("SelectByID() = %s, expected %s", got, want)
+	}
+	if got, want := table.Update(), `UPDATE "order" SET "select" = $1, "group" = $2 WHERE "id" = $3`; got != want {
+		t.Errorf("Update() = %s, expected %s", got, want)
+	}
+
+	// The migration of the table reads back as the imported columns
+	up, down := table.AlterTable(tables[0].Columns)
+	if up != nil || down != nil {
+		t.Errorf("The imported table should need no statements, got %q and %q", up, down)
+	}
+}
+
+func TestReadSQLiteSchema(t *testing.T) {
+	// legacy.db has 512 byte pages, so its schema spans interior pages and
+	// the CREATE TABLE of order_items overflows
+	stmts, err := ReadSQLiteSchema("testdata/legacy.db")
+	if err != nil {
+		t.Fatalf("ReadSQLiteSchema() failed: %v", err)
+	}
+	if len(stmts) != 13 {
+		t.Fatalf("ReadSQLiteSchema() returned %d statements, expected 13 tables", len(stmts))
+	}
+
+	tables, err := ParseDDL(strings.Join(stmts, "\n"))
+	if err != nil {
+		t.Fatalf("ParseDDL() failed: %v", err)
+	}
+	users, categories, items := tables[0], tables[1], tables[2]
+	if users.Name != "users" || users.Key != "id" || len(users.Columns) != 5 {
+		t.Errorf("users = %+v", users)
+	}
+	if categories.Name != "categories" || categories.Key != "code" {
+		t.Errorf("categories = %+v", categories)
+	}
+	if items.Name != "order_items" || items.Key != "id" || len(items.Columns) != 42 {
+		t.Errorf("order_items has %d columns and key %q, expected 42 and id", len(items.Columns), items.Key)
+	}
+
+	if _, err := ReadSQLiteSchema("sql_import.go"); err == nil {
+		t.Error("Reading a file that is not a database should fail")
+	}
+
+	// Tables created since the last checkpoint are only in the -wal file
+	data, _ := os.ReadFile("testdata/legacy.db")
+	db := filepath.Join(t.TempDir(), "app.db")
+	os.WriteFile(db, data, 0644)
+	os.WriteFile(db+"-wal", nil, 0644)
+	if _, err := ReadSQLiteSchema(db); err != nil {
+		t.Errorf("An empty -wal file should be ignored: %v", err)
+	}
+	os.WriteFile(db+"-wal", []byte("frames"), 0644)
+	if _, err := ReadSQLiteSchema(db); err == nil || !strings.Contains(err.Error(), "app.db-wal") {
+		t.Errorf("A database with a non-empty -wal file should fail, got %v", err)
+	}
+}
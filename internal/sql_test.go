@@ -0,0 +1,231 @@
+package internal
+
+import "testing"
+
+func TestTableName(t *testing.T) {
+	tests := map[string]string{
+		"User":      "users",
+		"OrderItem": "order_items",
+		"Category":  "categories",
+		"Day":       "days",
+		"Address":   "addresses",
+		"Box":       "boxes",
+		"Match":     "matches",
+		"product":   "products",
+	}
+
+	for entity, want := range tests {
+		if got := TableName(entity); got != want {
+			t.Errorf("TableName(%q) = %q, want %q", entity, got, want)
+		}
+	}
+}
+
+func TestSQLTable(t *testing.T) {
+	fields, err := ParseFields([]string{"name:string", "email:string:db=mail"})
+	if err != nil {
+		t.Fatalf("ParseFields() failed: %v", err)
+	}
+
+	tests := []struct {
+		dialect    Dialect
+		upsert     string
+		selectByID string
+	}{
+		{
+			dialect:    DialectPostgres,
+			upsert:     `INSERT INTO "users" ("id", "name", "mail") VALUES ($1, $2, $3) ON CONFLICT ("id") DO UPDATE SET "name" = EXCLUDED."name", "mail" = EXCLUDED."mail" RETURNING "id"`,
+			selectByID: `SELECT "id", "name", "mail" FROM "users" WHERE "id" = $1`,
+		},
+		{
+			dialect:    DialectSQLite,
+			upsert:     `INSERT INTO "users" ("id", "name", "mail") VALUES (?, ?, ?) ON CONFLICT ("id") DO UPDATE SET "name" = EXCLUDED."name", "mail" = EXCLUDED."mail" RETURNING "id"`,
+			selectByID: `SELECT "id", "name", "mail" FROM "users" WHERE "id" = ?`,
+		},
+		{
+			dialect:    DialectMySQL,
+			upsert:     "INSERT INTO `users` (`id`, `name`, `mail`) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE `name` = VALUES(`name`), `mail` = VALUES(`mail`)",
+			selectByID: "SELECT `id`, `name`, `mail` FROM `users` WHERE `id` = ?",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.dialect), func(t *testing.T) {
+			table := NewSQLTable(tt.dialect, "User", fields)
+			if got := table.Upsert(); got != tt.upsert {
+				t.Errorf("Upsert() = %q, want %q", got, tt.upsert)
+			}
+			if got := table.SelectByID(); got != tt.selectByID {
+				t.Errorf("SelectByID() = %q, want %q", got, tt.selectByID)
+			}
+			if got := table.Args("e"); got != "e.ID, e.Name, e.Email" {
+				t.Errorf("Args() = %q", got)
+			}
+			if got := table.Dests("e"); got != "&e.ID, &e.Name, &e.Email" {
+				t.Errorf("Dests() = %q", got)
+			}
+		})
+	}
+
+	tag := Fields{{Name: "ID", Column: "id", Type: "string"}}
+	if got := NewSQLTable(DialectPostgres, "Tag", tag).Update(); got != `UPDATE "tags" SET "id" = "id" WHERE "id" = $1` {
+		t.Errorf("Update() without columns to update = %q", got)
+	}
+	if got := NewSQLTable(DialectPostgres, "Tag", tag).Upsert(); got != `INSERT INTO "tags" ("id") VALUES ($1) ON CONFLICT ("id") DO UPDATE SET "id" = EXCLUDED."id" RETURNING "id"` {
+		t.Errorf("Upsert() without columns to update = %q", got)
+	}
+	if NewSQLTable(DialectPostgres, "User", Fields{{Name: "Name", Column: "name", Type: "string"}}) != nil {
+		t.Error("NewSQLTable() without an ID field should return nil")
+	}
+}
+
+func TestSQLTable_CRUD(t *testing.T) {
+	fields, err := ParseFields([]string{"name:string", "email:string"})
+	if err != nil {
+		t.Fatalf("ParseFields() failed: %v", err)
+	}
+
+	tests := []struct {
+		dialect Dialect
+		insert  string
+		update  string
+		delete  string
+	}{
+		{
+			dialect: DialectPostgres,
+			insert:  `INSERT INTO "users" ("id", "name", "email") VALUES ($1, $2, $3) ON CONFLICT ("id") DO NOTHING RETURNING "id"`,
+			update:  `UPDATE "users" SET "name" = $1, "email" = $2 WHERE "id" = $3`,
+			delete:  `DELETE FROM "users" WHERE "id" = $1`,
+		},
+		{
+			dialect: DialectSQLite,
+			insert:  `INSERT INTO "users" ("id", "name", "email") VALUES (?, ?, ?) ON CONFLICT ("id") DO NOTHING RETURNING "id"`,
+			update:  `UPDATE "users" SET "name" = ?, "email" = ? WHERE "id" = ?`,
+			delete:  `DELETE FROM "users" WHERE "id" = ?`,
+		},
+		{
+			dialect: DialectMySQL,
+			insert:  "INSERT INTO `users` (`id`, `name`, `email`) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE `id` = `id`",
+			update:  "UPDATE `users` SET `name` = ?, `email` = ? WHERE `id` = ?",
+			delete:  "DELETE FROM `users` WHERE `id` = ?",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.dialect), func(t *testing.T) {
+			table := NewSQLTable(tt.dialect, "User", fields)
+			if got := table.Insert(); got != tt.insert {
+				t.Errorf("Insert() = %q, want %q", got, tt.insert)
+			}
+			if got := table.Update(); got != tt.update {
+				t.Errorf("Update() = %q, want %q", got, tt.update)
+			}
+			if got := table.UpdateArgs("e"); got != "e.Name, e.Email, e.ID" {
+				t.Errorf("UpdateArgs() = %q", got)
+			}
+			if got := table.Delete(); got != tt.delete {
+				t.Errorf("Delete() = %q, want %q", got, tt.delete)
+			}
+			q := tt.dialect.Quote
+			if got, want := table.Select(), "SELECT "+q("id")+", "+q("name")+", "+q("email")+" FROM "+q("users"); got != want {
+				t.Errorf("Select() = %q, want %q", got, want)
+			}
+			if got, want := table.Count(), "SELECT COUNT(*) FROM "+q("users"); got != want {
+				t.Errorf("Count() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestDialect_Quote(t *testing.T) {
+	tests := []struct {
+		dialect Dialect
+		name    string
+		want    string
+	}{
+		{DialectPostgres, "order", `"order"`},
+		{DialectSQLite, `say "hi"`, `"say ""hi"""`},
+		{DialectPostgres, "app.users", `"app"."users"`},
+		{DialectMySQL, "order", "`order`"},
+		{DialectMySQL, "a`b", "`a``b`"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.dialect.Quote(tt.name); got != tt.want {
+			t.Errorf("%s.Quote(%q) = %s, want %s", tt.dialect, tt.name, got, tt.want)
+		}
+	}
+
+	if got := DialectPostgres.Literal(`"id" = $1`); got != "`\"id\" = $1`" {
+		t.Errorf("Literal() = %s, want a raw string", got)
+	}
+	if got := DialectMySQL.Literal("`id` = ?"); got != `"`+"`id` = ?"+`"` {
+		t.Errorf("Literal() = %s, want an interpreted string", got)
+	}
+	if got := DialectPostgres.Bind(`"name" = `); got != "fmt.Sprintf(`\"name\" = $%d`, len(args))" {
+		t.Errorf("Bind() = %s", got)
+	}
+}
+
+func TestSQLTable_JSON(t *testing.T) {
+	fields, err := ParseFields([]string{"name:string", "tags:[]string", "labels?:map[string]string", "avatar:[]byte"})
+	if err != nil {
+		t.Fatalf("ParseFields() failed: %v", err)
+	}
+
+	table := NewSQLTable(DialectPostgres, "User", fields)
+	if !table.JSON() {
+		t.Error("JSON() = false with slice and map fields")
+	}
+	if got, want := table.Args("e"), "e.ID, e.Name, jsonColumn{e.Tags}, jsonColumn{e.Labels}, e.Avatar"; got != want {
+		t.Errorf("Args() = %q, want %q", got, want)
+	}
+	if got, want := table.UpdateArgs("e"), "e.Name, jsonColumn{e.Tags}, jsonColumn{e.Labels}, e.Avatar, e.ID"; got != want {
+		t.Errorf("UpdateArgs() = %q, want %q", got, want)
+	}
+	if got, want := table.Dests("e"), "&e.ID, &e.Name, jsonColumn{&e.Tags}, jsonColumn{&e.Labels}, &e.Avatar"; got != want {
+		t.Errorf("Dests() = %q, want %q", got, want)
+	}
+	if NewSQLTable(DialectPostgres, "User", fields[:2]).JSON() {
+		t.Error("JSON() = true without slice or map fields")
+	}
+}
+
+func TestSQLTable_Generated(t *testing.T) {
+	fields := Fields{{Name: "ID", Column: "id", Type: "int64"}, {Name: "Name", Column: "name", Type: "string"}}
+
+	tests := []struct {
+		dialect   Dialect
+		generated bool
+		insert    string
+	}{
+		{DialectPostgres, true, `INSERT INTO "users" ("name") VALUES ($1) RETURNING "id"`},
+		{DialectSQLite, true, `INSERT INTO "users" ("name") VALUES (?) RETURNING "id"`},
+		{DialectMySQL, false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.dialect), func(t *testing.T) {
+			table := NewSQLTable(tt.dialect, "User", fields)
+			if got := table.Generated(); got != tt.generated {
+				t.Fatalf("Generated() = %v, want %v", got, tt.generated)
+			}
+			if !tt.generated {
+				return
+			}
+			if got := table.InsertGenerated(); got != tt.insert {
+				t.Errorf("InsertGenerated() = %q, want %q", got, tt.insert)
+			}
+			if got := table.ValueArgs("e"); got != "e.Name" {
+				t.Errorf("ValueArgs() = %q", got)
+			}
+		})
+	}
+
+	if NewSQLTable(DialectPostgres, "User", Fields{{Name: "ID", Column: "id", Type: "string"}}).Generated() {
+		t.Error("Generated() = true with a string key")
+	}
+	if got := NewSQLTable(DialectPostgres, "User", fields[:1]).InsertGenerated(); got != `INSERT INTO "users" DEFAULT VALUES RETURNING "id"` {
+		t.Errorf("InsertGenerated() without columns = %q", got)
+	}
+}
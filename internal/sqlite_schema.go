@@ -0,0 +1,246 @@
+package internal
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+)
+
+// sqliteHeader starts every SQLite database file
+const sqliteHeader = "SQLite format 3\x00"
+
+// ReadSQLiteSchema returns the CREATE TABLE statements of the tables of the
+// SQLite database file at path. It reads them from the sqlite_schema table
+// of the file itself, so it needs no SQLite driver. Changes still in the
+// write-ahead log are not in the file, so a database with a non-empty
+// -wal file is refused.
+func ReadSQLiteSchema(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if info, err := os.Stat(path + "-wal"); err == nil && info.Size() > 0 {
+		return nil, fmt.Errorf("%s has changes not yet written to it in %s-wal: close the applications using it or run PRAGMA wal_checkpoint(TRUNCATE) first", path, path)
+	}
+	if len(data) < 100 || string(data[:16]) != sqliteHeader {
+		return nil, fmt.Errorf("%s is not a SQLite database", path)
+	}
+	if encoding := binary.BigEndian.Uint32(data[56:60]); encoding > 1 {
+		return nil, fmt.Errorf("%s: only UTF-8 databases are supported", path)
+	}
+
+	pageSize := int(binary.BigEndian.Uint16(data[16:18]))
+	if pageSize == 1 {
+		pageSize = 65536
+	}
+	db := &sqliteFile{data: data, pageSize: pageSize, usable: pageSize - int(data[20])}
+
+	var stmts []string
+	// sqlite_schema is the table b-tree rooted at page 1, its columns are
+	// type, name, tbl_name, rootpage and sql
+	err = db.walk(1, func(record []any) {
+		if len(record) < 5 {
+			return
+		}
+		typ, _ := record[0].(string)
+		name, _ := record[1].(string)
+		sql, _ := record[4].(string)
+		if typ == "table" && !strings.HasPrefix(name, "sqlite_") && sql != "" {
+			stmts = append(stmts, sql+";")
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return stmts, nil
+}
+
+// errCorrupt is returned for pages that do not follow the file format
+var errCorrupt = errors.New("malformed database file")
+
+// sqliteFile is a SQLite database file read in memory
+type sqliteFile struct {
+	data     []byte
+	pageSize int
+	usable   int // page size minus the space reserved at the end of pages
+}
+
+// page returns the page with the given 1-based number
+func (f *sqliteFile) page(n int) ([]byte, error) {
+	start := (n - 1) * f.pageSize
+	if n < 1 || start+f.pageSize > len(f.data) {
+		return nil, errCorrupt
+	}
+	return f.data[start : start+f.pageSize], nil
+}
+
+// walk calls fn with the record of every row of the table b-tree rooted at
+// page root, in rowid order
+func (f *sqliteFile) walk(root int, fn func([]any)) error {
+	page, err := f.page(root)
+	if err != nil {
+		return err
+	}
+	header := 0
+	if root == 1 {
+		// The file header comes first
+		header = 100
+	}
+
+	kind := page[header]
+	cells := int(binary.BigEndian.Uint16(page[header+3:]))
+	pointers := header + 8
+	if kind == 0x05 {
+		pointers = header + 12
+	}
+
+	for i := range cells {
+		offset := int(binary.BigEndian.Uint16(page[pointers+2*i:]))
+		if offset >= len(page) {
+			return errCorrupt
+		}
+		switch kind {
+		case 0x05: // interior page: left child and key
+			if err := f.walk(int(binary.BigEndian.Uint32(page[offset:])), fn); err != nil {
+				return err
+			}
+		case 0x0d: // leaf page: payload size, rowid and payload
+			payload, err := f.payload(page, offset)
+			if err != nil {
+				return err
+			}
+			record, err := parseRecord(payload)
+			if err != nil {
+				return err
+			}
+			fn(record)
+		default:
+			return errCorrupt
+		}
+	}
+
+	if kind == 0x05 {
+		return f.walk(int(binary.BigEndian.Uint32(page[header+8:])), fn)
+	}
+	return nil
+}
+
+// payload returns the payload of the leaf cell at offset of page, following
+// its overflow pages
+func (f *sqliteFile) payload(page []byte, offset int) ([]byte, error) {
+	size, n := sqliteVarint(page[offset:])
+	offset += n
+	_, n = sqliteVarint(page[offset:]) // rowid
+	offset += n
+
+	total := int(size)
+	local := total
+	if maxLocal := f.usable - 35; total > maxLocal {
+		minLocal := (f.usable-12)*32/255 - 23
+		local = minLocal + (total-minLocal)%(f.usable-4)
+		if local > maxLocal {
+			local = minLocal
+		}
+	}
+	if offset+local > len(page) {
+		return nil, errCorrupt
+	}
+
+	payload := append([]byte(nil), page[offset:offset+local]...)
+	if local == total {
+		return payload, nil
+	}
+
+	next := int(binary.BigEndian.Uint32(page[offset+local:]))
+	for len(payload) < total {
+		overflow, err := f.page(next)
+		if err != nil {
+			return nil, err
+		}
+		next = int(binary.BigEndian.Uint32(overflow))
+		chunk := overflow[4:f.usable]
+		if rest := total - len(payload); len(chunk) > rest {
+			chunk = chunk[:rest]
+		}
+		payload = append(payload, chunk...)
+	}
+	return payload, nil
+}
+
+// parseRecord decodes the values of a record: nil, int64, float64, string
+// or []byte
+func parseRecord(payload []byte) ([]any, error) {
+	headerSize, n := sqliteVarint(payload)
+	if int(headerSize) > len(payload) {
+		return nil, errCorrupt
+	}
+
+	var values []any
+	body := int(headerSize)
+	for pos := n; pos < int(headerSize); {
+		serial, n := sqliteVarint(payload[pos:])
+		pos += n
+
+		var size int
+		switch {
+		case serial <= 4:
+			size = int(serial)
+		case serial == 5:
+			size = 6
+		case serial == 6, serial == 7:
+			size = 8
+		case serial >= 12:
+			size = int(serial-12) / 2
+		}
+		if body+size > len(payload) {
+			return nil, errCorrupt
+		}
+		value := payload[body : body+size]
+		body += size
+
+		switch {
+		case serial == 0:
+			values = append(values, nil)
+		case serial <= 6:
+			// Big-endian two's complement integers of 1 to 8 bytes
+			var v int64
+			if value[0]&0x80 != 0 {
+				v = -1
+			}
+			for _, b := range value {
+				v = v<<8 | int64(b)
+			}
+			values = append(values, v)
+		case serial == 7:
+			values = append(values, math.Float64frombits(binary.BigEndian.Uint64(value)))
+		case serial == 8, serial == 9:
+			values = append(values, int64(serial-8))
+		case serial >= 12 && serial%2 == 0:
+			values = append(values, append([]byte(nil), value...))
+		case serial >= 13:
+			values = append(values, string(value))
+		default:
+			return nil, errCorrupt
+		}
+	}
+	return values, nil
+}
+
+// sqliteVarint decodes the variable-length integer b starts with and
+// returns it with the number of bytes it takes
+func sqliteVarint(b []byte) (uint64, int) {
+	var v uint64
+	for i := 0; i < 9 && i < len(b); i++ {
+		if i == 8 {
+			return v<<8 | uint64(b[i]), 9
+		}
+		v = v<<7 | uint64(b[i]&0x7f)
+		if b[i]&0x80 == 0 {
+			return v, i + 1
+		}
+	}
+	return v, len(b)
+}
@@ -1,6 +1,13 @@
 package entities
-
+{{ with .Fields.Imports }}
+import (
+{{- range . }}
+	"{{ . }}"
+{{- end }}
+)
+{{ end }}
 type {{ .Name }} struct {
-    ID   string
-    Name string
-}
\ No newline at end of file
+{{- range .Fields }}
+	{{ .Name }} {{ .Type }}{{ with .StructTag }} `{{ . }}`{{ end }}
+{{- end }}
+}
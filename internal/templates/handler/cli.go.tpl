@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+
+	"{{ .Module }}/internal/usecases"
+)
+
+// {{ .Name }}Handler runs the {{ .UseCase }} use case as the {{ .Command }} command
+type {{ .Name }}Handler struct {
+	UC *usecases.{{ .UseCase }}UseCase
+}
+
+func New{{ .Name }}Handler(uc *usecases.{{ .UseCase }}UseCase) *{{ .Name }}Handler {
+	return &{{ .Name }}Handler{UC: uc}
+}
+
+// Register adds the {{ .Command }} command to root. Its flags fill the input of
+// the use case, and --output picks how the result is printed.
+func (h *{{ .Name }}Handler) Register(root *cobra.Command) {
+	var input usecases.{{ .UseCase }}Input
+	output := formatTable
+	cmd := &cobra.Command{
+		Use:   "{{ .Command }}",
+		Short: "{{ .Short }}",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := h.UC.Execute({{ if .Context }}cmd.Context(), {{ end }}input)
+			if err != nil {
+				return err
+			}
+			return writeOutput(cmd.OutOrStdout(), output, result)
+		},
+	}
+{{- range .Input }}
+{{- $flag := .Flag "input" }}
+{{- if $flag }}
+	cmd.Flags().{{ $flag }}
+{{- else }}
+	// TODO: add a flag for {{ .Name }} {{ .Type }}
+{{- end }}
+{{- end }}
+	cmd.Flags().VarP(&output, "output", "o", "output format (table, json)")
+	root.AddCommand(cmd)
+}
@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Formats of --output
+const (
+	formatTable outputFormat = "table"
+	formatJSON  outputFormat = "json"
+)
+
+// outputFormat is the value of the --output flag of the commands
+type outputFormat string
+
+func (f *outputFormat) Set(s string) error {
+	switch outputFormat(s) {
+	case formatTable, formatJSON:
+		*f = outputFormat(s)
+		return nil
+	}
+	return fmt.Errorf("unknown format %q, expected table or json", s)
+}
+
+func (f *outputFormat) String() string {
+	return string(*f)
+}
+
+func (f *outputFormat) Type() string {
+	return "format"
+}
+
+// writeOutput writes the result of a use case to w as indented JSON, or as
+// a table with a column per field and a row per struct
+func writeOutput(w io.Writer, format outputFormat, result any) error {
+	if format == formatJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+	}
+
+	v := reflect.Indirect(reflect.ValueOf(result))
+	if !v.IsValid() {
+		// Nothing to print
+		return nil
+	}
+	rows, typ := []reflect.Value{v}, v.Type()
+	if v.Kind() == reflect.Slice {
+		rows, typ = nil, typ.Elem()
+		for i := 0; i < v.Len(); i++ {
+			if row := reflect.Indirect(v.Index(i)); row.IsValid() {
+				rows = append(rows, row)
+			}
+		}
+	}
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		// Anything but structs is printed as it is
+		_, err := fmt.Fprintln(w, valueString(v))
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	var header []string
+	for i := 0; i < typ.NumField(); i++ {
+		if typ.Field(i).IsExported() {
+			header = append(header, strings.ToUpper(columnName(typ.Field(i))))
+		}
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		var cells []string
+		for i := 0; i < typ.NumField(); i++ {
+			if typ.Field(i).IsExported() {
+				cells = append(cells, valueString(row.Field(i)))
+			}
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+// columnName returns the JSON name of a field, its name when it has none
+func columnName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
+
+// valueString formats a cell of the table, empty for nil pointers
+func valueString(v reflect.Value) string {
+	if !v.IsValid() {
+		return ""
+	}
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if t, ok := v.Interface().(time.Time); ok {
+		return t.Format(time.RFC3339)
+	}
+	return fmt.Sprint(v.Interface())
+}
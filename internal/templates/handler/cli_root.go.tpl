@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// NewRoot returns the root command of {{ .ProjectName }}, the CLI handlers add their
+// commands to it
+func NewRoot() *cobra.Command {
+	return &cobra.Command{
+		Use:   "{{ .ProjectName }}",
+		Short: "{{ .ProjectName }} runs its use cases from the command line",
+		// main reports the error of the command
+		SilenceErrors: true,
+		SilenceUsage:  true,
+	}
+}
+
+// timeValue is the flag value of a time.Time field, written in RFC 3339
+type timeValue time.Time
+
+func (v *timeValue) Set(s string) error {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return err
+	}
+	*v = timeValue(t)
+	return nil
+}
+
+func (v *timeValue) String() string {
+	if time.Time(*v).IsZero() {
+		return ""
+	}
+	return time.Time(*v).Format(time.RFC3339)
+}
+
+func (v *timeValue) Type() string {
+	return "time"
+}
@@ -0,0 +1,39 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"{{ .Module }}/internal/messaging"
+	"{{ .Module }}/internal/usecases"
+)
+
+// {{ .Name }}Topic is the topic of the messages {{ .Name }}Handler consumes
+const {{ .Name }}Topic = "{{ .Topic }}"
+
+// {{ .Name }}Handler runs the {{ .UseCase }} use case for every message of
+// {{ .Name }}Topic, whose payload is the input of the use case in JSON
+type {{ .Name }}Handler struct {
+	UC    *usecases.{{ .UseCase }}UseCase
+	Retry RetryPolicy
+}
+
+func New{{ .Name }}Handler(uc *usecases.{{ .UseCase }}UseCase) *{{ .Name }}Handler {
+	return &{{ .Name }}Handler{UC: uc, Retry: DefaultRetryPolicy}
+}
+
+// Register subscribes the handler to its topic on broker, where the messages
+// that keep failing are published on the dead-letter topic
+func (h *{{ .Name }}Handler) Register(broker messaging.Broker) error {
+	return broker.Subscribe({{ .Name }}Topic, func(ctx context.Context, msg messaging.Message) error {
+		return handle(ctx, broker, h.Retry, msg, func(ctx context.Context) error {
+			var input usecases.{{ .UseCase }}Input
+			if err := json.Unmarshal(msg.Payload, &input); err != nil {
+				return fmt.Errorf("%w: invalid payload: %v", errPermanent, err)
+			}
+			_, err := h.UC.Execute({{ if .Context }}ctx, {{ end }}input)
+			return err
+		})
+	})
+}
@@ -0,0 +1,68 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"{{ .Module }}/internal/domain"
+	"{{ .Module }}/internal/messaging"
+)
+
+// RetryPolicy is how often a consumer handler runs its use case for a
+// message before giving up on it
+type RetryPolicy struct {
+	Attempts int           // runs of the use case, at least one
+	Backoff  time.Duration // wait before the first retry, doubled for the next ones
+}
+
+// DefaultRetryPolicy runs the use case up to three times, waiting 100ms and
+// then 200ms
+var DefaultRetryPolicy = RetryPolicy{Attempts: 3, Backoff: 100 * time.Millisecond}
+
+// DeadLetterTopic returns the topic the messages of topic that could not be
+// handled are published on
+func DeadLetterTopic(topic string) string {
+	return topic + ".dead-letter"
+}
+
+// errPermanent marks errors retrying does not fix, such as a payload that is
+// not valid JSON
+var errPermanent = errors.New("permanent failure")
+
+// handle runs process for msg following policy. Errors wrapping
+// domain.ErrInvalidInput or errPermanent are not retried. A message that
+// still fails is published on the dead-letter topic of its topic, with the
+// error and the attempts in its headers, and only the failure to publish it
+// is returned.
+func handle(ctx context.Context, dlq messaging.Publisher, policy RetryPolicy, msg messaging.Message, process func(context.Context) error) error {
+	var err error
+	attempts := 0
+	backoff := policy.Backoff
+	for {
+		attempts++
+		if err = process(ctx); err == nil {
+			return nil
+		}
+		if attempts >= policy.Attempts || errors.Is(err, errPermanent) || errors.Is(err, domain.ErrInvalidInput) {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+
+	log.Printf("Message of %s failed after %d attempts, sent to %s: %v", msg.Topic, attempts, DeadLetterTopic(msg.Topic), err)
+	headers := map[string]string{}
+	for key, value := range msg.Headers {
+		headers[key] = value
+	}
+	headers["error"] = err.Error()
+	headers["attempts"] = strconv.Itoa(attempts)
+	return dlq.Publish(ctx, messaging.Message{Topic: DeadLetterTopic(msg.Topic), Payload: msg.Payload, Headers: headers})
+}
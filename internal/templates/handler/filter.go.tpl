@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+{{- range .Fields.FilterImports }}
+	"{{ . }}"
+{{- end }}
+
+	"{{ .Module }}/internal/domain"
+	"{{ .Module }}/internal/repository"
+)
+
+// Parse{{ .Entity }}Filter maps the query parameters of a request onto a
+// repository.{{ .Entity }}Filter, e.g.
+// ?{{ with .Fields.Filterable }}{{ (index . 0).ParamName }}=value&{{ end }}sort=-{{ .ID.ParamName }}&limit=20&offset=40{{ if .ID.Ordered }} or ?after=<last {{ .ID.ParamName }}>&limit=20{{ end }}
+func Parse{{ .Entity }}Filter(query url.Values) (repository.{{ .Entity }}Filter, error) {
+	filter := repository.{{ .Entity }}Filter{Sort: query.Get("sort")}
+{{- range .Fields.Filterable }}
+
+	if v := query.Get("{{ .ParamName }}"); v != "" {
+{{- if .Parse "v" }}
+		parsed, err := {{ .Parse "v" }}
+		if err != nil {
+			return filter, fmt.Errorf("%w: {{ .ParamName }}: %v", domain.ErrInvalidFilter, err)
+		}
+{{- if eq (.Convert "parsed") "parsed" }}
+		filter.{{ .Name }} = &parsed
+{{- else }}
+		value := {{ .Convert "parsed" }}
+		filter.{{ .Name }} = &value
+{{- end }}
+{{- else }}
+		filter.{{ .Name }} = &v
+{{- end }}
+	}
+{{- end }}
+{{- if .ID.Ordered }}
+
+	if v := query.Get("after"); v != "" {
+{{- if .ID.Parse "v" }}
+		parsed, err := {{ .ID.Parse "v" }}
+		if err != nil {
+			return filter, fmt.Errorf("%w: after: %v", domain.ErrInvalidFilter, err)
+		}
+{{- if eq (.ID.Convert "parsed") "parsed" }}
+		filter.After = &parsed
+{{- else }}
+		value := {{ .ID.Convert "parsed" }}
+		filter.After = &value
+{{- end }}
+{{- else }}
+		filter.After = &v
+{{- end }}
+	}
+{{- end }}
+
+	for param, dest := range map[string]*int{"limit": &filter.Limit, "offset": &filter.Offset} {
+		if v := query.Get(param); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return filter, fmt.Errorf("%w: %s: %v", domain.ErrInvalidFilter, param, err)
+			}
+			*dest = n
+		}
+	}
+
+	return filter, filter.Validate()
+}
@@ -0,0 +1,61 @@
+package grpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	{{ .Proto.GoName }} "{{ .Proto.GoImport }}"
+	"{{ .Module }}/internal/usecases"
+)
+
+// {{ .Name }}Handler serves the {{ .UseCase }} use case as the
+// {{ .UseCase }}Service of {{ .Proto.Path }}
+type {{ .Name }}Handler struct {
+	{{ .Proto.GoName }}.Unimplemented{{ .UseCase }}ServiceServer
+	UC *usecases.{{ .UseCase }}UseCase
+}
+
+func New{{ .Name }}Handler(uc *usecases.{{ .UseCase }}UseCase) *{{ .Name }}Handler {
+	return &{{ .Name }}Handler{UC: uc}
+}
+
+// Register adds the service of the handler to server
+func (h *{{ .Name }}Handler) Register(server grpc.ServiceRegistrar) {
+	{{ .Proto.GoName }}.Register{{ .UseCase }}ServiceServer(server, h)
+}
+
+// {{ .UseCase }} executes the use case with the input of the request. Errors
+// are mapped to status codes by toStatus.
+func (h *{{ .Name }}Handler) {{ .UseCase }}(ctx context.Context, req *{{ .Proto.GoName }}.{{ .UseCase }}Request) (*{{ .Proto.GoName }}.{{ .Response }}, error) {
+	input := usecases.{{ .UseCase }}Input{
+{{- range .Input }}
+{{- $value := .FromProto (print "req." .ProtoGoName) }}
+{{- if $value }}
+		{{ .Name }}: {{ $value }},
+{{- else }}
+		// TODO: set {{ .Name }} from the request
+{{- end }}
+{{- else }}
+		// TODO: add the fields of {{ .UseCase }}Input and set them from req
+{{- end }}
+	}
+
+{{- if .List }}
+	list, err := h.UC.Execute({{ if .Context }}ctx, {{ end }}input)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	res := &{{ .Proto.GoName }}.{{ .Response }}{}
+	for _, e := range list {
+		res.Items = append(res.Items, {{ .ToProto }}(e))
+	}
+	return res, nil
+{{- else }}
+	entity, err := h.UC.Execute({{ if .Context }}ctx, {{ end }}input)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return {{ .ToProto }}(entity), nil
+{{- end }}
+}
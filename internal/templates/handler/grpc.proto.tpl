@@ -0,0 +1,7 @@
+syntax = "proto3";
+
+// Services of the use cases of {{ .Entity }}, served by the handlers in
+// internal/handlers/grpc
+package {{ .Proto.Package }};
+
+option go_package = "{{ .Proto.GoImport }};{{ .Proto.GoName }}";
@@ -0,0 +1,32 @@
+package grpc
+
+import (
+{{- if .Timestamp }}
+	"google.golang.org/protobuf/types/known/timestamppb"
+{{ end }}
+	{{ .Proto.GoName }} "{{ .Proto.GoImport }}"
+	"{{ .Module }}/internal/domain/entities"
+)
+
+// {{ .ToProto }} converts the {{ .Entity }} e to its message in {{ .Proto.Path }}
+func {{ .ToProto }}(e *entities.{{ .Entity }}) *{{ .Proto.GoName }}.{{ .Entity }} {
+	if e == nil {
+		return nil
+	}
+	m := &{{ .Proto.GoName }}.{{ .Entity }}{
+{{- range .Fields }}
+{{- $value := .ToProto (print "e." .Name) }}
+{{- if $value }}
+		{{ .ProtoGoName }}: {{ $value }},
+{{- end }}
+{{- end }}
+	}
+{{- range .Fields }}
+{{- if not (.ToProto (print "e." .Name)) }}
+{{- with .SetProto (print "e." .Name) (print "m." .ProtoGoName) }}
+	{{ . }}
+{{- end }}
+{{- end }}
+{{- end }}
+	return m
+}
@@ -0,0 +1,42 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"{{ .Module }}/internal/domain"
+)
+
+// toStatus converts an error returned by a use case to a gRPC status error.
+// Domain errors get their code, anything else is an internal error whose
+// details are only logged.
+func toStatus(err error) error {
+	code := codeOf(err)
+	if code == codes.Internal {
+		log.Printf("Internal error: %v", err)
+		return status.Error(code, "internal error")
+	}
+	return status.Error(code, err.Error())
+}
+
+// codeOf returns the gRPC status code of an error returned by a use case
+func codeOf(err error) codes.Code {
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput), errors.Is(err, domain.ErrInvalidFilter):
+		return codes.InvalidArgument
+	case errors.Is(err, domain.ErrNotFound):
+		return codes.NotFound
+	case errors.Is(err, domain.ErrAlreadyExists):
+		return codes.AlreadyExists
+	case errors.Is(err, context.Canceled):
+		return codes.Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return codes.DeadlineExceeded
+	default:
+		return codes.Internal
+	}
+}
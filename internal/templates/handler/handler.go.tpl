@@ -1,6 +1,9 @@
 package handlers
 
 import (
+{{- if .Context }}
+	"context"
+{{- end }}
 	"fmt"
 
 	"{{ .Module }}/internal/usecases"
@@ -15,15 +18,29 @@ func New{{ .Name }}Handler(uc *usecases.{{ .UseCase }}UseCase) *{{ .Name }}Handl
 }
 
 // Run executes the use case and displays the result
-func (h *{{ .Name }}Handler) Run() error {
+func (h *{{ .Name }}Handler) Run({{ if .Context }}ctx context.Context{{ end }}) error {
 	input := usecases.{{ .UseCase }}Input{}
 	
-	entity, err := h.UC.Execute(input)
+{{- if .List }}
+	list, err := h.UC.Execute({{ if .Context }}ctx, {{ end }}input)
+	if err != nil {
+		return fmt.Errorf("failed to execute use case: %w", err)
+	}
+
+	fmt.Printf("Have a good drink! 🥃\n")
+	fmt.Printf("Entities found: %d\n", len(list))
+	for _, entity := range list {
+		fmt.Printf("%+v\n", *entity)
+	}
+	return nil
+{{- else }}
+	entity, err := h.UC.Execute({{ if .Context }}ctx, {{ end }}input)
 	if err != nil {
 		return fmt.Errorf("failed to execute use case: %w", err)
 	}
 	
 	fmt.Printf("Have a good drink! 🥃\n")
-	fmt.Printf("Entity created: ID=%s, Name=%s\n", entity.ID, entity.Name)
+	fmt.Printf("Entity created: %+v\n", *entity)
 	return nil
+{{- end }}
 }
\ No newline at end of file
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+{{- if .Route.Body }}
+	"encoding/json"
+	"errors"
+{{- end }}
+{{- if or .Route.Body .IDParse }}
+	"fmt"
+{{- end }}
+{{- if .Route.Body }}
+	"io"
+{{- end }}
+	"net/http"
+{{- range .IDImports }}
+	"{{ . }}"
+{{- end }}
+{{- if .Router.Module }}
+
+	"{{ .Router.Import }}"
+{{- end }}
+{{ if or .Route.Body .IDParse }}
+	"{{ .Module }}/internal/domain"
+{{- end }}
+	"{{ .Module }}/internal/usecases"
+)
+{{ $request := or .Route.Body .Context .Filter }}
+// {{ .Name }}Handler serves the {{ .UseCase }} use case at {{ .Route }}
+type {{ .Name }}Handler struct {
+	UC *usecases.{{ .UseCase }}UseCase
+}
+
+func New{{ .Name }}Handler(uc *usecases.{{ .UseCase }}UseCase) *{{ .Name }}Handler {
+	return &{{ .Name }}Handler{UC: uc}
+}
+
+// Register adds the route of the handler to {{ .Router.Var }}
+func (h *{{ .Name }}Handler) Register({{ .Router.Var }} {{ .Router.Param }}) {
+	{{ .Router.Var }}.{{ .Router.Register .Route }}
+}
+
+// {{ .Router.Method }} executes the use case with the input of the request and writes
+// the result as JSON. Errors are mapped to their status by writeError.
+func (h *{{ .Name }}Handler) {{ .Router.Handler }} {
+{{- if .Router.Framework }}
+	w{{ if $request }}, r{{ end }} := {{ .Router.Writer }}{{ if $request }}, {{ .Router.Request }}{{ end }}
+{{- end }}
+	var input usecases.{{ .UseCase }}Input
+{{- if .Filter }}
+	filter, err := Parse{{ .Filter }}Filter(r.URL.Query())
+	if err != nil {
+		writeError(w, err)
+		{{ .Router.Return }}
+	}
+	input.Filter = filter
+{{- end }}
+{{- if .Route.Body }}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err))
+		{{ .Router.Return }}
+	}
+{{- end }}
+{{- if .Route.HasID }}
+{{- if not .ID }}
+	// TODO: set the input from {{ .Router.Value "id" }}
+{{- else if .IDParse }}
+	id, err := {{ .IDParse }}
+	if err != nil {
+		writeError(w, fmt.Errorf("%w: id: %v", domain.ErrInvalidInput, err))
+		{{ .Router.Return }}
+	}
+	input.{{ .ID.Name }} = {{ .ID.Convert "id" }}
+{{- else }}
+	input.{{ .ID.Name }} = {{ .Router.Value "id" }}
+{{- end }}
+{{- end }}
+{{ if eq .Route.Method "DELETE" }}
+	if _, err := h.UC.Execute({{ if .Context }}r.Context(), {{ end }}input); err != nil {
+		writeError(w, err)
+		{{ .Router.Return }}
+	}
+	w.WriteHeader(http.StatusNoContent)
+{{- else }}
+	{{ if .List }}list{{ else }}entity{{ end }}, err := h.UC.Execute({{ if .Context }}r.Context(), {{ end }}input)
+	if err != nil {
+		writeError(w, err)
+		{{ .Router.Return }}
+	}
+	writeJSON(w, {{ .Route.Status }}, {{ if .List }}list{{ else }}entity{{ end }})
+{{- end }}
+{{- if .Router.Returns }}
+	return nil
+{{- end }}
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"{{ .Module }}/internal/domain"
+)
+
+// writeJSON writes v as the JSON body of a response with the given status
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Failed to write response: %v", err)
+	}
+}
+
+// writeError writes err as a JSON error response. Domain errors get their
+// status, anything else is an internal error whose details are only logged.
+func writeError(w http.ResponseWriter, err error) {
+	status := statusOf(err)
+	message := err.Error()
+	if status == http.StatusInternalServerError {
+		log.Printf("Internal error: %v", err)
+		message = http.StatusText(status)
+	}
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+// statusOf returns the HTTP status of an error returned by a use case
+func statusOf(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput), errors.Is(err, domain.ErrInvalidFilter):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrAlreadyExists):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -1,5 +1,36 @@
 package mappers
+{{ if .Fields }}
+import (
+{{- range .Fields.Imports }}
+	"{{ . }}"
+{{- end }}
 
+	"{{ .Module }}/internal/domain/entities"
+)
+
+// {{ .Entity }}DTO is the transport representation of entities.{{ .Entity }}
+type {{ .Entity }}DTO struct {
+{{- range .Fields }}
+	{{ .Name }} {{ .Type }} `json:"{{ .JSONName }}"`
+{{- end }}
+}
+
+func Map{{ .Entity }}FromDTO(dto {{ .Entity }}DTO) (*entities.{{ .Entity }}, error) {
+	return &entities.{{ .Entity }}{
+{{- range .Fields }}
+		{{ .Name }}: dto.{{ .Name }},
+{{- end }}
+	}, nil
+}
+
+func Map{{ .Entity }}ToDTO(e *entities.{{ .Entity }}) {{ .Entity }}DTO {
+	return {{ .Entity }}DTO{
+{{- range .Fields }}
+		{{ .Name }}: e.{{ .Name }},
+{{- end }}
+	}
+}
+{{- else }}
 import "{{ .Module }}/internal/domain/entities"
 
 func Map{{ .Entity }}FromDTO(dto any) (*entities.{{ .Entity }}, error) {
@@ -10,4 +41,5 @@ func Map{{ .Entity }}FromDTO(dto any) (*entities.{{ .Entity }}, error) {
 func Map{{ .Entity }}ToDTO(e *entities.{{ .Entity }}) any {
     // TODO: implement mapper here
     return nil
-}
\ No newline at end of file
+}
+{{- end }}
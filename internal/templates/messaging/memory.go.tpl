@@ -0,0 +1,131 @@
+package inmemory
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"sync"
+
+	"{{ .Module }}/internal/messaging"
+)
+
+// Broker is an in-process messaging.Broker for local runs and tests. Every
+// handler subscribed to a topic gets the messages published on it, one at a
+// time and in order. Messages are lost when the process ends.
+type Broker struct {
+	mu      sync.Mutex
+	topics  map[string][]*subscription
+	running bool
+}
+
+func NewBroker() *Broker {
+	return &Broker{topics: make(map[string][]*subscription)}
+}
+
+// Publish queues msg for the handlers subscribed to its topic
+func (b *Broker) Publish(ctx context.Context, msg messaging.Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, sub := range b.topics[msg.Topic] {
+		sub.push(messaging.Message{
+			Topic:   msg.Topic,
+			Payload: slices.Clone(msg.Payload),
+			Headers: maps.Clone(msg.Headers),
+		})
+	}
+	return nil
+}
+
+// Subscribe registers handler for the messages published on topic from now
+// on. Handlers subscribe before Run.
+func (b *Broker) Subscribe(topic string, handler messaging.Handler) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.running {
+		return errors.New("subscribe before running the broker")
+	}
+	b.topics[topic] = append(b.topics[topic], &subscription{handler: handler, ready: make(chan struct{}, 1)})
+	return nil
+}
+
+// Run delivers the messages to the handlers until ctx is done. Handlers get
+// a context that is not cancelled with ctx, so the messages being handled
+// are finished, and the ones still queued are dropped.
+func (b *Broker) Run(ctx context.Context) error {
+	b.mu.Lock()
+	if b.running {
+		b.mu.Unlock()
+		return errors.New("broker already running")
+	}
+	b.running = true
+	var subs []*subscription
+	for _, topic := range b.topics {
+		subs = append(subs, topic...)
+	}
+	b.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, sub := range subs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sub.deliver(ctx)
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// subscription is the queue of the messages of a handler
+type subscription struct {
+	handler messaging.Handler
+	mu      sync.Mutex
+	queue   []messaging.Message
+	ready   chan struct{} // signaled when a message is queued
+}
+
+func (s *subscription) push(msg messaging.Message) {
+	s.mu.Lock()
+	s.queue = append(s.queue, msg)
+	s.mu.Unlock()
+	select {
+	case s.ready <- struct{}{}:
+	default:
+	}
+}
+
+// deliver hands the queued messages to the handler until ctx is done.
+// Errors are left to the handler, the message is dropped.
+func (s *subscription) deliver(ctx context.Context) {
+	for {
+		msg, ok := s.next(ctx)
+		if !ok {
+			return
+		}
+		_ = s.handler(context.WithoutCancel(ctx), msg)
+	}
+}
+
+// next waits for the next message, false once ctx is done
+func (s *subscription) next(ctx context.Context) (messaging.Message, bool) {
+	for ctx.Err() == nil {
+		s.mu.Lock()
+		if len(s.queue) > 0 {
+			msg := s.queue[0]
+			s.queue = s.queue[1:]
+			s.mu.Unlock()
+			return msg, true
+		}
+		s.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+		case <-s.ready:
+		}
+	}
+	return messaging.Message{}, false
+}
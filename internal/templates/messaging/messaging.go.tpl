@@ -0,0 +1,36 @@
+package messaging
+
+import "context"
+
+// Message is a payload published on a topic. Headers carry metadata, such
+// as why a message ended up on a dead-letter topic.
+type Message struct {
+	Topic   string
+	Payload []byte
+	Headers map[string]string
+}
+
+// Handler processes a message delivered by a Subscriber. An error means the
+// message was not processed.
+type Handler func(ctx context.Context, msg Message) error
+
+// Publisher publishes messages on their topic
+type Publisher interface {
+	Publish(ctx context.Context, msg Message) error
+}
+
+// Subscriber delivers the messages of the topics handlers subscribe to
+type Subscriber interface {
+	// Subscribe registers handler for the messages of topic, before Run
+	Subscribe(topic string, handler Handler) error
+	// Run delivers messages until ctx is done, then waits for the messages
+	// being handled
+	Run(ctx context.Context) error
+}
+
+// Broker publishes and delivers messages. In-memory, NATS or Kafka adapters
+// in infrastructure/messaging implement it.
+type Broker interface {
+	Publisher
+	Subscriber
+}
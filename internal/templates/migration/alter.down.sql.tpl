@@ -0,0 +1,4 @@
+-- Revert the {{ .Table.Name }} table to the previous {{ .Entity }} entities
+{{- range .Down }}
+{{ . }}
+{{- end }}
@@ -0,0 +1,4 @@
+-- Alter the {{ .Table.Name }} table to store {{ .Entity }} entities as they are now
+{{- range .Up }}
+{{ . }}
+{{- end }}
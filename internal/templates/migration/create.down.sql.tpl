@@ -0,0 +1,2 @@
+-- Drop the {{ .Table.Name }} table storing {{ .Entity }} entities
+{{ .Table.DropTable }}
@@ -0,0 +1,2 @@
+-- Create the {{ .Table.Name }} table storing {{ .Entity }} entities
+{{ .Table.CreateTable }}
@@ -0,0 +1,131 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+)
+
+// table records the versions of the applied migrations
+const table = "schema_migrations"
+
+// Up applies the up migrations of fsys that db has not applied yet, in
+// version order. Each migration runs in a transaction together with the
+// record of its version, although MySQL commits schema changes right away.
+func Up(ctx context.Context, db *sql.DB, fsys fs.FS) error {
+	applied, err := appliedVersions(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	files, err := fs.Glob(fsys, "*.up.sql")
+	if err != nil {
+		return err
+	}
+	slices.Sort(files)
+
+	for _, file := range files {
+		version, _, _ := strings.Cut(file, "_")
+		if slices.Contains(applied, version) {
+			continue
+		}
+		if err := run(ctx, db, fsys, file, `INSERT INTO `+table+` (version) VALUES ({{ .Dialect.Placeholder 1 }})`, version); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Down reverts the last migration db applied, running its down file
+func Down(ctx context.Context, db *sql.DB, fsys fs.FS) error {
+	applied, err := appliedVersions(ctx, db)
+	if err != nil || len(applied) == 0 {
+		return err
+	}
+	version := applied[len(applied)-1]
+
+	files, err := fs.Glob(fsys, version+"_*.down.sql")
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no down migration for version %s", version)
+	}
+	return run(ctx, db, fsys, files[0], `DELETE FROM `+table+` WHERE version = {{ .Dialect.Placeholder 1 }}`, version)
+}
+
+// appliedVersions creates the migrations table if needed and returns the
+// versions it records, in order
+func appliedVersions(ctx context.Context, db *sql.DB) ([]string, error) {
+	if _, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+table+` (version VARCHAR(255) NOT NULL PRIMARY KEY)`); err != nil {
+		return nil, fmt.Errorf("create %s: %w", table, err)
+	}
+
+	rows, err := db.QueryContext(ctx, `SELECT version FROM `+table+` ORDER BY version`)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	var versions []string
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			return nil, fmt.Errorf("read %s: %w", table, err)
+		}
+		versions = append(versions, version)
+	}
+	return versions, rows.Err()
+}
+
+// run executes the statements of file and then record, in a transaction
+func run(ctx context.Context, db *sql.DB, fsys fs.FS, file, record, version string) error {
+	content, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("migration %s: %w", file, err)
+	}
+	defer tx.Rollback()
+
+	for _, stmt := range statements(string(content)) {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("migration %s: %w", file, err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, record, version); err != nil {
+		return fmt.Errorf("migration %s: %w", file, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("migration %s: %w", file, err)
+	}
+	return nil
+}
+
+// statements splits a migration into its statements, which end with a ; at
+// the end of a line. Lines starting with -- are comments.
+func statements(content string) []string {
+	var stmts []string
+	var stmt strings.Builder
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		stmt.WriteString(line + "\n")
+		if strings.HasSuffix(trimmed, ";") {
+			stmts = append(stmts, strings.TrimSpace(stmt.String()))
+			stmt.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(stmt.String()); rest != "" {
+		stmts = append(stmts, rest)
+	}
+	return stmts
+}
@@ -0,0 +1,9 @@
+// Package migrations embeds the SQL migrations of the project in the binary.
+// Files are named <version>_<name>.up.sql and <version>_<name>.down.sql, and
+// the versions sort in the order the migrations are applied.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"{{ .Module }}/internal/messaging"
+)
+
+// consume delivers the messages of the topics the consumer handlers
+// subscribed to until the process gets SIGINT or SIGTERM, then waits for
+// the messages being handled
+func consume(subscriber messaging.Subscriber) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	log.Println("Consuming messages 🥃")
+	if err := subscriber.Run(ctx); err != nil {
+		return err
+	}
+	log.Println("Stopped consuming messages 🥃")
+	return nil
+}
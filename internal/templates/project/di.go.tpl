@@ -1,47 +1,41 @@
 package di
-
+{{ if .Driver.Setup }}
 import (
 	"database/sql"
+{{- range .Driver.Imports }}
+	"{{ . }}"
+{{- end }}
+{{- if .Driver.SQLDriver }}
 
-	"{{ .Module }}/infrastructure/database/mysql"
-	"{{ .Module }}/internal/handlers"
-	"{{ .Module }}/internal/usecases"
+	_ "{{ .Driver.SQLDriver }}"
+{{- end }}
 )
-
+{{ end }}
 // Container holds all dependencies
 type Container struct {
-	DB     *sql.DB
-	{{ .UseCase }}Handler *handlers.{{ .UseCase }}Handler
+{{- if .Driver.Setup }}
+	DB *sql.DB
+{{- end }}
 }
 
 // NewContainer initializes all dependencies and returns a Container
-// For demo purposes, database connection is optional (nil is acceptable)
 func NewContainer() (*Container, error) {
-	// Initialize database connection (optional for demo)
-	// In production, you would initialize a real database connection here
-	var db *sql.DB = nil
-
-	// Initialize repository with nil DB (for demo purposes)
-	// In production, you would pass a real database connection
-	{{ .Entity }}Repo := mysql.New{{ .Entity }}MySQLRepo(db)
-
-	// Initialize use case
-	{{ .UseCase }}UC := usecases.New{{ .UseCase }}UseCase({{ .Entity }}Repo)
-
-	// Initialize handler
-	{{ .UseCase }}Handler := handlers.New{{ .UseCase }}Handler({{ .UseCase }}UC)
-
+{{- if .Driver.Setup }}
+{{ .Driver.Setup }}
+{{ end }}
 	return &Container{
-		DB:     db,
-		{{ .UseCase }}Handler: {{ .UseCase }}Handler,
+{{- if .Driver.Setup }}
+		DB: {{ .Driver.Conn }},
+{{- end }}
 	}, nil
 }
 
 // Close closes all connections
 func (c *Container) Close() error {
+{{- if .Driver.Setup }}
 	if c.DB != nil {
 		return c.DB.Close()
 	}
+{{- end }}
 	return nil
 }
-
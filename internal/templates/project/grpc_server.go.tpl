@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// serveGRPC serves the services registered on server until the process gets
+// SIGINT or SIGTERM, then stops it gracefully, giving the calls in flight the
+// shutdown timeout to finish. The server is configured from the environment:
+//
+//	GRPC_ADDR              address to listen on, :9090 by default
+//	GRPC_SHUTDOWN_TIMEOUT  time to finish the calls in flight, 15s by default
+func serveGRPC(server *grpc.Server) error {
+	addr := os.Getenv("GRPC_ADDR")
+	if addr == "" {
+		addr = ":9090"
+	}
+	shutdownTimeout := 15 * time.Second
+	if value := os.Getenv("GRPC_SHUTDOWN_TIMEOUT"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid GRPC_SHUTDOWN_TIMEOUT: %w", err)
+		}
+		shutdownTimeout = d
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errs := make(chan error, 1)
+	go func() {
+		log.Printf("Serving gRPC on %s 🥃", listener.Addr())
+		errs <- server.Serve(listener)
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	// A second signal stops the process right away
+	stop()
+	log.Println("Stopping gRPC, finishing the calls in flight 🥃")
+
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(shutdownTimeout):
+		// Calls still running are cancelled
+		server.Stop()
+	}
+	return nil
+}
@@ -1,22 +1,41 @@
 package main
 
 import (
+{{- if or .UseCases .Serve }}
+{{- if and .UseCases .Context }}
+	"context"
+{{- end }}
 	"log"
 
 	"{{ .Module }}/cmd/{{ .ProjectName }}/di"
+{{- else }}
+	"fmt"
+{{- end }}
 )
 
 func main() {
+{{- if or .UseCases .Serve }}
 	// Initialize dependencies
 	container, err := di.NewContainer()
 	if err != nil {
 		log.Fatalf("Failed to initialize dependencies: %v", err)
 	}
 	defer container.Close()
+{{- if .UseCases }}
 
-	// Execute handler to demonstrate the full flow
+	// Execute the handlers to demonstrate the full flow
 	// This runs: Handler -> UseCase -> Repository
-	if err := container.{{ .UseCase }}Handler.Run(); err != nil {
+{{- if .Context }}
+	ctx := context.Background()
+{{- end }}
+{{- range .UseCases }}
+	if err := container.{{ . }}Handler.Run({{ if $.Context }}ctx{{ end }}); err != nil {
 		log.Fatalf("Failed to execute handler: %v", err)
 	}
+{{- end }}
+{{- end }}
+{{- else }}
+	// Nothing is wired yet, run `sazerac make all <Entity> <UseCase>`
+	fmt.Println("{{ .ProjectName }} is ready. Have a good drink! 🥃")
+{{- end }}
 }
@@ -0,0 +1,21 @@
+# Sazerac project configuration 🥃
+
+tags:
+  # Struct tags generated for entity fields: json, db, yaml, validate, bson
+  keys: [json, db]
+  # Tag naming strategy: snake_case or camelCase
+  naming: snake_case
+
+database:
+  # Driver repositories are generated for: memory, mysql, postgres or sqlite
+  driver: memory
+
+# Repositories, use cases and handlers take a context.Context as first
+# argument, passed down to the database queries
+context: true
+
+http:
+  # Router HTTP handlers are generated for: stdlib, chi, gin or echo. The
+  # first make handler --kind http sets it, stdlib unless --router says
+  # otherwise
+  router: ""
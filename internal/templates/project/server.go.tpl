@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// serve serves handler over HTTP until the process gets SIGINT or SIGTERM,
+// then shuts the server down, giving the requests in flight the shutdown
+// timeout to finish. The server is configured from the environment:
+//
+//	HTTP_ADDR              address to listen on, :8080 by default
+//	HTTP_READ_TIMEOUT      time to read a request, 10s by default
+//	HTTP_WRITE_TIMEOUT     time to write a response, 10s by default
+//	HTTP_SHUTDOWN_TIMEOUT  time to finish the requests in flight, 15s by default
+func serve(handler http.Handler) error {
+	addr := os.Getenv("HTTP_ADDR")
+	if addr == "" {
+		addr = ":8080"
+	}
+	readTimeout, err := durationEnv("HTTP_READ_TIMEOUT", 10*time.Second)
+	if err != nil {
+		return err
+	}
+	writeTimeout, err := durationEnv("HTTP_WRITE_TIMEOUT", 10*time.Second)
+	if err != nil {
+		return err
+	}
+	shutdownTimeout, err := durationEnv("HTTP_SHUTDOWN_TIMEOUT", 15*time.Second)
+	if err != nil {
+		return err
+	}
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadTimeout:       readTimeout,
+		ReadHeaderTimeout: readTimeout,
+		WriteTimeout:      writeTimeout,
+		IdleTimeout:       time.Minute,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errs := make(chan error, 1)
+	go func() {
+		log.Printf("Listening on %s 🥃", server.Addr)
+		errs <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	// A second signal stops the process right away
+	stop()
+	log.Println("Shutting down, finishing the requests in flight 🥃")
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		return fmt.Errorf("failed to shut down: %w", err)
+	}
+	return nil
+}
+
+// durationEnv reads the duration set in the environment variable key, such
+// as 30s, or returns fallback when it is not set
+func durationEnv(key string, fallback time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return d, nil
+}
@@ -0,0 +1,7 @@
+package domain
+
+import "errors"
+{{ range .Errors }}
+// {{ .Doc }}
+var {{ .Name }} = errors.New("{{ .Message }}")
+{{ end -}}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+{{- range .Fields.FilterImports }}
+	"{{ . }}"
+{{- end }}
+
+	"{{ .Module }}/internal/domain"
+)
+
+// {{ .Entity }}Filter selects, sorts and pages the entities returned by
+// {{ .Entity }}Repository.List. The zero value lists everything in ID order.
+type {{ .Entity }}Filter struct {
+{{- with .Fields.Filterable }}
+	// Only entities whose fields equal the values set
+{{- range . }}
+	{{ .Name }} *{{ .Type }}
+{{- end }}
+{{ end }}
+	// Sort is the field to sort by, see {{ .Entity }}SortFields, prefixed with -
+	// for descending order
+	Sort string
+
+	// Offset pagination: Limit caps the number of entities, 0 meaning no
+	// limit, after skipping the first Offset ones
+	Limit  int
+	Offset int
+{{- if .ID.Ordered }}
+
+	// Cursor pagination: only the entities after the one with this ID, in
+	// ID order
+	After *{{ .ID.Type }}
+{{- end }}
+}
+
+// {{ .Entity }}SortFields are the values {{ .Entity }}Filter.Sort accepts
+var {{ .Entity }}SortFields = []string{ {{- range $i, $f := .Fields.Sortable }}{{ if $i }}, {{ end }}"{{ $f.ParamName }}"{{ end -}} }
+
+// Order returns the field to sort by, {{ .ID.ParamName }} by default, and whether the
+// order is descending
+func (f {{ .Entity }}Filter) Order() (string, bool) {
+	field, desc := strings.CutPrefix(f.Sort, "-")
+	if field == "" {
+		field = "{{ .ID.ParamName }}"
+	}
+	return field, desc
+}
+
+// Validate reports filters List cannot run with domain.ErrInvalidFilter
+func (f {{ .Entity }}Filter) Validate() error {
+	field, _ := f.Order()
+	if !slices.Contains({{ .Entity }}SortFields, field) {
+		return fmt.Errorf("%w: unknown sort field %q", domain.ErrInvalidFilter, field)
+	}
+	if f.Limit < 0 || f.Offset < 0 {
+		return fmt.Errorf("%w: negative limit or offset", domain.ErrInvalidFilter)
+	}
+	if f.Offset > 0 && f.Limit == 0 {
+		return fmt.Errorf("%w: offset without limit", domain.ErrInvalidFilter)
+	}
+{{- if .ID.Ordered }}
+	if f.After != nil && field != "{{ .ID.ParamName }}" {
+		return fmt.Errorf("%w: a cursor needs the {{ .ID.ParamName }} sort order", domain.ErrInvalidFilter)
+	}
+{{- end }}
+	return nil
+}
@@ -0,0 +1,43 @@
+package {{ .Driver.Package }}
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// jsonColumn stores the value V points to in a JSON column, for the fields
+// database/sql cannot write or scan as they are, such as slices and maps.
+// It is the argument of queries writing them and the destination of scans.
+type jsonColumn struct {
+	V any
+}
+
+// Value encodes the value as JSON
+func (c jsonColumn) Value() (driver.Value, error) {
+	b, err := json.Marshal(c.V)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan decodes the JSON of the column into the value, leaving it empty for
+// NULL and for the empty text migrations fill SQLite columns with
+func (c jsonColumn) Scan(src any) error {
+	var b []byte
+	switch src := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		b = src
+	case string:
+		b = []byte(src)
+	default:
+		return fmt.Errorf("cannot scan %T into a JSON column", src)
+	}
+	if len(b) == 0 {
+		return nil
+	}
+	return json.Unmarshal(b, c.V)
+}
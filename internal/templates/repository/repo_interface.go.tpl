@@ -1,8 +1,33 @@
 package repository
+{{- $ctx := "" }}
+{{- if .Context }}{{ $ctx = "ctx context.Context, " }}{{ end }}
 
-import "{{ .Module }}/internal/domain/entities"
+import (
+{{- if .Context }}
+	"context"
+{{- end }}
+{{- range .ID.Imports }}
+	"{{ . }}"
+{{- end }}
 
+	"{{ .Module }}/internal/domain/entities"
+)
+{{ if .CRUD }}
+// {{ .Entity }}Repository persists {{ .Entity }} entities. Missing entities are
+// reported with domain.ErrNotFound, Create returns domain.ErrAlreadyExists
+// when the ID is taken and List domain.ErrInvalidFilter for filters failing
+// {{ .Entity }}Filter.Validate. Count only applies the conditions of filter.
+{{- end }}
 type {{ .Entity }}Repository interface {
-    Save(e *entities.{{ .Entity }}) error
-    FindByID(id string) (*entities.{{ .Entity }}, error)
-}
\ No newline at end of file
+    Save({{ $ctx }}e *entities.{{ .Entity }}) error
+{{- if .CRUD }}
+    Create({{ $ctx }}e *entities.{{ .Entity }}) error
+    Update({{ $ctx }}e *entities.{{ .Entity }}) error
+    Delete({{ $ctx }}id {{ .ID.Type }}) error
+{{- end }}
+    FindByID({{ $ctx }}id {{ .ID.Type }}) (*entities.{{ .Entity }}, error)
+{{- if .CRUD }}
+    List({{ $ctx }}filter {{ .Entity }}Filter) ([]*entities.{{ .Entity }}, error)
+    Count({{ $ctx }}filter {{ .Entity }}Filter) (int, error)
+{{- end }}
+}
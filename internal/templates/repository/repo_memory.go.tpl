@@ -0,0 +1,272 @@
+package memory
+
+import (
+{{- if .Context }}
+	"context"
+{{- end }}
+{{- if .Fields.Clones "maps" }}
+	"maps"
+{{- end }}
+{{- if or (and .Context .CRUD) (.Fields.Clones "slices") }}
+	"slices"
+{{- end }}
+{{- if .CRUD }}
+	"sort"
+{{- end }}
+	"sync"
+{{- range .ID.Imports }}
+	"{{ . }}"
+{{- end }}
+
+	"{{ .Module }}/internal/domain"
+	"{{ .Module }}/internal/domain/entities"
+	"{{ .Module }}/internal/repository"
+)
+
+{{- $repo := .Driver.RepoType .Entity }}
+{{- $ctx := "" }}
+{{- if .Context }}{{ $ctx = "ctx context.Context, " }}{{ end }}
+{{- $id := .ID.Name }}
+{{- $copy := "*e" }}
+{{- if .Fields.Shared }}{{ $copy = "r.clone(*e)" }}{{ end }}
+
+// {{ $repo }} keeps {{ .Entity }} entities in a map. It is safe for
+// concurrent use and works with copies, so callers never share an entity with
+// it. Besides running a project without a database, it is a ready made fake
+// for use case tests.
+{{- if .Context }}
+// Writes made in a UnitOfWork transaction that fails are undone.
+{{- end }}
+type {{ $repo }} struct {
+	mu    sync.RWMutex
+	items map[{{ .ID.Type }}]entities.{{ .Entity }}
+{{- if .CRUD }}
+	order []{{ .ID.Type }} // IDs in insertion order, for List
+{{- end }}
+}
+
+func New{{ $repo }}() repository.{{ .Entity }}Repository {
+	return &{{ $repo }}{items: map[{{ .ID.Type }}]entities.{{ .Entity }}{}}
+}
+
+// Save stores a copy of the entity, replacing the one with the same ID
+func (r *{{ $repo }}) Save({{ $ctx }}e *entities.{{ .Entity }}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+{{ if .Context }}
+	prev, ok := r.items[e.{{ $id }}]
+	record(ctx, r.restore(e.{{ $id }}, prev, ok))
+{{- if .CRUD }}
+	if !ok {
+		r.order = append(r.order, e.{{ $id }})
+	}
+{{- end }}
+{{- else if .CRUD }}
+	if _, ok := r.items[e.{{ $id }}]; !ok {
+		r.order = append(r.order, e.{{ $id }})
+	}
+{{- end }}
+	r.items[e.{{ $id }}] = {{ $copy }}
+	return nil
+}
+{{- if .CRUD }}
+
+// Create stores a copy of a new entity, or returns domain.ErrAlreadyExists
+func (r *{{ $repo }}) Create({{ $ctx }}e *entities.{{ .Entity }}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.items[e.{{ $id }}]; ok {
+		return domain.ErrAlreadyExists
+	}
+{{- if .Context }}
+	record(ctx, r.restore(e.{{ $id }}, entities.{{ .Entity }}{}, false))
+{{- end }}
+	r.items[e.{{ $id }}] = {{ $copy }}
+	r.order = append(r.order, e.{{ $id }})
+	return nil
+}
+
+// Update replaces the stored entity with a copy of e, or returns
+// domain.ErrNotFound
+func (r *{{ $repo }}) Update({{ $ctx }}e *entities.{{ .Entity }}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+{{- if .Context }}
+	prev, ok := r.items[e.{{ $id }}]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	record(ctx, r.restore(e.{{ $id }}, prev, true))
+{{- else }}
+	if _, ok := r.items[e.{{ $id }}]; !ok {
+		return domain.ErrNotFound
+	}
+{{- end }}
+	r.items[e.{{ $id }}] = {{ $copy }}
+	return nil
+}
+
+// Delete removes the entity with the given ID, or returns domain.ErrNotFound
+func (r *{{ $repo }}) Delete({{ $ctx }}id {{ .ID.Type }}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+{{- if .Context }}
+	prev, ok := r.items[id]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	record(ctx, r.restore(id, prev, true))
+{{- else }}
+	if _, ok := r.items[id]; !ok {
+		return domain.ErrNotFound
+	}
+{{- end }}
+	delete(r.items, id)
+	for i, key := range r.order {
+		if key == id {
+			r.order = append(r.order[:i], r.order[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+{{- end }}
+
+{{- if .Context }}
+
+// restore returns a function putting back what id held before a write, which
+// undoes the write when its transaction rolls back
+func (r *{{ $repo }}) restore(id {{ .ID.Type }}, prev entities.{{ .Entity }}, existed bool) func() {
+{{- if .CRUD }}
+	index := slices.Index(r.order, id)
+{{- end }}
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		if existed {
+			r.items[id] = prev
+		} else {
+			delete(r.items, id)
+		}
+{{- if .CRUD }}
+		r.order = slices.DeleteFunc(r.order, func(key {{ .ID.Type }}) bool { return key == id })
+		if existed {
+			r.order = slices.Insert(r.order, min(index, len(r.order)), id)
+		}
+{{- end }}
+	}
+}
+{{- end }}
+
+// FindByID returns a copy of the entity with the given ID, or domain.ErrNotFound
+func (r *{{ $repo }}) FindByID({{ $ctx }}id {{ .ID.Type }}) (*entities.{{ .Entity }}, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	e, ok := r.items[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+{{- if .Fields.Shared }}
+	e = r.clone(e)
+{{- end }}
+	return &e, nil
+}
+{{- if .Fields.Shared }}
+
+// clone returns a copy of e sharing no slice, map or pointer with it
+func (r *{{ $repo }}) clone(e entities.{{ .Entity }}) entities.{{ .Entity }} {
+{{- range .Fields.Shared }}
+	{{ .Clone "e" }}
+{{- end }}
+	return e
+}
+{{- end }}
+{{- if .CRUD }}
+
+// List returns copies of the entities matching filter
+func (r *{{ $repo }}) List({{ $ctx }}filter repository.{{ .Entity }}Filter) ([]*entities.{{ .Entity }}, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	field, desc := filter.Order()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	list := []*entities.{{ .Entity }}{}
+	for _, id := range r.order {
+		e := r.items[id]
+		if !r.matches(&e, filter) {
+			continue
+		}
+{{- if .ID.Ordered }}
+		if filter.After != nil && (desc && {{ .ID.Less "*filter.After" "e.ID" }} || !desc && {{ .ID.Less "e.ID" "*filter.After" }} || e.ID == *filter.After) {
+			continue
+		}
+{{- end }}
+{{- if .Fields.Shared }}
+		e = r.clone(e)
+{{- end }}
+		list = append(list, &e)
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if desc {
+			a, b = b, a
+		}
+		switch field {
+{{- range .Fields.Filterable }}
+		case "{{ .ParamName }}":
+			if !({{ .Equal (print "a." .Name) (print "b." .Name) }}) {
+				return {{ .Less (print "a." .Name) (print "b." .Name) }}
+			}
+{{- end }}
+		}
+{{- if .ID.Ordered }}
+		return {{ .ID.Less "a.ID" "b.ID" }}
+{{- else }}
+		// IDs do not sort, ties keep the insertion order
+		return false
+{{- end }}
+	})
+
+	if filter.Offset >= len(list) {
+		return []*entities.{{ .Entity }}{}, nil
+	}
+	list = list[filter.Offset:]
+	if filter.Limit > 0 && filter.Limit < len(list) {
+		list = list[:filter.Limit]
+	}
+	return list, nil
+}
+
+// Count returns the number of entities matching the conditions of filter
+func (r *{{ $repo }}) Count({{ $ctx }}filter repository.{{ .Entity }}Filter) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	n := 0
+	for _, e := range r.items {
+		if r.matches(&e, filter) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// matches reports whether e has the field values filter asks for
+func (r *{{ $repo }}) matches(e *entities.{{ .Entity }}, filter repository.{{ .Entity }}Filter) bool {
+{{- range .Fields.Filterable }}
+	if filter.{{ .Name }} != nil && !({{ .Equal (print "e." .Name) (print "*filter." .Name) }}) {
+		return false
+	}
+{{- end }}
+	return true
+}
+{{- end }}
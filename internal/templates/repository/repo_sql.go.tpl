@@ -0,0 +1,357 @@
+package {{ .Driver.Package }}
+
+import (
+{{- if .Context }}
+	"context"
+{{- end }}
+	"database/sql"
+{{- if .Table }}
+	"errors"
+	"fmt"
+{{- end }}
+{{- if and .Table .CRUD }}
+	"strings"
+{{- end }}
+{{- range .ID.Imports }}
+	"{{ . }}"
+{{- end }}
+
+{{- if .Table }}
+
+	"{{ .Module }}/internal/domain"
+{{- end }}
+	"{{ .Module }}/internal/domain/entities"
+	"{{ .Module }}/internal/repository"
+)
+
+{{- $repo := .Driver.RepoType .Entity }}
+{{- $ctx := "" }}
+{{- $call := "(" }}
+{{- $db := "r.DB." }}
+{{- if .Context }}
+{{- $ctx = "ctx context.Context, " }}
+{{- $call = "Context(ctx, " }}
+{{- /* queries run in the transaction of ctx, if any */}}
+{{- $db = "conn(ctx, r.DB)." }}
+{{- end }}
+
+// {{ $repo }} stores {{ .Entity }} entities in {{ if .Table }}the {{ .Table.Name }} table{{ else }}{{ .Driver.Name }}{{ end }}
+type {{ $repo }} struct {
+	DB *sql.DB
+}
+
+func New{{ $repo }}(db *sql.DB) repository.{{ .Entity }}Repository {
+	return &{{ $repo }}{DB: db}
+}
+{{ if .Table }}
+{{- $key := .Table.Key }}
+// Save inserts the entity, or updates it when its ID already exists
+func (r *{{ $repo }}) Save({{ $ctx }}e *entities.{{ .Entity }}) error {
+{{- if .Table.Generated }}
+	// A zero ID is assigned by the database
+	if e.{{ $key.Name }} == 0 {
+		const query = {{ .Table.Dialect.Literal .Table.InsertGenerated }}
+
+		if err := {{ $db }}QueryRow{{ $call }}query{{ with .Table.ValueArgs "e" }}, {{ . }}{{ end }}).Scan(&e.{{ $key.Name }}); err != nil {
+			return fmt.Errorf("save {{ .Entity }}: %w", err)
+		}
+		return nil
+	}
+
+{{ end }}	const query = {{ .Table.Dialect.Literal .Table.Upsert }}
+{{- if .Table.Dialect.Returning }}
+
+	if err := {{ $db }}QueryRow{{ $call }}query, {{ .Table.Args "e" }}).Scan(&e.{{ $key.Name }}); err != nil {
+		return fmt.Errorf("save {{ .Entity }}: %w", err)
+	}
+	return nil
+{{- else }}
+
+	{{ if $key.Integer }}res{{ else }}_{{ end }}, err := {{ $db }}Exec{{ $call }}query, {{ .Table.Args "e" }})
+	if err != nil {
+		return fmt.Errorf("save {{ .Entity }}: %w", err)
+	}
+{{- if $key.Integer }}
+
+	// A zero ID is assigned by the database
+	if e.{{ $key.Name }} == 0 {
+		id, err := res.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("save {{ .Entity }}: %w", err)
+		}
+		e.{{ $key.Name }} = {{ if eq $key.Type "int64" }}id{{ else }}{{ $key.Type }}(id){{ end }}
+	}
+{{- end }}
+	return nil
+{{- end }}
+}
+
+{{- if .CRUD }}
+
+// Create inserts a new entity, or returns domain.ErrAlreadyExists when its ID
+// is taken
+func (r *{{ $repo }}) Create({{ $ctx }}e *entities.{{ .Entity }}) error {
+{{- if .Table.Generated }}
+	// A zero ID is assigned by the database
+	if e.{{ $key.Name }} == 0 {
+		const query = {{ .Table.Dialect.Literal .Table.InsertGenerated }}
+
+		if err := {{ $db }}QueryRow{{ $call }}query{{ with .Table.ValueArgs "e" }}, {{ . }}{{ end }}).Scan(&e.{{ $key.Name }}); err != nil {
+			return fmt.Errorf("create {{ .Entity }}: %w", err)
+		}
+		return nil
+	}
+
+{{ end }}	const query = {{ .Table.Dialect.Literal .Table.Insert }}
+{{- if .Table.Dialect.Returning }}
+
+	err := {{ $db }}QueryRow{{ $call }}query, {{ .Table.Args "e" }}).Scan(&e.{{ $key.Name }})
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ErrAlreadyExists
+	}
+	if err != nil {
+		return fmt.Errorf("create {{ .Entity }}: %w", err)
+	}
+	return nil
+{{- else }}
+
+	res, err := {{ $db }}Exec{{ $call }}query, {{ .Table.Args "e" }})
+	if err != nil {
+		return fmt.Errorf("create {{ .Entity }}: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("create {{ .Entity }}: %w", err)
+	}
+	if n == 0 {
+		return domain.ErrAlreadyExists
+	}
+{{- if $key.Integer }}
+
+	// A zero ID is assigned by the database
+	if e.{{ $key.Name }} == 0 {
+		id, err := res.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("create {{ .Entity }}: %w", err)
+		}
+		e.{{ $key.Name }} = {{ if eq $key.Type "int64" }}id{{ else }}{{ $key.Type }}(id){{ end }}
+	}
+{{- end }}
+	return nil
+{{- end }}
+}
+
+// Update writes every field of the entity, or returns domain.ErrNotFound
+func (r *{{ $repo }}) Update({{ $ctx }}e *entities.{{ .Entity }}) error {
+	const query = {{ .Table.Dialect.Literal .Table.Update }}
+
+	res, err := {{ $db }}Exec{{ $call }}query, {{ .Table.UpdateArgs "e" }})
+	if err != nil {
+		return fmt.Errorf("update {{ .Entity }}: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("update {{ .Entity }}: %w", err)
+	}
+{{- if .Table.Dialect.FoundRows }}
+	if n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+{{- else }}
+	if n > 0 {
+		return nil
+	}
+
+	// Rows whose values did not change are not counted, check the ID exists
+	var found int
+	err = {{ $db }}QueryRow{{ $call }}{{ .Table.Dialect.Literal .Table.Exists }}, e.{{ $key.Name }}).Scan(&found)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("update {{ .Entity }}: %w", err)
+	}
+	return nil
+{{- end }}
+}
+
+// Delete deletes the entity with the given ID, or returns domain.ErrNotFound
+func (r *{{ $repo }}) Delete({{ $ctx }}id {{ .ID.Type }}) error {
+	const query = {{ .Table.Dialect.Literal .Table.Delete }}
+
+	res, err := {{ $db }}Exec{{ $call }}query, id)
+	if err != nil {
+		return fmt.Errorf("delete {{ .Entity }} %v: %w", id, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("delete {{ .Entity }} %v: %w", id, err)
+	}
+	if n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+{{- end }}
+
+// FindByID returns the entity with the given ID, or domain.ErrNotFound
+func (r *{{ $repo }}) FindByID({{ $ctx }}id {{ .ID.Type }}) (*entities.{{ .Entity }}, error) {
+	const query = {{ .Table.Dialect.Literal .Table.SelectByID }}
+
+	e := &entities.{{ .Entity }}{}
+	err := {{ $db }}QueryRow{{ $call }}query, id).Scan({{ .Table.Dests "e" }})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("find {{ .Entity }} %v: %w", id, err)
+	}
+
+	return e, nil
+}
+{{- if .CRUD }}
+
+// List returns the entities matching filter
+func (r *{{ $repo }}) List({{ $ctx }}filter repository.{{ .Entity }}Filter) ([]*entities.{{ .Entity }}, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	field, desc := filter.Order()
+
+	conds, args := r.conditions(filter)
+{{- if .ID.Ordered }}
+	if filter.After != nil {
+		args = append(args, *filter.After)
+		if desc {
+			conds = append(conds, {{ .Table.Dialect.Bind (print (.Table.Quote .Table.Key.Column) " < ") }})
+		} else {
+			conds = append(conds, {{ .Table.Dialect.Bind (print (.Table.Quote .Table.Key.Column) " > ") }})
+		}
+	}
+{{- end }}
+
+	query := {{ .Table.Dialect.Literal .Table.Select }}
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+
+	// Only known columns make it into ORDER BY, ties are broken by ID
+	const key = {{ .Table.ColumnLiteral .Table.Key.Column }}
+	column := key
+	switch field {
+{{- range .Fields.Filterable }}
+	case "{{ .ParamName }}":
+		column = {{ $.Table.ColumnLiteral .Column }}
+{{- end }}
+	}
+	direction := " ASC"
+	if desc {
+		direction = " DESC"
+	}
+	query += " ORDER BY " + column + direction
+	if column != key {
+		query += ", " + key + direction
+	}
+
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += {{ .Table.Dialect.Bind " LIMIT " }}
+	}
+	if filter.Offset > 0 {
+		args = append(args, filter.Offset)
+		query += {{ .Table.Dialect.Bind " OFFSET " }}
+	}
+
+	rows, err := {{ $db }}Query{{ $call }}query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list {{ .Entity }}: %w", err)
+	}
+	defer rows.Close()
+
+	list := []*entities.{{ .Entity }}{}
+	for rows.Next() {
+		e := &entities.{{ .Entity }}{}
+		if err := rows.Scan({{ .Table.Dests "e" }}); err != nil {
+			return nil, fmt.Errorf("list {{ .Entity }}: %w", err)
+		}
+		list = append(list, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list {{ .Entity }}: %w", err)
+	}
+
+	return list, nil
+}
+
+// Count returns the number of entities matching the conditions of filter
+func (r *{{ $repo }}) Count({{ $ctx }}filter repository.{{ .Entity }}Filter) (int, error) {
+	conds, args := r.conditions(filter)
+
+	query := {{ .Table.Dialect.Literal .Table.Count }}
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+
+	var n int
+	if err := {{ $db }}QueryRow{{ $call }}query, args...).Scan(&n); err != nil {
+		return 0, fmt.Errorf("count {{ .Entity }}: %w", err)
+	}
+	return n, nil
+}
+
+// conditions returns the WHERE conditions for the field values filter asks
+// for, and their arguments
+func (r *{{ $repo }}) conditions(filter repository.{{ .Entity }}Filter) ([]string, []any) {
+	var conds []string
+	var args []any
+{{- range .Fields.Filterable }}
+	if filter.{{ .Name }} != nil {
+		args = append(args, *filter.{{ .Name }})
+		conds = append(conds, {{ $.Table.Dialect.Bind (print ($.Table.Quote .Column) " = ") }})
+	}
+{{- end }}
+	return conds, args
+}
+{{- end }}
+{{- else }}
+// TODO: the fields of {{ .Entity }} were unknown, generate the entity first or
+// pass them as field:type arguments to get the queries written
+
+func (r *{{ $repo }}) Save({{ $ctx }}e *entities.{{ .Entity }}) error {
+	// TODO: implement
+	return nil
+}
+
+func (r *{{ $repo }}) FindByID({{ $ctx }}id {{ .ID.Type }}) (*entities.{{ .Entity }}, error) {
+	// TODO: implement
+	return nil, nil
+}
+{{- if .CRUD }}
+
+func (r *{{ $repo }}) Create({{ $ctx }}e *entities.{{ .Entity }}) error {
+	// TODO: implement, return domain.ErrAlreadyExists when the ID is taken
+	return nil
+}
+
+func (r *{{ $repo }}) Update({{ $ctx }}e *entities.{{ .Entity }}) error {
+	// TODO: implement, return domain.ErrNotFound when the ID is unknown
+	return nil
+}
+
+func (r *{{ $repo }}) Delete({{ $ctx }}id {{ .ID.Type }}) error {
+	// TODO: implement, return domain.ErrNotFound when the ID is unknown
+	return nil
+}
+
+func (r *{{ $repo }}) List({{ $ctx }}filter repository.{{ .Entity }}Filter) ([]*entities.{{ .Entity }}, error) {
+	// TODO: implement, return domain.ErrInvalidFilter when filter.Validate fails
+	return nil, nil
+}
+
+func (r *{{ $repo }}) Count({{ $ctx }}filter repository.{{ .Entity }}Filter) (int, error) {
+	// TODO: implement
+	return 0, nil
+}
+{{- end }}
+{{- end }}
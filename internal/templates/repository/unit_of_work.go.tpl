@@ -0,0 +1,11 @@
+package repository
+
+import "context"
+
+// UnitOfWork runs use case code in a transaction. The repositories called
+// with the context fn receives take part in it: everything they write is
+// committed when fn returns nil, and rolled back when fn returns an error or
+// panics. A Do nested in fn joins the transaction of the outer one.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
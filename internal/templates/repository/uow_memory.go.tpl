@@ -0,0 +1,70 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"{{ .Module }}/internal/repository"
+)
+
+// UnitOfWork runs functions in transactions over the memory repositories:
+// when a function fails, the writes it made through them are undone. Unlike
+// database transactions, other goroutines see the writes before Do returns.
+type UnitOfWork struct{}
+
+func NewUnitOfWork() repository.UnitOfWork {
+	return &UnitOfWork{}
+}
+
+// txKey is the context key of the undo log of a transaction
+type txKey struct{}
+
+// undoLog holds the functions undoing the writes of a transaction, in the
+// order they were made
+type undoLog struct {
+	mu   sync.Mutex
+	undo []func()
+}
+
+// Do runs fn, undoing its writes when it fails or panics
+func (u *UnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*undoLog); ok {
+		// Already in a transaction, fn joins it
+		return fn(ctx)
+	}
+
+	log := &undoLog{}
+	defer func() {
+		if p := recover(); p != nil {
+			log.rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey{}, log)); err != nil {
+		log.rollback()
+		return err
+	}
+	return nil
+}
+
+// rollback undoes the writes, last first
+func (l *undoLog) rollback() {
+	l.mu.Lock()
+	undo := l.undo
+	l.undo = nil
+	l.mu.Unlock()
+
+	for i := len(undo) - 1; i >= 0; i-- {
+		undo[i]()
+	}
+}
+
+// record adds undo to the transaction ctx runs in, if any
+func record(ctx context.Context, undo func()) {
+	if l, ok := ctx.Value(txKey{}).(*undoLog); ok {
+		l.mu.Lock()
+		l.undo = append(l.undo, undo)
+		l.mu.Unlock()
+	}
+}
@@ -0,0 +1,70 @@
+package {{ .Driver.Package }}
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"{{ .Module }}/internal/repository"
+)
+
+// UnitOfWork runs functions in {{ .Driver.Type }} transactions, which the
+// repositories of this package join through the context
+type UnitOfWork struct {
+	DB *sql.DB
+}
+
+func NewUnitOfWork(db *sql.DB) repository.UnitOfWork {
+	return &UnitOfWork{DB: db}
+}
+
+// txKey is the context key of the transaction repositories run in
+type txKey struct{}
+
+// Do begins a transaction, commits it when fn succeeds and rolls it back
+// when fn fails or panics
+func (u *UnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		// Already in a transaction, fn joins it
+		return fn(ctx)
+	}
+
+	tx, err := u.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return errors.Join(err, fmt.Errorf("rollback transaction: %w", rbErr))
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+// querier runs queries on a *sql.DB or a *sql.Tx
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// conn returns the transaction ctx runs in, or db outside of transactions
+func conn(ctx context.Context, db *sql.DB) querier {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return db
+}
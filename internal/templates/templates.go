@@ -2,5 +2,5 @@ package templates
 
 import "embed"
 
-//go:embed project/* entity/* usecase/* repository/* handler/* validator/* mapper/*
+//go:embed project/* entity/* usecase/* repository/* handler/* validator/* mapper/* migration/* messaging/*
 var FS embed.FS
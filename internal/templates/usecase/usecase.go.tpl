@@ -1,9 +1,31 @@
 package usecases
+{{- $ctx := "" }}
+{{- if .Context }}{{ $ctx = "ctx, " }}{{ end }}
+{{- $op := .Operation }}
+{{- $todo := and (or (eq $op "Delete") (eq $op "List")) (not .CRUD) }}
+{{- $result := print "*entities." .Entity }}
+{{- if eq $op "List" }}{{ $result = print "[]*entities." .Entity }}{{ end }}
 
 import (
+{{- if .Context }}
+	"context"
+{{- end }}
+{{- if $todo }}
+	"errors"
+{{- else }}
 	"fmt"
+{{- end }}
+{{- if not $op }}
+{{- if .Fields.HasString "Name" }}
 	"math/rand"
+{{- end }}
+{{- if .Fields.HasString "ID" }}
 	"time"
+{{- end }}
+{{- end }}
+{{- range .Input.Imports }}
+	"{{ . }}"
+{{- end }}
 
 	"{{ .Module }}/internal/domain/entities"
 	"{{ .Module }}/internal/repository"
@@ -11,33 +33,122 @@ import (
 
 type {{ .Name }}UseCase struct {
     Repo repository.{{ .Entity }}Repository
+{{- if .Tx }}
+    UoW  repository.UnitOfWork
+{{- end }}
 }
 
+{{ if .Tx -}}
+func New{{ .Name }}UseCase(repo repository.{{ .Entity }}Repository, uow repository.UnitOfWork) *{{ .Name }}UseCase {
+    return &{{ .Name }}UseCase{Repo: repo, UoW: uow}
+}
+{{- else -}}
 func New{{ .Name }}UseCase(repo repository.{{ .Entity }}Repository) *{{ .Name }}UseCase {
     return &{{ .Name }}UseCase{Repo: repo}
 }
+{{- end }}
+{{ if .Tx }}
+// Execute runs the use case in a transaction: every repository call made
+// with its ctx commits or rolls back together, an error undoes them all
+func (uc *{{ .Name }}UseCase) Execute(ctx context.Context, input {{ .Name }}Input) ({{ $result }}, error) {
+    var result {{ $result }}
+    err := uc.UoW.Do(ctx, func(ctx context.Context) error {
+        var err error
+        result, err = uc.execute(ctx, input)
+        return err
+    })
+    if err != nil {
+        return nil, err
+    }
+    return result, nil
+}
 
-func (uc *{{ .Name }}UseCase) Execute(input {{ .Name }}Input) (*entities.{{ .Entity }}, error) {
+// execute is the body of Execute, run in its transaction
+func (uc *{{ .Name }}UseCase) execute(ctx context.Context, input {{ .Name }}Input) ({{ $result }}, error) {
+{{- else }}
+func (uc *{{ .Name }}UseCase) Execute({{ if .Context }}ctx context.Context, {{ end }}input {{ .Name }}Input) ({{ $result }}, error) {
+{{- end }}
+{{- if eq $op "Create" }}
+    entity := &entities.{{ .Entity }}{
+{{- range .Input }}
+        {{ .Name }}: input.{{ .Name }},
+{{- end }}
+    }
+    if err := uc.Repo.{{ if .CRUD }}Create{{ else }}Save{{ end }}({{ $ctx }}entity); err != nil {
+        return nil, fmt.Errorf("failed to create entity: %w", err)
+    }
+    return entity, nil
+{{- else if eq $op "Get" }}
+    entity, err := uc.Repo.FindByID({{ $ctx }}input.{{ .ID.Name }})
+    if err != nil {
+        return nil, fmt.Errorf("failed to find entity: %w", err)
+    }
+    return entity, nil
+{{- else if eq $op "Update" }}
+{{- if not .CRUD }}
+    // Save also stores missing entities, so they are looked up first
+    if _, err := uc.Repo.FindByID({{ $ctx }}input.{{ .ID.Name }}); err != nil {
+        return nil, fmt.Errorf("failed to find entity: %w", err)
+    }
+{{- end }}
+    entity := &entities.{{ .Entity }}{
+{{- range .Input }}
+        {{ .Name }}: input.{{ .Name }},
+{{- end }}
+    }
+    if err := uc.Repo.{{ if .CRUD }}Update{{ else }}Save{{ end }}({{ $ctx }}entity); err != nil {
+        return nil, fmt.Errorf("failed to update entity: %w", err)
+    }
+    return entity, nil
+{{- else if $todo }}
+    // TODO: the repository has no {{ $op }}, generate it with make repo --crud
+    return nil, errors.New("{{ .Name }} is not implemented")
+{{- else if eq $op "List" }}
+    list, err := uc.Repo.List({{ $ctx }}input.Filter)
+    if err != nil {
+        return nil, fmt.Errorf("failed to list entities: %w", err)
+    }
+    return list, nil
+{{- else if eq $op "Delete" }}
+    entity, err := uc.Repo.FindByID({{ $ctx }}input.{{ .ID.Name }})
+    if err != nil {
+        return nil, fmt.Errorf("failed to find entity: %w", err)
+    }
+    if err := uc.Repo.Delete({{ $ctx }}input.{{ .ID.Name }}); err != nil {
+        return nil, fmt.Errorf("failed to delete entity: %w", err)
+    }
+    return entity, nil
+{{- else }}
     // TODO: business logic here
-    
+{{ if .Fields.HasString "Name" }}
     // Generate random name for demo
-    rand.Seed(time.Now().UnixNano())
     names := []string{"Alice", "Bob", "Charlie", "Diana", "Eve", "Frank", "Grace", "Henry"}
     randomName := names[rand.Intn(len(names))]
-    
+{{ end }}
     entity := &entities.{{ .Entity }}{
+{{- if .Fields.HasString "ID" }}
         ID:   fmt.Sprintf("%d", time.Now().Unix()),
+{{- end }}
+{{- if .Fields.HasString "Name" }}
         Name: randomName,
+{{- end }}
     }
-    
+
     // Save entity using repository
-    if err := uc.Repo.Save(entity); err != nil {
+    if err := uc.Repo.Save({{ $ctx }}entity); err != nil {
         return nil, fmt.Errorf("failed to save entity: %w", err)
     }
-    
     return entity, nil
+{{- end }}
 }
 
 type {{ .Name }}Input struct {
+{{- range .Input }}
+    {{ .Name }} {{ .Type }} `json:"{{ .JSONName }}"`
+{{- else }}
     // TODO: add definition
-}
\ No newline at end of file
+{{- end }}
+{{- if .Filter }}
+    Filter repository.{{ .Entity }}Filter
+{{- end }}
+}
@@ -1,8 +1,37 @@
 package validators
+{{ if .Fields }}
+import (
+	"errors"
+	"fmt"
 
+	"{{ .Module }}/internal/domain"
+	"{{ .Module }}/internal/domain/entities"
+)
+
+// Validate{{ .Entity }} checks that every required field of the {{ .Entity }} is set.
+// The errors it returns wrap domain.ErrInvalidInput.
+func Validate{{ .Entity }}(e *entities.{{ .Entity }}) error {
+	if e == nil {
+		return fmt.Errorf("%w: {{ .Entity }} is required", domain.ErrInvalidInput)
+	}
+
+	var errs []error
+{{- range .Fields }}{{ if .Required }}
+	if {{ .EmptyCheck "e" }} {
+		errs = append(errs, errors.New("{{ .Column }} is required"))
+	}
+{{- end }}{{ end }}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %w", domain.ErrInvalidInput, errors.Join(errs...))
+	}
+	return nil
+}
+{{- else }}
 import "errors"
 
 func Validate{{ .Entity }}(input any) error {
     // TODO: validation logic here
     return errors.New("validator not implemented")
-}
\ No newline at end of file
+}
+{{- end }}